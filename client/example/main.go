@@ -2,7 +2,10 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
 	"flag"
 	"fmt"
 	"io"
@@ -26,6 +29,9 @@ func main() {
 	serverAddr := flag.String("server", "localhost:50051", "gRPC server address")
 	filePath := flag.String("file", "", "Path to file to upload")
 	insecureFlag := flag.Bool("insecure", false, "Use insecure connection")
+	caFile := flag.String("ca", "", "CA certificate to verify the server (default: skip verification)")
+	certFile := flag.String("cert", "", "Client certificate for mTLS")
+	keyFile := flag.String("key", "", "Client key for mTLS")
 	flag.Parse()
 
 	if *filePath == "" {
@@ -36,7 +42,10 @@ func main() {
 	if *insecureFlag {
 		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	} else {
-		config := &tls.Config{InsecureSkipVerify: true}
+		config, err := clientTLSConfig(*caFile, *certFile, *keyFile)
+		if err != nil {
+			log.Fatalf("Failed to load TLS files: %v", err)
+		}
 		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(config)))
 	}
 
@@ -109,19 +118,26 @@ func uploadFile(client pb.FileServiceClient, filePath string) (string, error) {
 		return "", fmt.Errorf("failed to create upload stream: %v", err)
 	}
 
-	filename := filepath.Base(filePath)
-	if err := stream.Send(&pb.UploadRequest{
-		Data: &pb.UploadRequest_Filename{Filename: filename},
-	}); err != nil {
-		return "", fmt.Errorf("failed to send filename: %v", err)
-	}
-
 	file, err := os.Open(filePath)
 	if err != nil {
 		return "", fmt.Errorf("failed to open file: %v", err)
 	}
 	defer file.Close()
 
+	// сервер сверит сумму с полученными данными и не сохранит файл при расхождении
+	sum, err := fileSHA256(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash file: %v", err)
+	}
+
+	filename := filepath.Base(filePath)
+	if err := stream.Send(&pb.UploadRequest{
+		Data:      &pb.UploadRequest_Filename{Filename: filename},
+		Checksums: &pb.Checksums{Sha256: sum},
+	}); err != nil {
+		return "", fmt.Errorf("failed to send filename: %v", err)
+	}
+
 	buffer := make([]byte, defaultChunkSize)
 	for {
 		n, err := file.Read(buffer)
@@ -143,9 +159,22 @@ func uploadFile(client pb.FileServiceClient, filePath string) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("failed to receive response: %v", err)
 	}
+	fmt.Printf("Uploaded %s, sha256 %s\n", response.FileId, response.GetChecksums().GetSha256())
 	return response.FileId, nil
 }
 
+// fileSHA256 считает SHA-256 файла и возвращает позицию чтения в начало.
+func fileSHA256(file *os.File) (string, error) {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
 func updateFile(client pb.FileServiceClient, pathToNewData string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
 	defer cancel()
@@ -223,21 +252,28 @@ func listFiles(client pb.FileServiceClient) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
 
+	fmt.Println("Files on server:")
 	req := &pb.ListRequest{}
-	resp, err := client.ListFiles(ctx, req)
-	if err != nil {
-		return fmt.Errorf("ListFiles error: %v", err)
-	}
+	for {
+		resp, err := client.ListFiles(ctx, req)
+		if err != nil {
+			return fmt.Errorf("ListFiles error: %v", err)
+		}
 
-	fmt.Println("Files on server:")
-	for _, file := range resp.Files {
-		createdAt := file.CreatedAt.AsTime().Format("2006-01-02 15:04:05") // YYYY-MM-DD HH:MM:SS
-		updatedAt := file.UpdatedAt.AsTime().Format("2006-01-02 15:04:05")
+		for _, file := range resp.Files {
+			createdAt := file.CreatedAt.AsTime().Format("2006-01-02 15:04:05") // YYYY-MM-DD HH:MM:SS
+			updatedAt := file.UpdatedAt.AsTime().Format("2006-01-02 15:04:05")
 
-		fmt.Printf("ID: %s, Name: %s, Size: %d bytes, Created: %s, UpdatedAt: %s\n",
-			file.FileId, file.Filename, file.Size, createdAt, updatedAt)
+			fmt.Printf("ID: %s, Name: %s, Type: %s, Size: %d bytes, Created: %s, UpdatedAt: %s\n",
+				file.FileId, file.Filename, file.ContentType, file.Size, createdAt, updatedAt)
+		}
+
+		// сервер отдаёт файлы постранично, следующая страница запрашивается по токену
+		if resp.NextPageToken == "" {
+			return nil
+		}
+		req.PageToken = resp.NextPageToken
 	}
-	return nil
 }
 
 func downloadZip(client pb.FileServiceClient, fileIDs []string, outputPath string) error {
@@ -269,4 +305,31 @@ func downloadZip(client pb.FileServiceClient, fileIDs []string, outputPath strin
 		}
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// clientTLSConfig проверяет сервер по CA из caFile (без него проверка отключена)
+// и, если заданы certFile и keyFile, предъявляет клиентский сертификат для mTLS.
+func clientTLSConfig(caFile, certFile, keyFile string) (*tls.Config, error) {
+	config := &tls.Config{InsecureSkipVerify: true}
+
+	if caFile != "" {
+		data, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("%s: no CA certificates found", caFile)
+		}
+		config = &tls.Config{RootCAs: pool}
+	}
+
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
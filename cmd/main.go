@@ -1,10 +1,16 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"expvar"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/1abobik1/upload_file_service/internal/auth"
 	"github.com/1abobik1/upload_file_service/internal/config"
 	"github.com/1abobik1/upload_file_service/internal/grpc/server"
 	"github.com/1abobik1/upload_file_service/internal/handler"
@@ -13,32 +19,229 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// таймауты HTTP-сервера публичных ссылок: медленный клиент не держит соединение бесконечно.
+// WriteTimeout не задаётся, чтобы не обрывать скачивание больших файлов.
+const (
+	shareReadHeaderTimeout = 10 * time.Second
+	shareReadTimeout       = 30 * time.Second
+	shareIdleTimeout       = 2 * time.Minute
+)
+
+// таймауты HTTP-сервера ссылок и форм загрузки бэкенда fs. Через форму передаётся весь файл,
+// поэтому чтение запроса ограничено не константой, а сроком действия формы (DIRECT_UPLOAD_EXPIRY).
+const (
+	linkReadHeaderTimeout = 10 * time.Second
+	linkIdleTimeout       = 2 * time.Minute
+)
+
 func main() {
 	// загрузка конфигурации
 	cfg := config.MustLoad()
 
-	minioStorage, err := storage.NewMinIOStorage(
-		cfg.MinIO.Endpoint,
-		cfg.MinIO.MinIoRootUser,
-		cfg.MinIO.MinIoRootPassword,
-		cfg.MinIO.Bucket,
-		cfg.MinIO.UseSSL,
+	var (
+		objectStorage service.MinIOStorageI
+		// HTTP-сервер для presigned-ссылок и форм загрузки бэкенда fs; для MinIO они ведут прямо в MinIO
+		linkServer *http.Server
 	)
-	if err != nil {
-		logrus.Fatal(err)
+
+	switch cfg.Storage.Backend {
+	case config.StorageBackendFS:
+		if cfg.Storage.FSSigningKey == "" {
+			logrus.Warn("FS_STORAGE_SIGNING_KEY is not set, download links will be invalidated on restart")
+		}
+		fsStorage, err := storage.NewFSStorage(cfg.Storage.FSRoot, cfg.Storage.FSPublicURL, []byte(cfg.Storage.FSSigningKey))
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		objectStorage = fsStorage
+		linkServer = &http.Server{
+			Addr:              cfg.Storage.FSHTTPAddr,
+			Handler:           fsStorage.Handler(),
+			ReadHeaderTimeout: linkReadHeaderTimeout,
+			ReadTimeout:       cfg.DirectUpload.Expiry,
+			IdleTimeout:       linkIdleTimeout,
+		}
+	default:
+		minioStorage, err := storage.NewMinIOStorage(
+			cfg.MinIO.Endpoint,
+			cfg.MinIO.MinIoRootUser,
+			cfg.MinIO.MinIoRootPassword,
+			cfg.MinIO.Bucket,
+			cfg.MinIO.UseSSL,
+		)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		objectStorage = minioStorage
+	}
+
+	opts := []service.Option{
+		service.WithUploadSessionTTL(cfg.UploadSession.TTL),
+		service.WithUploadPartSize(cfg.UploadSession.PartSize),
+		service.WithDirectUpload(cfg.DirectUpload.Expiry, cfg.DirectUpload.MaxSize),
+		service.WithTrashRetention(cfg.Trash.Retention),
+		service.WithVersionRetention(cfg.Version.MaxCount, cfg.Version.MaxAge),
+		service.WithCRC32C(cfg.Checksum.CRC32C),
+		service.WithDownloadLinkExpiry(cfg.DownloadLink.Expiry, cfg.DownloadLink.MaxExpiry),
+		service.WithSharePasswordLimit(cfg.Share.PasswordMaxAttempts, cfg.Share.PasswordLockout),
+		service.WithDedup(cfg.Dedup.Enabled),
+	}
+	if cfg.Share.HTTPAddr != "" {
+		opts = append(opts, service.WithShareLinks(cfg.Share.PublicURL))
+	}
+
+	var adaptiveLimiter *server.AdaptiveLimiter
+	if cfg.GRPC.AdaptiveLimitEnabled {
+		adaptiveLimiter = server.NewAdaptiveLimiter(server.AdaptiveLimit{
+			Groups:        cfg.GRPC.AdaptiveLimitGroups,
+			Min:           cfg.GRPC.AdaptiveLimitMin,
+			Max:           cfg.GRPC.AdaptiveLimitMax,
+			LatencyTarget: cfg.GRPC.AdaptiveLimitLatencyTarget,
+			MaxErrorRate:  cfg.GRPC.AdaptiveLimitMaxErrorRate,
+			Window:        cfg.GRPC.AdaptiveLimitWindow,
+		})
+		// лимит подстраивается по задержке и ошибкам обращений сервиса к хранилищу
+		opts = append(opts, service.WithStorageObserver(adaptiveLimiter.Observe))
+	}
+
+	if cfg.Index.Path != "" {
+		index, err := storage.NewBoltIndex(cfg.Index.Path)
+		if err != nil {
+			logrus.Fatalf("Failed to open metadata index: %v", err)
+		}
+		defer index.Close()
+		opts = append(opts, service.WithIndex(index))
+	}
+
+	fileService := service.NewFileService(objectStorage, cfg.MinIO.Bucket, opts...)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// новый индекс заполняется из бакета до того, как сервер начнёт отвечать
+	if err := fileService.EnsureIndex(ctx); err != nil {
+		logrus.Fatalf("Failed to build metadata index: %v", err)
 	}
 
-	fileService := service.NewFileService(minioStorage, cfg.MinIO.Bucket)
+	// чистка брошенных сессий возобновляемой загрузки
+	go fileService.RunUploadSessionJanitor(ctx, cfg.UploadSession.CleanupInterval)
+	// чистка просроченных форм прямой загрузки и брошенных объектов
+	go fileService.RunDirectUploadJanitor(ctx, cfg.DirectUpload.CleanupInterval)
+	// окончательное удаление файлов из корзины по истечении срока хранения
+	go fileService.RunTrashJanitor(ctx, cfg.Trash.PurgeInterval)
+	go fileService.RunVersionJanitor(ctx, cfg.Version.PruneInterval)
+	// запись счётчиков статистики, которые меняются вместе с файлами
+	go fileService.RunStatsFlusher(ctx, cfg.Stats.FlushInterval)
 
 	fileHandler := handler.NewFileHandler(fileService)
 
-	srv := server.New(server.Config{
+	// публичные ссылки отдаются через сервис, чтобы их можно было отозвать и ограничить число скачиваний
+	var shareServer *http.Server
+	if cfg.Share.HTTPAddr != "" {
+		shareServer = &http.Server{
+			Addr:              cfg.Share.HTTPAddr,
+			Handler:           handler.NewShareHTTPHandler(fileService),
+			ReadHeaderTimeout: shareReadHeaderTimeout,
+			ReadTimeout:       shareReadTimeout,
+			IdleTimeout:       shareIdleTimeout,
+		}
+		if cfg.GRPC.TLSEnabled() {
+			// браузеры не предъявляют клиентских сертификатов, поэтому CA клиентов здесь не нужен
+			tlsConfig, err := server.NewTLSConfig(server.TLSConfig{
+				CertFile:       cfg.GRPC.TLSCertFile,
+				KeyFile:        cfg.GRPC.TLSKeyFile,
+				ReloadInterval: cfg.GRPC.TLSReloadInterval,
+			})
+			if err != nil {
+				logrus.Fatalf("Failed to load TLS certificates for share link server: %v", err)
+			}
+			shareServer.TLSConfig = tlsConfig
+		} else {
+			logrus.Warn("GRPC_TLS_CERT_FILE is not set, share link server accepts plaintext connections")
+		}
+	}
+
+	serverConfig := server.Config{
 		Port:                    cfg.GRPC.Port,
 		MaxConcurrentStreams:    cfg.GRPC.MaxConcurrentStreams,
 		ShutdownTimeout:         cfg.GRPC.ShutdownTimeout,
 		FileOpsConcurrencyLimit: cfg.GRPC.FileOpsConcurrencyLimit,
 		ListConcurrencyLimit:    cfg.GRPC.ListConcurrencyLimit,
-	}, fileHandler)
+		LimiterQueueTimeout:     cfg.GRPC.LimiterQueueTimeout,
+		LimiterMaxQueue:         cfg.GRPC.LimiterMaxQueue,
+		Adaptive:                adaptiveLimiter,
+		ClientLimits: server.ClientLimits{
+			Rate:        cfg.GRPC.PerClientRateLimit,
+			Burst:       cfg.GRPC.PerClientRateBurst,
+			Concurrency: cfg.GRPC.PerClientConcurrencyLimit,
+		},
+	}
+	groupOverrides, err := cfg.GRPC.LimitGroupOverrides()
+	if err != nil {
+		logrus.Fatal(err)
+	}
+	limitGroups := make([]server.LimitGroup, 0, len(groupOverrides))
+	for _, g := range groupOverrides {
+		limitGroups = append(limitGroups, server.LimitGroup(g))
+	}
+	serverConfig.LimitGroups = server.MergeLimitGroups(
+		server.DefaultLimitGroups(cfg.GRPC.FileOpsConcurrencyLimit, cfg.GRPC.ListConcurrencyLimit),
+		limitGroups,
+	)
+
+	overrides, err := cfg.GRPC.ClientLimitOverrides()
+	if err != nil {
+		logrus.Fatal(err)
+	}
+	if len(overrides) > 0 {
+		serverConfig.ClientLimitOverrides = make(map[string]server.ClientLimits, len(overrides))
+		for client, limit := range overrides {
+			serverConfig.ClientLimitOverrides[client] = server.ClientLimits(limit)
+		}
+	}
+	if cfg.GRPC.TLSEnabled() {
+		tlsConfig, err := server.NewTLSConfig(server.TLSConfig{
+			CertFile:           cfg.GRPC.TLSCertFile,
+			KeyFile:            cfg.GRPC.TLSKeyFile,
+			ClientCAFile:       cfg.GRPC.TLSClientCAFile,
+			ClientCertOptional: cfg.GRPC.TLSClientCertOptional,
+			ReloadInterval:     cfg.GRPC.TLSReloadInterval,
+		})
+		if err != nil {
+			logrus.Fatalf("Failed to load TLS certificates: %v", err)
+		}
+		serverConfig.TLS = tlsConfig
+	} else {
+		logrus.Warn("GRPC_TLS_CERT_FILE is not set, gRPC server accepts plaintext connections")
+	}
+	if cfg.Auth.Enabled {
+		verifier, err := auth.NewVerifier(auth.Config{
+			HMACSecret:       cfg.Auth.HMACSecret,
+			RSAPublicKeyFile: cfg.Auth.RSAPublicKeyFile,
+			JWKSFile:         cfg.Auth.JWKSFile,
+			Issuer:           cfg.Auth.Issuer,
+			Audience:         cfg.Auth.Audience,
+			Leeway:           cfg.Auth.Leeway,
+		})
+		if err != nil {
+			logrus.Fatalf("Failed to load token verification keys: %v", err)
+		}
+		serverConfig.Auth = verifier
+		serverConfig.AuthExempt = cfg.Auth.ExemptMethods
+	} else {
+		logrus.Warn("AUTH_ENABLED is false, gRPC server accepts unauthenticated calls")
+	}
+
+	srv := server.New(serverConfig, fileHandler)
+
+	var metricsServer *http.Server
+	if cfg.Metrics.HTTPAddr != "" {
+		expvar.Publish("grpc_limiter", expvar.Func(func() any { return srv.LimiterStats() }))
+		metricsServer = &http.Server{
+			Addr:    cfg.Metrics.HTTPAddr,
+			Handler: expvar.Handler(),
+		}
+	}
 
 	logrus.Infof("cfg.GRPC.FileOpsConcurrencyLimit: %v,  cfg.GRPC.ListConcurrencyLimit: %v", cfg.GRPC.FileOpsConcurrencyLimit, cfg.GRPC.ListConcurrencyLimit)
 	go func() {
@@ -47,9 +250,61 @@ func main() {
 		}
 	}()
 
+	if shareServer != nil {
+		go func() {
+			logrus.Infof("serving share links on %s", shareServer.Addr)
+			var err error
+			if shareServer.TLSConfig != nil {
+				// сертификат берётся из TLSConfig
+				err = shareServer.ListenAndServeTLS("", "")
+			} else {
+				err = shareServer.ListenAndServe()
+			}
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logrus.Fatalf("Failed to start share link server: %v", err)
+			}
+		}()
+	}
+
+	if metricsServer != nil {
+		go func() {
+			logrus.Infof("serving metrics on %s", metricsServer.Addr)
+			if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logrus.Fatalf("Failed to start metrics server: %v", err)
+			}
+		}()
+	}
+
+	if linkServer != nil {
+		go func() {
+			logrus.Infof("serving download links on %s", linkServer.Addr)
+			if err := linkServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logrus.Fatalf("Failed to start download link server: %v", err)
+			}
+		}()
+	}
+
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 	<-sigChan
 
 	srv.GracefulStop()
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), cfg.GRPC.ShutdownTimeout)
+	defer shutdownCancel()
+	if shareServer != nil {
+		if err := shareServer.Shutdown(shutdownCtx); err != nil {
+			logrus.WithError(err).Warn("failed to stop share link server")
+		}
+	}
+	if metricsServer != nil {
+		if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+			logrus.WithError(err).Warn("failed to stop metrics server")
+		}
+	}
+	if linkServer != nil {
+		if err := linkServer.Shutdown(shutdownCtx); err != nil {
+			logrus.WithError(err).Warn("failed to stop download link server")
+		}
+	}
 }
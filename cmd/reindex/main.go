@@ -0,0 +1,56 @@
+// reindex восстанавливает локальный индекс метаданных по содержимому бакета.
+// Сервер должен быть остановлен: файл индекса блокируется одним процессом.
+package main
+
+import (
+	"context"
+
+	"github.com/1abobik1/upload_file_service/internal/config"
+	"github.com/1abobik1/upload_file_service/internal/service"
+	"github.com/1abobik1/upload_file_service/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+func main() {
+	cfg := config.MustLoad()
+
+	if cfg.Index.Path == "" {
+		logrus.Fatal("METADATA_INDEX_PATH is not set")
+	}
+
+	var objectStorage service.MinIOStorageI
+	switch cfg.Storage.Backend {
+	case config.StorageBackendFS:
+		fsStorage, err := storage.NewFSStorage(cfg.Storage.FSRoot, cfg.Storage.FSPublicURL, []byte(cfg.Storage.FSSigningKey))
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		objectStorage = fsStorage
+	default:
+		minioStorage, err := storage.NewMinIOStorage(
+			cfg.MinIO.Endpoint,
+			cfg.MinIO.MinIoRootUser,
+			cfg.MinIO.MinIoRootPassword,
+			cfg.MinIO.Bucket,
+			cfg.MinIO.UseSSL,
+		)
+		if err != nil {
+			logrus.Fatal(err)
+		}
+		objectStorage = minioStorage
+	}
+
+	index, err := storage.NewBoltIndex(cfg.Index.Path)
+	if err != nil {
+		logrus.Fatalf("Failed to open metadata index: %v", err)
+	}
+	defer index.Close()
+
+	fileService := service.NewFileService(objectStorage, cfg.MinIO.Bucket, service.WithIndex(index))
+
+	n, err := fileService.RebuildIndex(context.Background())
+	if err != nil {
+		logrus.Fatalf("Failed to rebuild metadata index: %v", err)
+	}
+	logrus.Infof("metadata index rebuilt with %d files", n)
+}
@@ -9,11 +9,43 @@ import (
 
 var (
 	ErrFileNotFound          = errors.New("file not found")
+	ErrFileAlreadyExists     = errors.New("file already exists")
 	ErrInvalidFileFormat     = errors.New("invalid file format")
 	ErrStorageFailure        = errors.New("storage failure")
+	ErrObjectNotFound        = errors.New("object not found in storage")      // возвращается реализациями хранилища
+	ErrObjectModified        = errors.New("object was modified concurrently") // условная запись в хранилище не выполнена
 	ErrPermissionDenied      = errors.New("permission denied")
 	ErrFilenameProvidedTwice = errors.New("filename must only be provided in the first chunk")
 	ErrFileIDProvidedTwice   = errors.New("file_id must only be provided in the first chunk")
+	ErrInvalidPageToken      = errors.New("invalid page token")
+	ErrInvalidChecksum       = errors.New("invalid checksum format")
+	ErrChecksumMismatch      = errors.New("checksum of received data does not match")
+	ErrVersionNotFound       = errors.New("file version not found")
+	ErrPreconditionFailed    = errors.New("file was modified since expected etag or version")
+	ErrInvalidArchiveFormat  = errors.New("unsupported archive format")
+	ErrInvalidRange          = errors.New("requested range is outside of file")
+	ErrInvalidLinkExpiry     = errors.New("invalid link expiry")
+	ErrInvalidContentType    = errors.New("invalid content type")
+	ErrFileTooLarge          = errors.New("file exceeds maximum size")
+
+	ErrDirectUploadNotFound   = errors.New("direct upload not found")
+	ErrDirectUploadIncomplete = errors.New("object of direct upload was not uploaded")
+	ErrDirectUploadMismatch   = errors.New("uploaded object does not match direct upload")
+
+	ErrShareLinkNotFound     = errors.New("share link not found")
+	ErrShareLinkExpired      = errors.New("share link expired")
+	ErrShareLinkExhausted    = errors.New("share link reached its download limit")
+	ErrSharePasswordRequired = errors.New("share link requires password")
+	ErrSharePasswordMismatch = errors.New("wrong share link password")
+	ErrSharePasswordTooLong  = errors.New("share link password is too long")
+	ErrSharePasswordLocked   = errors.New("too many wrong share link passwords")
+	ErrShareLinksDisabled    = errors.New("share links are disabled")
+
+	ErrUploadSessionNotFound      = errors.New("upload session not found")
+	ErrUploadSessionBusy          = errors.New("upload session is being written by another stream")
+	ErrUploadSessionSealed        = errors.New("upload session already received its last part")
+	ErrUploadOffsetMismatch       = errors.New("offset does not match committed size of upload session")
+	ErrSessionHeaderProvidedTwice = errors.New("session header must only be provided in the first chunk")
 )
 
 // MapErrorToStatus преобразует ошибки в безопасные gRPC-ответы
@@ -21,14 +53,66 @@ func MapErrorToStatus(err error) error {
 	switch {
 	case errors.Is(err, ErrFileNotFound):
 		return status.Error(codes.NotFound, "file not found")
+	case errors.Is(err, ErrPermissionDenied):
+		return status.Error(codes.PermissionDenied, "file is not owned by or shared with caller")
+	case errors.Is(err, ErrFileAlreadyExists):
+		return status.Error(codes.AlreadyExists, "file already exists")
+	case errors.Is(err, ErrVersionNotFound):
+		return status.Error(codes.NotFound, "file version not found or already removed by retention")
+	case errors.Is(err, ErrPreconditionFailed):
+		return status.Error(codes.FailedPrecondition, "file was modified since expected etag or version, fetch it again")
+	case errors.Is(err, ErrInvalidArchiveFormat):
+		return status.Error(codes.InvalidArgument, "unsupported archive format")
+	case errors.Is(err, ErrInvalidRange):
+		return status.Error(codes.OutOfRange, "offset is beyond the end of file")
+	case errors.Is(err, ErrInvalidLinkExpiry):
+		return status.Error(codes.InvalidArgument, "link expiry must not be negative")
+	case errors.Is(err, ErrInvalidContentType):
+		return status.Error(codes.InvalidArgument, "content_type must be a valid MIME type")
+	case errors.Is(err, ErrFileTooLarge):
+		return status.Error(codes.InvalidArgument, "file exceeds maximum size of direct upload")
+	case errors.Is(err, ErrDirectUploadNotFound):
+		return status.Error(codes.NotFound, "direct upload not found or expired")
+	case errors.Is(err, ErrDirectUploadIncomplete):
+		return status.Error(codes.FailedPrecondition, "file was not uploaded to storage yet")
+	case errors.Is(err, ErrDirectUploadMismatch):
+		return status.Error(codes.InvalidArgument, "uploaded file does not match declared size or content type, upload it again")
+	case errors.Is(err, ErrShareLinkNotFound):
+		return status.Error(codes.NotFound, "share link not found or revoked")
+	case errors.Is(err, ErrShareLinkExpired), errors.Is(err, ErrShareLinkExhausted):
+		return status.Error(codes.FailedPrecondition, "share link expired or reached its download limit")
+	case errors.Is(err, ErrSharePasswordRequired), errors.Is(err, ErrSharePasswordMismatch):
+		return status.Error(codes.PermissionDenied, "wrong share link password")
+	case errors.Is(err, ErrSharePasswordLocked):
+		return status.Error(codes.ResourceExhausted, "too many wrong share link passwords, try again later")
+	case errors.Is(err, ErrShareLinksDisabled):
+		return status.Error(codes.FailedPrecondition, "share links are disabled on this server")
+	case errors.Is(err, ErrSharePasswordTooLong):
+		return status.Error(codes.InvalidArgument, "share link password must not exceed 72 bytes")
 	case errors.Is(err, ErrInvalidFileFormat):
 		return status.Error(codes.InvalidArgument, "invalid file format")
+	case errors.Is(err, ErrInvalidChecksum):
+		return status.Error(codes.InvalidArgument, "checksum must be hex: 64 characters for sha256, 8 for crc32c")
+	case errors.Is(err, ErrChecksumMismatch):
+		return status.Error(codes.DataLoss, "checksum of received data does not match, file was not saved")
 	case errors.Is(err, ErrStorageFailure):
 		return status.Error(codes.Internal, "internal storage error")
 	case errors.Is(err, ErrFilenameProvidedTwice):
 		return status.Error(codes.InvalidArgument, "filename must only be provided in the first chunk")
 	case errors.Is(err, ErrFileIDProvidedTwice):
 		return status.Error(codes.InvalidArgument, "file_id must only be provided in the first chunk")
+	case errors.Is(err, ErrInvalidPageToken):
+		return status.Error(codes.InvalidArgument, "invalid page token or sort order differs from previous page")
+	case errors.Is(err, ErrUploadSessionNotFound):
+		return status.Error(codes.NotFound, "upload session not found or expired")
+	case errors.Is(err, ErrUploadSessionBusy):
+		return status.Error(codes.Aborted, "upload session is being written by another stream")
+	case errors.Is(err, ErrUploadSessionSealed):
+		return status.Error(codes.FailedPrecondition, "upload session already received its last part")
+	case errors.Is(err, ErrUploadOffsetMismatch):
+		return status.Error(codes.FailedPrecondition, "offset does not match committed size, check session status")
+	case errors.Is(err, ErrSessionHeaderProvidedTwice):
+		return status.Error(codes.InvalidArgument, "session header must only be provided in the first chunk")
 	default:
 		return status.Error(codes.Internal, "internal server error")
 	}
@@ -0,0 +1,78 @@
+// Package auth проверяет JWT вызывающего и передаёт его claims и клиентский
+// TLS-сертификат через context.
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+)
+
+// ErrInvalidToken возвращается для любого токена, который не прошёл проверку.
+// Подробности оборачиваются в ошибку и предназначены только для логов.
+var ErrInvalidToken = errors.New("invalid token")
+
+// Claims - полезная нагрузка проверенного токена.
+type Claims map[string]any
+
+// Subject возвращает claim "sub" - идентификатор вызывающего.
+func (c Claims) Subject() string {
+	sub, _ := c["sub"].(string)
+	return sub
+}
+
+type claimsKey struct{}
+
+// NewContext возвращает копию ctx с claims вызывающего.
+func NewContext(ctx context.Context, claims Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// FromContext возвращает claims вызывающего; ok = false, если запрос не аутентифицирован.
+func FromContext(ctx context.Context) (claims Claims, ok bool) {
+	claims, ok = ctx.Value(claimsKey{}).(Claims)
+	return claims, ok
+}
+
+type clientCertKey struct{}
+
+// NewClientCertContext возвращает копию ctx с проверенным сертификатом клиента (mTLS).
+func NewClientCertContext(ctx context.Context, cert *x509.Certificate) context.Context {
+	return context.WithValue(ctx, clientCertKey{}, cert)
+}
+
+// ClientCertFromContext возвращает сертификат клиента; ok = false, если клиент его не предъявил.
+func ClientCertFromContext(ctx context.Context) (cert *x509.Certificate, ok bool) {
+	cert, ok = ctx.Value(clientCertKey{}).(*x509.Certificate)
+	return cert, ok
+}
+
+// CertSubject - идентификатор владельца сертификата: первый URI из SAN (например, SPIFFE ID),
+// а если их нет - Common Name.
+func CertSubject(cert *x509.Certificate) string {
+	if len(cert.URIs) > 0 {
+		return cert.URIs[0].String()
+	}
+	return cert.Subject.CommonName
+}
+
+// Authenticated сообщает, предъявил ли вызывающий проверенный токен или сертификат клиента.
+func Authenticated(ctx context.Context) bool {
+	if _, ok := FromContext(ctx); ok {
+		return true
+	}
+	_, ok := ClientCertFromContext(ctx)
+	return ok
+}
+
+// Subject возвращает идентификатор вызывающего: sub из токена, а без токена - идентификатор
+// из сертификата клиента. Пустая строка - запрос не аутентифицирован (см. Authenticated).
+func Subject(ctx context.Context) string {
+	if claims, ok := FromContext(ctx); ok {
+		return claims.Subject()
+	}
+	if cert, ok := ClientCertFromContext(ctx); ok {
+		return CertSubject(cert)
+	}
+	return ""
+}
@@ -0,0 +1,293 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// RFC 7518: ключ HS256 не короче выхода SHA-256
+	minHMACKeySize = 32
+	minRSAKeyBits  = 2048
+)
+
+// Config - ключи и ограничения проверки токенов. Нужен хотя бы один источник ключей.
+type Config struct {
+	HMACSecret       string // ключ HS256
+	RSAPublicKeyFile string // PEM с открытым ключом RS256
+	JWKSFile         string // JWKS с ключами RS256 ("kty": "RSA") и HS256 ("kty": "oct")
+
+	Issuer   string        // пусто - iss не проверяется
+	Audience string        // пусто - aud не проверяется
+	Leeway   time.Duration // допустимое расхождение часов при проверке exp и nbf
+}
+
+// Verifier проверяет JWT, подписанные HS256 или RS256. Токен без exp не принимается.
+type Verifier struct {
+	keys     []verifierKey
+	issuer   string
+	audience string
+	leeway   time.Duration
+	now      func() time.Time
+}
+
+// verifierKey - ключ HS256 (hmac) или RS256 (rsa). Ключ без kid подходит для токена с любым kid.
+type verifierKey struct {
+	kid  string
+	hmac []byte
+	rsa  *rsa.PublicKey
+}
+
+func NewVerifier(cfg Config) (*Verifier, error) {
+	v := &Verifier{
+		issuer:   cfg.Issuer,
+		audience: cfg.Audience,
+		leeway:   cfg.Leeway,
+		now:      time.Now,
+	}
+
+	if cfg.HMACSecret != "" {
+		if len(cfg.HMACSecret) < minHMACKeySize {
+			return nil, fmt.Errorf("HS256 secret must be at least %d bytes", minHMACKeySize)
+		}
+		v.keys = append(v.keys, verifierKey{hmac: []byte(cfg.HMACSecret)})
+	}
+	if cfg.RSAPublicKeyFile != "" {
+		key, err := loadRSAPublicKey(cfg.RSAPublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		v.keys = append(v.keys, verifierKey{rsa: key})
+	}
+	if cfg.JWKSFile != "" {
+		keys, err := loadJWKS(cfg.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		v.keys = append(v.keys, keys...)
+	}
+
+	if len(v.keys) == 0 {
+		return nil, errors.New("no token verification keys configured")
+	}
+	return v, nil
+}
+
+// Verify проверяет подпись и сроки токена и возвращает его claims.
+func (v *Verifier) Verify(token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: malformed header: %v", ErrInvalidToken, err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidToken)
+	}
+	if err := v.verifySignature(header.Alg, header.Kid, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: malformed claims: %v", ErrInvalidToken, err)
+	}
+	if err := v.validate(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// verifySignature проверяет подпись ключами, подходящими по алгоритму и kid.
+// Алгоритм берётся из заголовка, но ключ HS256 никогда не используется для RS256 и наоборот.
+func (v *Verifier) verifySignature(alg, kid, signed string, signature []byte) error {
+	var verify func(verifierKey) bool
+	switch alg {
+	case "HS256":
+		verify = func(k verifierKey) bool {
+			if k.hmac == nil {
+				return false
+			}
+			mac := hmac.New(sha256.New, k.hmac)
+			mac.Write([]byte(signed))
+			return hmac.Equal(signature, mac.Sum(nil))
+		}
+	case "RS256":
+		digest := sha256.Sum256([]byte(signed))
+		verify = func(k verifierKey) bool {
+			return k.rsa != nil && rsa.VerifyPKCS1v15(k.rsa, crypto.SHA256, digest[:], signature) == nil
+		}
+	default:
+		return fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, alg)
+	}
+
+	for _, k := range v.keys {
+		if (k.kid == "" || kid == "" || k.kid == kid) && verify(k) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: signature verification failed", ErrInvalidToken)
+}
+
+func (v *Verifier) validate(claims Claims) error {
+	now := v.now()
+
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return fmt.Errorf("%w: exp claim is required", ErrInvalidToken)
+	}
+	// без sub вызывающего нельзя отличить от других: права на файлы проверяются по нему
+	if claims.Subject() == "" {
+		return fmt.Errorf("%w: sub claim is required", ErrInvalidToken)
+	}
+	if now.After(time.Unix(int64(exp), 0).Add(v.leeway)) {
+		return fmt.Errorf("%w: token expired", ErrInvalidToken)
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(v.leeway).Before(time.Unix(int64(nbf), 0)) {
+		return fmt.Errorf("%w: token is not valid yet", ErrInvalidToken)
+	}
+
+	if v.issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != v.issuer {
+			return fmt.Errorf("%w: unexpected issuer %q", ErrInvalidToken, iss)
+		}
+	}
+	if v.audience != "" && !hasAudience(claims["aud"], v.audience) {
+		return fmt.Errorf("%w: token is not intended for %q", ErrInvalidToken, v.audience)
+	}
+	return nil
+}
+
+// hasAudience - aud может быть строкой или массивом строк.
+func hasAudience(aud any, audience string) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == audience
+	case []any:
+		for _, a := range aud {
+			if a == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func loadRSAPublicKey(path string) (*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data found", path)
+	}
+
+	var key any
+	switch block.Type {
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%s: unsupported PEM block %q", path, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%s: not an RSA public key", path)
+	}
+	if rsaKey.N.BitLen() < minRSAKeyBits {
+		return nil, fmt.Errorf("%s: RSA key must be at least %d bits", path, minRSAKeyBits)
+	}
+	return rsaKey, nil
+}
+
+// loadJWKS читает ключи RSA и oct из JWKS-файла. Ключи других типов и ключи
+// шифрования ("use": "enc") пропускаются.
+func loadJWKS(path string) ([]verifierKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			K   string `json:"k"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var keys []verifierKey
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		switch jwk.Kty {
+		case "RSA":
+			n, errN := base64.RawURLEncoding.DecodeString(jwk.N)
+			e, errE := base64.RawURLEncoding.DecodeString(jwk.E)
+			if errN != nil || errE != nil || len(e) == 0 || len(e) > 4 {
+				return nil, fmt.Errorf("%s: malformed RSA key %q", path, jwk.Kid)
+			}
+			key := &rsa.PublicKey{
+				N: new(big.Int).SetBytes(n),
+				E: int(new(big.Int).SetBytes(e).Int64()),
+			}
+			if key.N.BitLen() < minRSAKeyBits {
+				return nil, fmt.Errorf("%s: RSA key %q must be at least %d bits", path, jwk.Kid, minRSAKeyBits)
+			}
+			keys = append(keys, verifierKey{kid: jwk.Kid, rsa: key})
+		case "oct":
+			k, err := base64.RawURLEncoding.DecodeString(jwk.K)
+			if err != nil || len(k) < minHMACKeySize {
+				return nil, fmt.Errorf("%s: HS256 key %q must be at least %d bytes", path, jwk.Kid, minHMACKeySize)
+			}
+			keys = append(keys, verifierKey{kid: jwk.Kid, hmac: k})
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%s: no usable keys", path)
+	}
+	return keys, nil
+}
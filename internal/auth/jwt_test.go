@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func sign(t *testing.T, header, claims map[string]any, signer func(signed string) []byte) string {
+	t.Helper()
+	h, err := json.Marshal(header)
+	require.NoError(t, err)
+	c, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	signed := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(c)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signer(signed))
+}
+
+func hs256(secret string) func(string) []byte {
+	return func(signed string) []byte {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(signed))
+		return mac.Sum(nil)
+	}
+}
+
+func rs256(t *testing.T, key *rsa.PrivateKey) func(string) []byte {
+	return func(signed string) []byte {
+		digest := sha256.Sum256([]byte(signed))
+		sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+		return sig
+	}
+}
+
+func TestVerifyHS256(t *testing.T) {
+	v, err := NewVerifier(Config{HMACSecret: testSecret, Issuer: "issuer", Audience: "uploads"})
+	require.NoError(t, err)
+
+	exp := time.Now().Add(time.Hour).Unix()
+	claims := map[string]any{"sub": "alice", "exp": exp, "iss": "issuer", "aud": []string{"other", "uploads"}}
+	token := sign(t, map[string]any{"alg": "HS256"}, claims, hs256(testSecret))
+
+	got, err := v.Verify(token)
+	require.NoError(t, err)
+	require.Equal(t, "alice", got.Subject())
+
+	for name, token := range map[string]string{
+		"wrong key":   sign(t, map[string]any{"alg": "HS256"}, claims, hs256("fedcba9876543210fedcba9876543210")),
+		"alg none":    sign(t, map[string]any{"alg": "none"}, claims, func(string) []byte { return nil }),
+		"no exp":      sign(t, map[string]any{"alg": "HS256"}, map[string]any{"sub": "alice", "iss": "issuer", "aud": "uploads"}, hs256(testSecret)),
+		"no sub":      sign(t, map[string]any{"alg": "HS256"}, map[string]any{"exp": exp, "iss": "issuer", "aud": "uploads"}, hs256(testSecret)),
+		"empty sub":   sign(t, map[string]any{"alg": "HS256"}, map[string]any{"sub": "", "exp": exp, "iss": "issuer", "aud": "uploads"}, hs256(testSecret)),
+		"expired":     sign(t, map[string]any{"alg": "HS256"}, map[string]any{"sub": "alice", "exp": time.Now().Add(-time.Hour).Unix(), "iss": "issuer", "aud": "uploads"}, hs256(testSecret)),
+		"wrong aud":   sign(t, map[string]any{"alg": "HS256"}, map[string]any{"sub": "alice", "exp": exp, "iss": "issuer", "aud": "other"}, hs256(testSecret)),
+		"wrong iss":   sign(t, map[string]any{"alg": "HS256"}, map[string]any{"sub": "alice", "exp": exp, "iss": "evil", "aud": "uploads"}, hs256(testSecret)),
+		"not a token": "abc",
+	} {
+		_, err := v.Verify(token)
+		require.ErrorIs(t, err, ErrInvalidToken, name)
+	}
+
+	_, err = NewVerifier(Config{HMACSecret: "short"})
+	require.Error(t, err)
+}
+
+func TestVerifyRS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	pemFile := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(pemFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+
+	jwks, err := json.Marshal(map[string]any{"keys": []map[string]any{
+		{"kty": "EC", "kid": "ec", "crv": "P-256"},
+		{
+			"kty": "RSA", "kid": "k2", "use": "sig",
+			"n": base64.RawURLEncoding.EncodeToString(other.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(other.E)).Bytes()),
+		},
+	}})
+	require.NoError(t, err)
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(jwksFile, jwks, 0o600))
+
+	v, err := NewVerifier(Config{RSAPublicKeyFile: pemFile, JWKSFile: jwksFile, HMACSecret: testSecret})
+	require.NoError(t, err)
+
+	claims := map[string]any{"sub": "bob", "exp": time.Now().Add(time.Hour).Unix()}
+	got, err := v.Verify(sign(t, map[string]any{"alg": "RS256"}, claims, rs256(t, key)))
+	require.NoError(t, err)
+	require.Equal(t, "bob", got.Subject())
+
+	_, err = v.Verify(sign(t, map[string]any{"alg": "RS256", "kid": "k2"}, claims, rs256(t, other)))
+	require.NoError(t, err)
+
+	// ключ из JWKS выбирается по kid
+	_, err = v.Verify(sign(t, map[string]any{"alg": "RS256", "kid": "k3"}, claims, rs256(t, other)))
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	// подпись HS256 открытым ключом RSA не принимается
+	_, err = v.Verify(sign(t, map[string]any{"alg": "HS256"}, claims, hs256(string(der))))
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
@@ -4,6 +4,8 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
@@ -16,19 +18,236 @@ type GRPCConfig struct {
 	FileOpsConcurrencyLimit int           `env:"GRPC_CLIENT_FILE_OPS_CONCURRENCY_LIMIT"  env-required:"true"`
 	ListConcurrencyLimit    int           `env:"GRPC_CLIENT_LIST_CONCURRENCY_LIMIT"  env-required:"true"`
 	ShutdownTimeout         time.Duration `env:"GRPC_SHUTDOWN_TIMEOUT"  env-required:"true"`
+
+	// изменения таблицы лимитов по методам: "group=limit;group2=limit:Method,Method"
+	LimiterGroups string `env:"GRPC_LIMITER_GROUPS"`
+	// очередь вызовов, ожидающих слот лимитера; 0 - без ограничения
+	LimiterQueueTimeout time.Duration `env:"GRPC_LIMITER_QUEUE_TIMEOUT" env-default:"30s"`
+	LimiterMaxQueue     int           `env:"GRPC_LIMITER_MAX_QUEUE" env-default:"100"`
+
+	// адаптивный лимит групп передачи файлов по задержке и ошибкам хранилища
+	AdaptiveLimitEnabled       bool          `env:"GRPC_ADAPTIVE_LIMIT_ENABLED" env-default:"false"`
+	AdaptiveLimitGroups        []string      `env:"GRPC_ADAPTIVE_LIMIT_GROUPS" env-default:"files"`
+	AdaptiveLimitMin           int           `env:"GRPC_ADAPTIVE_LIMIT_MIN" env-default:"1"`
+	AdaptiveLimitMax           int           `env:"GRPC_ADAPTIVE_LIMIT_MAX" env-default:"0"`
+	AdaptiveLimitLatencyTarget time.Duration `env:"GRPC_ADAPTIVE_LIMIT_LATENCY_TARGET" env-default:"200ms"`
+	AdaptiveLimitMaxErrorRate  float64       `env:"GRPC_ADAPTIVE_LIMIT_MAX_ERROR_RATE" env-default:"0.05"`
+	AdaptiveLimitWindow        time.Duration `env:"GRPC_ADAPTIVE_LIMIT_WINDOW" env-default:"1s"`
+
+	// ограничения каждого клиента (по идентификатору, без аутентификации - по IP); 0 - без ограничения
+	PerClientRateLimit        float64 `env:"GRPC_PER_CLIENT_RATE_LIMIT" env-default:"0"`
+	PerClientRateBurst        int     `env:"GRPC_PER_CLIENT_RATE_BURST" env-default:"0"`
+	PerClientConcurrencyLimit int     `env:"GRPC_PER_CLIENT_CONCURRENCY_LIMIT" env-default:"0"`
+	// ограничения отдельных клиентов: "client=rate,burst,concurrency;client2=..."
+	PerClientOverrides string `env:"GRPC_PER_CLIENT_LIMIT_OVERRIDES"`
+
+	// TLS включается, если заданы сертификат и ключ; с CA клиентов - mTLS
+	TLSCertFile           string        `env:"GRPC_TLS_CERT_FILE"`
+	TLSKeyFile            string        `env:"GRPC_TLS_KEY_FILE"`
+	TLSClientCAFile       string        `env:"GRPC_TLS_CLIENT_CA_FILE"`
+	TLSClientCertOptional bool          `env:"GRPC_TLS_CLIENT_CERT_OPTIONAL" env-default:"false"`
+	TLSReloadInterval     time.Duration `env:"GRPC_TLS_RELOAD_INTERVAL" env-default:"1m"`
+}
+
+func (c GRPCConfig) TLSEnabled() bool {
+	return c.TLSCertFile != "" || c.TLSKeyFile != ""
+}
+
+// LimitGroup - лимит одновременных вызовов группы методов; пустой Methods оставляет методы группы прежними.
+type LimitGroup struct {
+	Name    string
+	Limit   int
+	Methods []string
+}
+
+// LimitGroupOverrides разбирает GRPC_LIMITER_GROUPS.
+func (c GRPCConfig) LimitGroupOverrides() ([]LimitGroup, error) {
+	var groups []LimitGroup
+	for _, entry := range strings.Split(c.LimiterGroups, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, spec, ok := strings.Cut(entry, "=")
+		limit, methods, _ := strings.Cut(spec, ":")
+		group := LimitGroup{Name: strings.TrimSpace(name)}
+		var err error
+		group.Limit, err = strconv.Atoi(strings.TrimSpace(limit))
+		if !ok || group.Name == "" || err != nil || group.Limit < 0 {
+			return nil, fmt.Errorf("GRPC_LIMITER_GROUPS: %q must look like group=limit or group=limit:Method,Method", entry)
+		}
+		for _, m := range strings.Split(methods, ",") {
+			if m = strings.TrimSpace(m); m != "" {
+				group.Methods = append(group.Methods, m)
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// ClientLimit - ограничения одного клиента; нулевые значения снимают ограничение.
+type ClientLimit struct {
+	Rate        float64
+	Burst       int
+	Concurrency int
+}
+
+// ClientLimitOverrides разбирает GRPC_PER_CLIENT_LIMIT_OVERRIDES. Клиент отделяется от
+// ограничений последним "=", поэтому идентификатор может содержать "=" (но не ";").
+func (c GRPCConfig) ClientLimitOverrides() (map[string]ClientLimit, error) {
+	overrides := make(map[string]ClientLimit)
+	for _, entry := range strings.Split(c.PerClientOverrides, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		i := strings.LastIndex(entry, "=")
+		fields := strings.Split(entry[i+1:], ",")
+		if i <= 0 || len(fields) != 3 {
+			return nil, fmt.Errorf("GRPC_PER_CLIENT_LIMIT_OVERRIDES: %q must look like client=rate,burst,concurrency", entry)
+		}
+
+		var (
+			limit ClientLimit
+			err   error
+		)
+		limit.Rate, err = strconv.ParseFloat(strings.TrimSpace(fields[0]), 64)
+		if err == nil {
+			limit.Burst, err = strconv.Atoi(strings.TrimSpace(fields[1]))
+		}
+		if err == nil {
+			limit.Concurrency, err = strconv.Atoi(strings.TrimSpace(fields[2]))
+		}
+		if err != nil || limit.Rate < 0 || limit.Burst < 0 || limit.Concurrency < 0 {
+			return nil, fmt.Errorf("GRPC_PER_CLIENT_LIMIT_OVERRIDES: invalid limits in %q", entry)
+		}
+		overrides[strings.TrimSpace(entry[:i])] = limit
+	}
+	return overrides, nil
+}
+
+// AuthConfig - проверка JWT в gRPC. Ключи HS256 и RS256 можно задать одновременно.
+type AuthConfig struct {
+	Enabled          bool          `env:"AUTH_ENABLED" env-default:"false"`
+	HMACSecret       string        `env:"AUTH_JWT_HMAC_SECRET"`
+	RSAPublicKeyFile string        `env:"AUTH_JWT_RSA_PUBLIC_KEY_FILE"`
+	JWKSFile         string        `env:"AUTH_JWT_JWKS_FILE"`
+	Issuer           string        `env:"AUTH_JWT_ISSUER"`
+	Audience         string        `env:"AUTH_JWT_AUDIENCE"`
+	Leeway           time.Duration `env:"AUTH_JWT_LEEWAY" env-default:"30s"`
+	// префиксы полных имён методов, доступных без токена
+	ExemptMethods []string `env:"AUTH_EXEMPT_METHODS" env-separator:"," env-default:"/grpc.reflection.,/grpc.health.v1.Health/"`
 }
 
+const (
+	StorageBackendMinIO = "minio"
+	StorageBackendFS    = "fs"
+)
+
+// MinIOConfig - параметры подключения к MinIO. Bucket используется любым бэкендом хранилища,
+// остальные поля обязательны только для STORAGE_BACKEND=minio.
 type MinIOConfig struct {
-	Endpoint          string `env:"MINIO_PORT" env-required:"true"`
-	MinIoRootUser     string `env:"MINIO_ROOT_USER"  env-required:"true"`
-	MinIoRootPassword string `env:"MINIO_ROOT_PASSWORD"  env-required:"true"`
+	Endpoint          string `env:"MINIO_PORT"`
+	MinIoRootUser     string `env:"MINIO_ROOT_USER"`
+	MinIoRootPassword string `env:"MINIO_ROOT_PASSWORD"`
 	Bucket            string `env:"MINIO_BUCKET" env-required:"true"`
-	UseSSL            bool   `env:"MINIO_USE_SSL" env-required:"true"`
+	UseSSL            bool   `env:"MINIO_USE_SSL" env-default:"false"`
+}
+
+type StorageConfig struct {
+	Backend string `env:"STORAGE_BACKEND" env-default:"minio"` // minio | fs
+
+	// параметры бэкенда fs: каталог с данными и HTTP-сервер для presigned-ссылок
+	FSRoot       string `env:"FS_STORAGE_ROOT" env-default:"data"`
+	FSHTTPAddr   string `env:"FS_STORAGE_HTTP_ADDR" env-default:"0.0.0.0:8080"`
+	FSPublicURL  string `env:"FS_STORAGE_PUBLIC_URL" env-default:"http://localhost:8080"`
+	FSSigningKey string `env:"FS_STORAGE_SIGNING_KEY"` // пусто - случайный ключ на время жизни процесса
+}
+
+type UploadSessionConfig struct {
+	TTL             time.Duration `env:"UPLOAD_SESSION_TTL" env-default:"24h"`
+	PartSize        int           `env:"UPLOAD_SESSION_PART_SIZE" env-default:"8388608"`
+	CleanupInterval time.Duration `env:"UPLOAD_SESSION_CLEANUP_INTERVAL" env-default:"10m"`
+}
+
+type DirectUploadConfig struct {
+	// срок действия формы загрузки напрямую в хранилище и максимальный размер файла
+	Expiry          time.Duration `env:"DIRECT_UPLOAD_EXPIRY" env-default:"1h"`
+	MaxSize         int64         `env:"DIRECT_UPLOAD_MAX_SIZE" env-default:"5368709120"`
+	CleanupInterval time.Duration `env:"DIRECT_UPLOAD_CLEANUP_INTERVAL" env-default:"10m"`
+}
+
+type TrashConfig struct {
+	Retention     time.Duration `env:"TRASH_RETENTION" env-default:"720h"`
+	PurgeInterval time.Duration `env:"TRASH_PURGE_INTERVAL" env-default:"1h"`
+}
+
+type DownloadLinkConfig struct {
+	Expiry    time.Duration `env:"DOWNLOAD_LINK_EXPIRY" env-default:"1h"`      // срок ссылки, если клиент его не указал
+	MaxExpiry time.Duration `env:"DOWNLOAD_LINK_MAX_EXPIRY" env-default:"24h"` // больший запрошенный срок обрезается
+}
+
+type ShareConfig struct {
+	// HTTP-сервер, который отдаёт файлы по публичным ссылкам, и адрес, по которому он доступен клиентам;
+	// пусто - публичные ссылки выключены. С GRPC_TLS_CERT_FILE сервер работает по HTTPS с тем же сертификатом
+	HTTPAddr  string `env:"SHARE_HTTP_ADDR" env-default:""`
+	PublicURL string `env:"SHARE_PUBLIC_URL" env-default:"http://localhost:8081"`
+	// после стольких неверных паролей подряд ссылка не принимает пароль в течение PasswordLockout
+	PasswordMaxAttempts int           `env:"SHARE_PASSWORD_MAX_ATTEMPTS" env-default:"5"`
+	PasswordLockout     time.Duration `env:"SHARE_PASSWORD_LOCKOUT" env-default:"15m"`
+}
+
+type ChecksumConfig struct {
+	// SHA-256 считается всегда, CRC32C - дополнительно по этому флагу
+	CRC32C bool `env:"CHECKSUM_CRC32C" env-default:"false"`
+}
+
+type VersionConfig struct {
+	// сколько прежних версий файла хранить и как долго; 0 - без ограничения
+	MaxCount      int           `env:"VERSION_MAX_COUNT" env-default:"10"`
+	MaxAge        time.Duration `env:"VERSION_MAX_AGE" env-default:"0"`
+	PruneInterval time.Duration `env:"VERSION_PRUNE_INTERVAL" env-default:"1h"`
+}
+
+type DedupConfig struct {
+	// хранение одинакового содержимого в одном блобе с подсчётом ссылок
+	Enabled bool `env:"DEDUP_ENABLED" env-default:"false"`
+}
+
+type StatsConfig struct {
+	// как часто накопленные изменения статистики хранилища записываются в бакет
+	FlushInterval time.Duration `env:"STATS_FLUSH_INTERVAL" env-default:"1m"`
+}
+
+type MetricsConfig struct {
+	// HTTP-сервер с метриками в формате expvar (/debug/vars); пусто - метрики не отдаются
+	HTTPAddr string `env:"METRICS_HTTP_ADDR" env-default:""`
+}
+
+type IndexConfig struct {
+	// путь к файлу bbolt с индексом метаданных; пусто - индекс выключен
+	Path string `env:"METADATA_INDEX_PATH" env-default:""`
 }
 
 type Config struct {
-	GRPC  GRPCConfig
-	MinIO MinIOConfig
+	GRPC          GRPCConfig
+	Auth          AuthConfig
+	MinIO         MinIOConfig
+	Storage       StorageConfig
+	UploadSession UploadSessionConfig
+	DirectUpload  DirectUploadConfig
+	Trash         TrashConfig
+	Version       VersionConfig
+	DownloadLink  DownloadLinkConfig
+	Share         ShareConfig
+	Checksum      ChecksumConfig
+	Dedup         DedupConfig
+	Stats         StatsConfig
+	Index         IndexConfig
+	Metrics       MetricsConfig
 }
 
 func MustLoad() *Config {
@@ -46,9 +265,63 @@ func MustLoad() *Config {
 		panic(fmt.Sprintf("failed to read env vars: %v", err))
 	}
 
+	if err := cfg.validate(); err != nil {
+		panic(fmt.Sprintf("invalid config: %v", err))
+	}
+
 	return &cfg
 }
 
+func (c *Config) validate() error {
+	switch c.Storage.Backend {
+	case StorageBackendMinIO:
+		if c.MinIO.Endpoint == "" || c.MinIO.MinIoRootUser == "" || c.MinIO.MinIoRootPassword == "" {
+			return fmt.Errorf("MINIO_PORT, MINIO_ROOT_USER and MINIO_ROOT_PASSWORD are required for storage backend %q", c.Storage.Backend)
+		}
+	case StorageBackendFS:
+	default:
+		return fmt.Errorf("unknown storage backend %q", c.Storage.Backend)
+	}
+
+	// presigned-ссылки S3 не могут действовать дольше недели
+	if c.DownloadLink.MaxExpiry > 7*24*time.Hour {
+		return fmt.Errorf("DOWNLOAD_LINK_MAX_EXPIRY must not exceed 168h")
+	}
+	if c.DownloadLink.Expiry > c.DownloadLink.MaxExpiry {
+		return fmt.Errorf("DOWNLOAD_LINK_EXPIRY must not exceed DOWNLOAD_LINK_MAX_EXPIRY")
+	}
+	if c.Auth.Enabled && c.Auth.HMACSecret == "" && c.Auth.RSAPublicKeyFile == "" && c.Auth.JWKSFile == "" {
+		return fmt.Errorf("AUTH_ENABLED requires AUTH_JWT_HMAC_SECRET, AUTH_JWT_RSA_PUBLIC_KEY_FILE or AUTH_JWT_JWKS_FILE")
+	}
+	if c.GRPC.TLSEnabled() && (c.GRPC.TLSCertFile == "" || c.GRPC.TLSKeyFile == "") {
+		return fmt.Errorf("GRPC_TLS_CERT_FILE and GRPC_TLS_KEY_FILE must be set together")
+	}
+	if c.GRPC.TLSClientCAFile != "" && !c.GRPC.TLSEnabled() {
+		return fmt.Errorf("GRPC_TLS_CLIENT_CA_FILE requires GRPC_TLS_CERT_FILE and GRPC_TLS_KEY_FILE")
+	}
+	if c.Share.PasswordMaxAttempts <= 0 || c.Share.PasswordLockout <= 0 {
+		return fmt.Errorf("SHARE_PASSWORD_MAX_ATTEMPTS and SHARE_PASSWORD_LOCKOUT must be positive")
+	}
+	if _, err := c.GRPC.LimitGroupOverrides(); err != nil {
+		return err
+	}
+	if _, err := c.GRPC.ClientLimitOverrides(); err != nil {
+		return err
+	}
+	if c.GRPC.AdaptiveLimitEnabled {
+		if c.GRPC.AdaptiveLimitMax != 0 && c.GRPC.AdaptiveLimitMax < c.GRPC.AdaptiveLimitMin {
+			return fmt.Errorf("GRPC_ADAPTIVE_LIMIT_MAX must not be less than GRPC_ADAPTIVE_LIMIT_MIN")
+		}
+		if c.GRPC.AdaptiveLimitMaxErrorRate < 0 || c.GRPC.AdaptiveLimitMaxErrorRate >= 1 {
+			return fmt.Errorf("GRPC_ADAPTIVE_LIMIT_MAX_ERROR_RATE must be in [0, 1)")
+		}
+	}
+	if c.DirectUpload.Expiry > 7*24*time.Hour {
+		return fmt.Errorf("DIRECT_UPLOAD_EXPIRY must not exceed 168h")
+	}
+	return nil
+}
+
 func getConfigPath() string {
 	if envPath := os.Getenv("CONFIG_PATH"); envPath != "" {
 		return envPath
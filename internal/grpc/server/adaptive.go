@@ -0,0 +1,156 @@
+package server
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// adaptiveBackoff - во сколько раз снижается лимит при перегрузке хранилища
+	adaptiveBackoff = 0.9
+	// defaultAdaptiveWindow - как часто пересчитывается лимит, если AdaptiveLimit.Window не задан
+	defaultAdaptiveWindow = time.Second
+)
+
+// AdaptiveLimit - параметры адаптивного лимита групп методов, работающих с хранилищем.
+type AdaptiveLimit struct {
+	// Groups - группы, чей лимит подстраивается; стартовый лимит берётся из таблицы групп
+	Groups []string
+	Min    int // 0 - не меньше одного
+	Max    int // 0 - без верхней границы
+	// LatencyTarget - средняя задержка хранилища, выше которой лимит снижается; 0 - задержка не учитывается
+	LatencyTarget time.Duration
+	// MaxErrorRate - доля ошибок хранилища, выше которой лимит снижается
+	MaxErrorRate float64
+	// Window - за какой период усредняются наблюдения перед пересчётом лимита
+	Window time.Duration
+}
+
+// AdaptiveStats - наблюдения за последний завершённый период.
+type AdaptiveStats struct {
+	LatencyMs float64 `json:"latency_ms"` // средняя задержка хранилища
+	ErrorRate float64 `json:"error_rate"`
+	Samples   int     `json:"samples"`
+	Overload  bool    `json:"overload"` // лимит в этом периоде снижался
+}
+
+// AdaptiveLimiter подстраивает лимиты групп по задержке и ошибкам хранилища по схеме AIMD:
+// если за период средняя задержка выше LatencyTarget или доля ошибок выше MaxErrorRate,
+// лимит уменьшается в adaptiveBackoff раз, иначе растёт на единицу, но только у групп,
+// занятых хотя бы наполовину, - простаивающий лимит не раздувается до Max.
+//
+// Наблюдения передаются через Observe, например из service.WithStorageObserver;
+// лимит пересчитывается при первом наблюдении после окончания периода.
+type AdaptiveLimiter struct {
+	cfg AdaptiveLimit
+	now func() time.Time
+
+	mu          sync.Mutex
+	groups      []*semaphore
+	windowStart time.Time
+	samples     int
+	errors      int
+	timed       int // наблюдения с задержкой
+	latencySum  time.Duration
+	last        AdaptiveStats
+}
+
+func NewAdaptiveLimiter(cfg AdaptiveLimit) *AdaptiveLimiter {
+	cfg.Min = max(cfg.Min, 1)
+	if cfg.Max <= 0 {
+		cfg.Max = math.MaxInt
+	}
+	cfg.Max = max(cfg.Max, cfg.Min)
+	if cfg.Window <= 0 {
+		cfg.Window = defaultAdaptiveWindow
+	}
+	return &AdaptiveLimiter{cfg: cfg, now: time.Now}
+}
+
+// bind передаёт лимитеру управление семафорами групп из cfg.Groups. Группы без лимита пропускаются.
+func (a *AdaptiveLimiter) bind(cl *concurrencyLimiter) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, name := range a.cfg.Groups {
+		sem, ok := cl.groups[name]
+		if !ok {
+			continue
+		}
+		sem.setLimit(a.clamp(sem.stats().Limit))
+		a.groups = append(a.groups, sem)
+	}
+	cl.adaptive = a
+}
+
+// Observe учитывает одно обращение к хранилищу. Обращение с нулевой latency учитывается
+// только в доле ошибок.
+func (a *AdaptiveLimiter) Observe(latency time.Duration, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.now()
+	if a.windowStart.IsZero() {
+		a.windowStart = now
+	}
+	if now.Sub(a.windowStart) >= a.cfg.Window {
+		a.adjust()
+		a.windowStart = now
+	}
+
+	a.samples++
+	if err != nil {
+		a.errors++
+	}
+	if latency > 0 {
+		a.timed++
+		a.latencySum += latency
+	}
+}
+
+// adjust пересчитывает лимиты по наблюдениям периода и начинает новый. Вызывается под mu.
+func (a *AdaptiveLimiter) adjust() {
+	if a.samples == 0 {
+		return
+	}
+
+	stats := AdaptiveStats{
+		ErrorRate: float64(a.errors) / float64(a.samples),
+		Samples:   a.samples,
+	}
+	var latency time.Duration
+	if a.timed > 0 {
+		latency = a.latencySum / time.Duration(a.timed)
+		stats.LatencyMs = float64(latency) / float64(time.Millisecond)
+	}
+	stats.Overload = stats.ErrorRate > a.cfg.MaxErrorRate ||
+		(a.cfg.LatencyTarget > 0 && latency > a.cfg.LatencyTarget)
+
+	for _, sem := range a.groups {
+		current := sem.stats()
+		limit := current.Limit
+		switch {
+		case stats.Overload:
+			limit = int(float64(limit) * adaptiveBackoff)
+		case current.InFlight*2 >= limit:
+			limit++
+		}
+		if limit = a.clamp(limit); limit != current.Limit {
+			sem.setLimit(limit)
+		}
+	}
+
+	a.last = stats
+	a.samples, a.errors, a.timed, a.latencySum = 0, 0, 0, 0
+}
+
+func (a *AdaptiveLimiter) clamp(limit int) int {
+	return min(max(limit, a.cfg.Min), a.cfg.Max)
+}
+
+func (a *AdaptiveLimiter) stats() AdaptiveStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.last
+}
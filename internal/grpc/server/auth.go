@@ -0,0 +1,44 @@
+package server
+
+import (
+	"context"
+	"strings"
+
+	"github.com/1abobik1/upload_file_service/internal/auth"
+	grpc_auth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TokenVerifier проверяет bearer-токен и возвращает его claims.
+type TokenVerifier interface {
+	Verify(token string) (auth.Claims, error)
+}
+
+// newAuthFunc проверяет токен из заголовка authorization и кладёт claims в context.
+// Методы, полное имя которых начинается с одного из exempt, вызываются без токена.
+func newAuthFunc(verifier TokenVerifier, exempt []string) grpc_auth.AuthFunc {
+	return func(ctx context.Context) (context.Context, error) {
+		method, _ := grpc.Method(ctx)
+		for _, prefix := range exempt {
+			if strings.HasPrefix(method, prefix) {
+				return ctx, nil
+			}
+		}
+
+		token, err := grpc_auth.AuthFromMD(ctx, "bearer")
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "bearer token is required")
+		}
+
+		claims, err := verifier.Verify(token)
+		if err != nil {
+			logrus.WithError(err).Debugf("rejected token for %s", method)
+			return nil, status.Error(codes.Unauthenticated, "invalid or expired token")
+		}
+
+		return auth.NewContext(ctx, claims), nil
+	}
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/1abobik1/upload_file_service/internal/auth"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type staticVerifier map[string]string
+
+func (v staticVerifier) Verify(token string) (auth.Claims, error) {
+	sub, ok := v[token]
+	if !ok {
+		return nil, errors.New("unknown token")
+	}
+	return auth.Claims{"sub": sub}, nil
+}
+
+type methodStream struct {
+	grpc.ServerTransportStream
+	method string
+}
+
+func (s methodStream) Method() string { return s.method }
+
+func TestAuthFunc(t *testing.T) {
+	authFunc := newAuthFunc(staticVerifier{"good": "alice"}, []string{"/grpc.health.v1.Health/"})
+
+	call := func(method, authorization string) (context.Context, error) {
+		ctx := grpc.NewContextWithServerTransportStream(context.Background(), methodStream{method: method})
+		if authorization != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", authorization))
+		}
+		return authFunc(ctx)
+	}
+
+	ctx, err := call("/upload_service.v1.FileService/ListFiles", "Bearer good")
+	require.NoError(t, err)
+	require.Equal(t, "alice", auth.Subject(ctx))
+
+	for _, authorization := range []string{"", "Bearer bad", "Basic good"} {
+		_, err = call("/upload_service.v1.FileService/ListFiles", authorization)
+		require.Equal(t, codes.Unauthenticated, status.Code(err), authorization)
+	}
+
+	ctx, err = call("/grpc.health.v1.Health/Check", "")
+	require.NoError(t, err)
+	require.Empty(t, auth.Subject(ctx))
+}
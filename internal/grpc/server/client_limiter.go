@@ -0,0 +1,164 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/1abobik1/upload_file_service/internal/auth"
+	"google.golang.org/grpc/peer"
+)
+
+// clientIdleTimeout - через сколько простоя состояние клиента забывается.
+const clientIdleTimeout = 10 * time.Minute
+
+// ClientLimits - ограничения одного клиента; нулевые значения снимают ограничение.
+type ClientLimits struct {
+	Rate        float64 // вызовов в секунду в среднем
+	Burst       int     // вызовов подряд сверх среднего; 0 - не меньше одного
+	Concurrency int     // одновременных вызовов, включая ожидающие слот
+}
+
+func (l ClientLimits) burst() float64 {
+	return float64(max(l.Burst, 1))
+}
+
+// clientLimitError - вызов отклонён лимитом клиента.
+type clientLimitError struct {
+	reason     string
+	limit      int
+	remaining  int
+	retryAfter time.Duration
+}
+
+func (e *clientLimitError) Error() string {
+	return fmt.Sprintf("client %s exceeded, retry after %s", e.reason, e.retryAfter)
+}
+
+type clientState struct {
+	limits   ClientLimits
+	tokens   float64
+	updated  time.Time
+	inFlight int
+}
+
+// refill пополняет корзину токенов к моменту now.
+func (c *clientState) refill(now time.Time) {
+	if c.limits.Rate > 0 {
+		c.tokens = math.Min(c.limits.burst(), c.tokens+now.Sub(c.updated).Seconds()*c.limits.Rate)
+	}
+	c.updated = now
+}
+
+// clientLimiter ограничивает частоту и число одновременных вызовов каждого клиента.
+type clientLimiter struct {
+	defaults  ClientLimits
+	overrides map[string]ClientLimits
+	now       func() time.Time
+
+	mu        sync.Mutex
+	clients   map[string]*clientState
+	lastSweep time.Time
+
+	rejected atomic.Uint64
+}
+
+func newClientLimiter(defaults ClientLimits, overrides map[string]ClientLimits) *clientLimiter {
+	return &clientLimiter{
+		defaults:  defaults,
+		overrides: overrides,
+		now:       time.Now,
+		clients:   make(map[string]*clientState),
+	}
+}
+
+func (l *clientLimiter) limitsFor(client string) ClientLimits {
+	if limits, ok := l.overrides[client]; ok {
+		return limits
+	}
+	return l.defaults
+}
+
+// acquire расходует токен клиента и занимает один из его слотов.
+func (l *clientLimiter) acquire(client string) (release func(), err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	c, ok := l.clients[client]
+	if !ok {
+		limits := l.limitsFor(client)
+		c = &clientState{limits: limits, tokens: limits.burst(), updated: now}
+		l.clients[client] = c
+	}
+	c.refill(now)
+
+	if c.limits.Concurrency > 0 && c.inFlight >= c.limits.Concurrency {
+		l.rejected.Add(1)
+		return nil, &clientLimitError{
+			reason:     "concurrency limit",
+			limit:      c.limits.Concurrency,
+			retryAfter: time.Second,
+		}
+	}
+	if c.limits.Rate > 0 {
+		if c.tokens < 1 {
+			l.rejected.Add(1)
+			return nil, &clientLimitError{
+				reason:     "rate limit",
+				limit:      int(c.limits.burst()),
+				retryAfter: time.Duration((1 - c.tokens) / c.limits.Rate * float64(time.Second)),
+			}
+		}
+		c.tokens--
+	}
+
+	c.inFlight++
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		c.inFlight--
+	}, nil
+}
+
+// sweep забывает клиентов без вызовов, чья корзина давно полна. Вызывается под mu.
+func (l *clientLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < clientIdleTimeout {
+		return
+	}
+	l.lastSweep = now
+
+	for client, c := range l.clients {
+		if c.inFlight == 0 && now.Sub(c.updated) >= clientIdleTimeout {
+			delete(l.clients, client)
+		}
+	}
+}
+
+func (l *clientLimiter) count() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.clients)
+}
+
+// clientKey - идентификатор вызывающего, а для неаутентифицированных вызовов - IP клиента.
+func clientKey(ctx context.Context) string {
+	if subject := auth.Subject(ctx); subject != "" {
+		return subject
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
@@ -2,14 +2,19 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
 	"net"
 	"time"
 
 	pb "github.com/1abobik1/proto-upload-service/gen/go/upload_service/v1"
-	grpc_logrus "github.com/grpc-ecosystem/go-grpc-middleware/logging/logrus"
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	grpc_auth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
+	grpc_logrus "github.com/grpc-ecosystem/go-grpc-middleware/logging/logrus"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 )
 
@@ -19,42 +24,95 @@ type Config struct {
 	FileOpsConcurrencyLimit int
 	ListConcurrencyLimit    int
 	ShutdownTimeout         time.Duration
+
+	// LimitGroups - таблица лимитов по методам; nil - DefaultLimitGroups(FileOpsConcurrencyLimit, ListConcurrencyLimit)
+	LimitGroups []LimitGroup
+
+	// Adaptive подстраивает лимиты своих групп по состоянию хранилища; nil - лимиты постоянные
+	Adaptive *AdaptiveLimiter
+
+	// LimiterQueueTimeout - сколько вызов ждёт свободный слот, прежде чем получить RESOURCE_EXHAUSTED; 0 - без ограничения
+	LimiterQueueTimeout time.Duration
+	// LimiterMaxQueue - сколько вызовов может ждать слот одновременно, остальные отклоняются сразу; 0 - без ограничения
+	LimiterMaxQueue int
+	// ClientLimits - ограничения каждого клиента (по идентификатору, а без аутентификации - по IP)
+	ClientLimits ClientLimits
+	// ClientLimitOverrides - ограничения отдельных клиентов вместо ClientLimits
+	ClientLimitOverrides map[string]ClientLimits
+
+	// Auth включает проверку bearer-токенов; nil - сервер принимает любого вызывающего
+	Auth TokenVerifier
+	// AuthExempt - префиксы полных имён методов, доступных без токена (reflection, health)
+	AuthExempt []string
+
+	// TLS включает шифрование и, если в нём заданы ClientCAs, проверку клиентов; nil - plaintext
+	TLS *tls.Config
 }
 
 type Server struct {
 	grpcServer *grpc.Server
 	config     Config
+	limiter    *concurrencyLimiter
 }
 
 func New(config Config, fileService pb.FileServiceServer) *Server {
-	limiter := newConcurrencyLimiter(config.FileOpsConcurrencyLimit, config.ListConcurrencyLimit)
+	groups := config.LimitGroups
+	if groups == nil {
+		groups = DefaultLimitGroups(config.FileOpsConcurrencyLimit, config.ListConcurrencyLimit)
+	}
+	limiter := newConcurrencyLimiter(groups, queueLimits{
+		maxWait:   config.LimiterQueueTimeout,
+		maxLength: config.LimiterMaxQueue,
+	}, newClientLimiter(config.ClientLimits, config.ClientLimitOverrides))
+	if config.Adaptive != nil {
+		config.Adaptive.bind(limiter)
+	}
 
 	s := &Server{
-		config: config,
+		config:  config,
+		limiter: limiter,
 	}
 
 	logger := logrus.New()
 	logger.SetLevel(logrus.DebugLevel)
 
-	unaryChain := grpc_middleware.ChainUnaryServer(
+	unaryInterceptors := []grpc.UnaryServerInterceptor{
 		grpc_logrus.UnaryServerInterceptor(logrus.NewEntry(logger)),
-		limiter.unaryInterceptor,
-	)
-	streamChain := grpc_middleware.ChainStreamServer(
+	}
+	streamInterceptors := []grpc.StreamServerInterceptor{
 		grpc_logrus.StreamServerInterceptor(logrus.NewEntry(logger)),
-		limiter.streamInterceptor,
-	)
-	
+	}
+	// сертификат клиента нужен проверке токена и сервису раньше всех
+	if config.TLS != nil {
+		unaryInterceptors = append(unaryInterceptors, clientCertUnaryInterceptor)
+		streamInterceptors = append(streamInterceptors, clientCertStreamInterceptor)
+	}
+	// проверка токена идёт до лимитера, чтобы неаутентифицированные вызовы не занимали слоты
+	if config.Auth != nil {
+		authFunc := newAuthFunc(config.Auth, config.AuthExempt)
+		unaryInterceptors = append(unaryInterceptors, grpc_auth.UnaryServerInterceptor(authFunc))
+		streamInterceptors = append(streamInterceptors, grpc_auth.StreamServerInterceptor(authFunc))
+	}
+	unaryInterceptors = append(unaryInterceptors, limiter.unaryInterceptor)
+	streamInterceptors = append(streamInterceptors, limiter.streamInterceptor)
+
+	unaryChain := grpc_middleware.ChainUnaryServer(unaryInterceptors...)
+	streamChain := grpc_middleware.ChainStreamServer(streamInterceptors...)
+
 	opts := []grpc.ServerOption{
 		grpc.MaxConcurrentStreams(uint32(config.MaxConcurrentStreams)),
 		grpc.UnaryInterceptor(unaryChain),
 		grpc.StreamInterceptor(streamChain),
 	}
+	if config.TLS != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(config.TLS)))
+	}
 
 	s.grpcServer = grpc.NewServer(opts...)
 
 	pb.RegisterFileServiceServer(s.grpcServer, fileService)
 	reflection.Register(s.grpcServer)
+	healthpb.RegisterHealthServer(s.grpcServer, health.NewServer())
 
 	return s
 }
@@ -65,10 +123,15 @@ func (s *Server) Start() error {
 		return err
 	}
 
-	logrus.Infof("Starting gRPC server on port %s", s.config.Port)
+	logrus.Infof("Starting gRPC server on port %s (TLS: %t)", s.config.Port, s.config.TLS != nil)
 	return s.grpcServer.Serve(listener)
 }
 
+// LimiterStats возвращает текущую загрузку и длину очередей лимитера.
+func (s *Server) LimiterStats() LimiterStats {
+	return s.limiter.stats()
+}
+
 func (s *Server) GracefulStop() {
 	logrus.Info("Shutting down server...")
 
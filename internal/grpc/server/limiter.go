@@ -2,47 +2,349 @@ package server
 
 import (
 	"context"
-	"strings"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 )
 
-// concurrencyLimiter хранит два семафора: для file-операций и для просмотра списка.
+var (
+	errQueueFull    = errors.New("limiter queue is full")
+	errQueueTimeout = errors.New("timed out waiting for limiter slot")
+)
+
+// queueLimits - ограничения ожидания свободного слота; нулевые значения снимают ограничение.
+type queueLimits struct {
+	maxWait   time.Duration // сколько вызов может ждать слот
+	maxLength int           // сколько вызовов может ждать одновременно
+}
+
+// retryAfter - через сколько клиенту стоит повторить отклонённый вызов.
+func (q queueLimits) retryAfter() time.Duration {
+	return max(q.maxWait, time.Second)
+}
+
+// concurrencyLimiter хранит семафоры групп методов и ограничения отдельных клиентов.
 type concurrencyLimiter struct {
-	fileOps chan struct{} // семафор для скачивания и загрузки файлов
-	listOps chan struct{} // семафор для просмотра списка файлов
+	methods *methodTable
+	groups  map[string]*semaphore // группы без лимита сюда не попадают
+	queue   queueLimits
+	clients *clientLimiter
+
+	adaptive *AdaptiveLimiter // nil - лимиты групп не меняются
 }
 
-// newConcurrencyLimiter создаёт новый лимитер с заданными лимитами.
-func newConcurrencyLimiter(fileOpsLimit, listOpsLimit int) *concurrencyLimiter {
-	return &concurrencyLimiter{
-		fileOps: make(chan struct{}, fileOpsLimit),
-		listOps: make(chan struct{}, listOpsLimit),
+// newConcurrencyLimiter создаёт новый лимитер с заданными группами методов.
+func newConcurrencyLimiter(groups []LimitGroup, queue queueLimits, clients *clientLimiter) *concurrencyLimiter {
+	if clients == nil {
+		clients = newClientLimiter(ClientLimits{}, nil)
+	}
+	cl := &concurrencyLimiter{
+		methods: newMethodTable(groups),
+		groups:  make(map[string]*semaphore, len(groups)),
+		queue:   queue,
+		clients: clients,
 	}
+	for _, g := range groups {
+		if g.Limit > 0 {
+			cl.groups[g.Name] = newSemaphore(g.Limit, queue)
+		}
+	}
+	return cl
 }
 
-// acquire пытается захватить слот в зависимости от метода.
-func (cl *concurrencyLimiter) acquire(method string) (release func()) {
-	if strings.HasSuffix(method, "/ListFiles") {
-		cl.listOps <- struct{}{}
-		return func() { <-cl.listOps }
+// acquire проверяет лимиты клиента и захватывает слот группы метода. Вызов ждёт
+// в очереди, пока не освободится слот, не отменят ctx или не будет превышено время ожидания;
+// при переполненной очереди он отклоняется сразу.
+func (cl *concurrencyLimiter) acquire(ctx context.Context, method string) (release func(), err error) {
+	sem, ok := cl.groups[cl.methods.group(method)]
+	if !ok {
+		return func() {}, nil
+	}
+
+	client := clientKey(ctx)
+	releaseClient, err := cl.clients.acquire(client)
+	if err != nil {
+		return nil, err
+	}
+
+	releaseSlot, err := sem.acquire(ctx, client)
+	if err != nil {
+		releaseClient()
+		return nil, err
 	}
-	cl.fileOps <- struct{}{}
-	return func() { <-cl.fileOps }
+
+	return func() {
+		releaseSlot()
+		releaseClient()
+	}, nil
+}
+
+// rejection переводит ошибку acquire в gRPC-статус. Отказ лимитера - RESOURCE_EXHAUSTED
+// с подсказкой, когда повторить вызов: в RetryInfo и в заголовке retry-after (секунды).
+// Отказ по лимиту клиента дополнительно несёт заголовки ratelimit-limit, ratelimit-remaining
+// и ratelimit-reset.
+func (cl *concurrencyLimiter) rejection(err error) (header metadata.MD, statusErr error) {
+	var (
+		clientErr  *clientLimitError
+		retryAfter time.Duration
+		message    string
+	)
+	switch {
+	case errors.As(err, &clientErr):
+		retryAfter = clientErr.retryAfter
+		message = "client " + clientErr.reason + " exceeded, retry later"
+	case errors.Is(err, errQueueFull), errors.Is(err, errQueueTimeout):
+		retryAfter = cl.queue.retryAfter()
+		message = "server is busy, retry later"
+	default:
+		return nil, status.FromContextError(err).Err()
+	}
+
+	st := status.New(codes.ResourceExhausted, message)
+	if detailed, derr := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)}); derr == nil {
+		st = detailed
+	}
+
+	seconds := strconv.FormatInt(int64((retryAfter+time.Second-1)/time.Second), 10)
+	header = metadata.Pairs("retry-after", seconds)
+	if clientErr != nil {
+		header.Append("ratelimit-limit", strconv.Itoa(clientErr.limit))
+		header.Append("ratelimit-remaining", strconv.Itoa(clientErr.remaining))
+		header.Append("ratelimit-reset", seconds)
+	}
+	return header, st.Err()
 }
 
 // unaryInterceptor для униарных вызовов.
 func (cl *concurrencyLimiter) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-
-	release := cl.acquire(info.FullMethod)
+	release, err := cl.acquire(ctx, info.FullMethod)
+	if err != nil {
+		header, statusErr := cl.rejection(err)
+		if header != nil {
+			grpc.SetHeader(ctx, header)
+		}
+		return nil, statusErr
+	}
 	defer release()
 	return handler(ctx, req)
 }
 
 // streamInterceptor для стримовых вызовов.
 func (cl *concurrencyLimiter) streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
-	
-	release := cl.acquire(info.FullMethod)
+	release, err := cl.acquire(ss.Context(), info.FullMethod)
+	if err != nil {
+		header, statusErr := cl.rejection(err)
+		if header != nil {
+			ss.SetHeader(header)
+		}
+		return statusErr
+	}
 	defer release()
 	return handler(srv, ss)
 }
+
+// LimiterStats - состояние лимитера для мониторинга.
+type LimiterStats struct {
+	Groups map[string]SemaphoreStats `json:"groups"`
+	// Adaptive - наблюдения адаптивного лимита; текущие лимиты его групп - в Groups
+	Adaptive *AdaptiveStats `json:"adaptive,omitempty"`
+
+	Clients        int    `json:"clients"`         // клиенты, чьё состояние сейчас хранится
+	ClientRejected uint64 `json:"client_rejected"` // вызовы, отклонённые лимитами клиентов
+}
+
+type SemaphoreStats struct {
+	Limit    int    `json:"limit"`
+	InFlight int    `json:"in_flight"`
+	Queued   int64  `json:"queued"`   // вызовы, ожидающие слот
+	Rejected uint64 `json:"rejected"` // вызовы, отклонённые из-за очереди, с момента запуска
+}
+
+func (cl *concurrencyLimiter) stats() LimiterStats {
+	stats := LimiterStats{
+		Groups:         make(map[string]SemaphoreStats, len(cl.groups)),
+		Clients:        cl.clients.count(),
+		ClientRejected: cl.clients.rejected.Load(),
+	}
+	for name, sem := range cl.groups {
+		stats.Groups[name] = sem.stats()
+	}
+	if cl.adaptive != nil {
+		adaptive := cl.adaptive.stats()
+		stats.Adaptive = &adaptive
+	}
+	return stats
+}
+
+// semaphore раздаёт слоты по очереди между клиентами: освободившийся слот получает
+// следующий по кругу клиент с ожидающими вызовами, а внутри клиента - самый давний вызов.
+// Так клиент с сотней вызовов в очереди не задерживает клиента с одним.
+type semaphore struct {
+	limit int
+	queue queueLimits
+
+	mu      sync.Mutex
+	inUse   int
+	waiting map[string][]*waiter // ожидающие вызовы по клиентам
+	order   []string             // клиенты с ожидающими вызовами в порядке обслуживания
+	queued  int
+
+	rejected atomic.Uint64
+}
+
+type waiter struct {
+	client  string
+	ready   chan struct{} // закрывается, когда слот передан вызову
+	granted bool
+}
+
+func newSemaphore(limit int, queue queueLimits) *semaphore {
+	return &semaphore{
+		limit:   limit,
+		queue:   queue,
+		waiting: make(map[string][]*waiter),
+	}
+}
+
+func (s *semaphore) acquire(ctx context.Context, client string) (release func(), err error) {
+	s.mu.Lock()
+	if s.inUse < s.limit && s.queued == 0 {
+		s.inUse++
+		s.mu.Unlock()
+		return s.release, nil
+	}
+	if s.queue.maxLength > 0 && s.queued >= s.queue.maxLength {
+		s.mu.Unlock()
+		s.rejected.Add(1)
+		return nil, errQueueFull
+	}
+	w := &waiter{client: client, ready: make(chan struct{})}
+	if len(s.waiting[client]) == 0 {
+		s.order = append(s.order, client)
+	}
+	s.waiting[client] = append(s.waiting[client], w)
+	s.queued++
+	s.mu.Unlock()
+
+	var timeout <-chan time.Time
+	if s.queue.maxWait > 0 {
+		timer := time.NewTimer(s.queue.maxWait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case <-w.ready:
+		return s.release, nil
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-timeout:
+		err = errQueueTimeout
+	}
+
+	if !s.cancel(w) {
+		// слот успели передать, пока мы выходили из ожидания, - возвращаем его
+		s.release()
+	}
+	if errors.Is(err, errQueueTimeout) {
+		s.rejected.Add(1)
+	}
+	return nil, err
+}
+
+// cancel убирает вызов из очереди; false - слот ему уже передан.
+func (s *semaphore) cancel(w *waiter) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if w.granted {
+		return false
+	}
+	queue := s.waiting[w.client]
+	for i, other := range queue {
+		if other == w {
+			queue = append(queue[:i], queue[i+1:]...)
+			break
+		}
+	}
+	s.setQueue(w.client, queue)
+	s.queued--
+	return true
+}
+
+// release освобождает слот и передаёт его следующему клиенту по кругу.
+func (s *semaphore) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.inUse--
+	s.grant()
+}
+
+// setLimit меняет лимит. При увеличении свободные слоты сразу получают ожидающие вызовы,
+// при уменьшении уже выполняющиеся вызовы не прерываются, а новые ждут, пока их станет меньше лимита.
+func (s *semaphore) setLimit(limit int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.limit = limit
+	s.grant()
+}
+
+// grant раздаёт свободные слоты ожидающим вызовам. Вызывается под mu.
+func (s *semaphore) grant() {
+	for s.inUse < s.limit && len(s.order) > 0 {
+		client := s.order[0]
+		s.order = s.order[1:]
+		queue := s.waiting[client]
+		w := queue[0]
+		if len(queue) > 1 {
+			// клиент встаёт в конец круга со следующим вызовом
+			s.waiting[client] = queue[1:]
+			s.order = append(s.order, client)
+		} else {
+			delete(s.waiting, client)
+		}
+		s.queued--
+		s.inUse++
+
+		w.granted = true
+		close(w.ready)
+	}
+}
+
+// setQueue сохраняет очередь клиента и убирает его из круга, если она опустела. Вызывается под mu.
+func (s *semaphore) setQueue(client string, queue []*waiter) {
+	if len(queue) > 0 {
+		s.waiting[client] = queue
+		return
+	}
+	delete(s.waiting, client)
+	for i, c := range s.order {
+		if c == client {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+}
+
+func (s *semaphore) stats() SemaphoreStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return SemaphoreStats{
+		Limit:    s.limit,
+		InFlight: s.inUse,
+		Queued:   int64(s.queued),
+		Rejected: s.rejected.Load(),
+	}
+}
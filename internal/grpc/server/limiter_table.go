@@ -0,0 +1,127 @@
+package server
+
+import (
+	"slices"
+	"strings"
+)
+
+// DefaultLimitGroup - группа методов, не попавших ни в одну другую; в таблице по умолчанию
+// в неё входят и ссылки с вызовами метаданных.
+const DefaultLimitGroup = "default"
+
+// LimitGroup - методы с общим лимитом одновременных вызовов.
+//
+// Метод задаётся коротким именем ("Upload"), полным именем ("/upload_service.v1.FileService/Upload")
+// или префиксом полного имени, который заканчивается на "/" или "." ("/grpc.health.v1.Health/").
+// "*" относит группу к методам по умолчанию. Если метод подходит под несколько групп, выбирается
+// группа с полным именем, затем с коротким, затем с самым длинным префиксом, затем "*".
+type LimitGroup struct {
+	Name    string
+	Limit   int // 0 - вызовы не ограничиваются и не учитываются в лимитах клиентов
+	Methods []string
+}
+
+// DefaultLimitGroups - таблица по умолчанию. Загрузки и скачивания делят один лимит fileOps,
+// а ссылки, вызовы метаданных и новые методы - один лимит listOps, так что сервер в целом
+// выполняет не больше fileOps передач файлов; ссылки на скачивание не конкурируют с загрузками.
+// Health-check и reflection не ограничиваются: их стримы живут дольше отдельных вызовов.
+func DefaultLimitGroups(fileOps, listOps int) []LimitGroup {
+	return []LimitGroup{
+		{Name: "files", Limit: fileOps, Methods: []string{
+			"Upload", "UpdateFile", "AppendUploadSession", "FinalizeUploadSession", "CompleteDirectUpload",
+			"DownloadFile", "DownloadZip",
+		}},
+		{Name: DefaultLimitGroup, Limit: listOps, Methods: []string{
+			"GetDownloadLink", "GetVersionDownloadLink", "CreateDirectUpload",
+			"ListFiles", "ListTrash", "ListVersions", "ListShareLinks", "GetStats",
+			"*",
+		}},
+		{Name: "system", Limit: 0, Methods: []string{"/grpc.health.v1.Health/", "/grpc.reflection."}},
+	}
+}
+
+// MergeLimitGroups применяет overrides к base. Группа с тем же именем получает новый лимит,
+// а если в ней указаны методы, то и новый список методов; новые группы добавляются. Методы
+// из overrides убираются из остальных групп base, поэтому метод можно перенести в другую группу,
+// указав только его.
+func MergeLimitGroups(base, overrides []LimitGroup) []LimitGroup {
+	merged := make([]LimitGroup, 0, len(base)+len(overrides))
+	for _, g := range base {
+		g.Methods = slices.Clone(g.Methods)
+		merged = append(merged, g)
+	}
+
+	for _, o := range overrides {
+		for i := range merged {
+			if merged[i].Name != o.Name {
+				merged[i].Methods = slices.DeleteFunc(merged[i].Methods, func(m string) bool {
+					return slices.Contains(o.Methods, m)
+				})
+			}
+		}
+
+		i := slices.IndexFunc(merged, func(g LimitGroup) bool { return g.Name == o.Name })
+		if i < 0 {
+			merged = append(merged, LimitGroup{Name: o.Name, Limit: o.Limit, Methods: slices.Clone(o.Methods)})
+			continue
+		}
+		merged[i].Limit = o.Limit
+		if len(o.Methods) > 0 {
+			merged[i].Methods = slices.Clone(o.Methods)
+		}
+	}
+	return merged
+}
+
+// methodTable находит группу метода по полному имени.
+type methodTable struct {
+	full     map[string]string
+	short    map[string]string
+	prefixes []methodPrefix // от длинного к короткому
+	fallback string         // группа "*"; пусто - прочие методы не ограничиваются
+}
+
+type methodPrefix struct {
+	prefix string
+	group  string
+}
+
+func newMethodTable(groups []LimitGroup) *methodTable {
+	t := &methodTable{
+		full:  make(map[string]string),
+		short: make(map[string]string),
+	}
+	for _, g := range groups {
+		for _, m := range g.Methods {
+			switch {
+			case m == "*":
+				t.fallback = g.Name
+			case strings.HasPrefix(m, "/") && (strings.HasSuffix(m, "/") || strings.HasSuffix(m, ".")):
+				t.prefixes = append(t.prefixes, methodPrefix{prefix: m, group: g.Name})
+			case strings.HasPrefix(m, "/"):
+				t.full[m] = g.Name
+			default:
+				t.short[m] = g.Name
+			}
+		}
+	}
+	slices.SortStableFunc(t.prefixes, func(a, b methodPrefix) int {
+		return len(b.prefix) - len(a.prefix)
+	})
+	return t
+}
+
+func (t *methodTable) group(fullMethod string) string {
+	if g, ok := t.full[fullMethod]; ok {
+		return g
+	}
+	if g, ok := t.short[fullMethod[strings.LastIndex(fullMethod, "/")+1:]]; ok {
+		return g
+	}
+	for _, p := range t.prefixes {
+		if strings.HasPrefix(fullMethod, p.prefix) {
+			return p.group
+		}
+	}
+	return t.fallback
+}
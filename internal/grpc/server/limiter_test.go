@@ -1,15 +1,21 @@
 package server
 
 import (
+	"context"
+	"errors"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
-)
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
 
 func TestConcurrencyLimiterFileOps(t *testing.T) {
-	limiter := newConcurrencyLimiter(2, 100)
+	limiter := newConcurrencyLimiter(DefaultLimitGroups(2, 100), queueLimits{}, nil)
 
 	var current int32
 	var max int32
@@ -17,7 +23,11 @@ func TestConcurrencyLimiterFileOps(t *testing.T) {
 
 	simulate := func(method string) {
 		defer wg.Done()
-		release := limiter.acquire(method)
+		release, err := limiter.acquire(context.Background(), method)
+		if err != nil {
+			t.Error(err)
+			return
+		}
 		atomic.AddInt32(&current, 1)
 		c := atomic.LoadInt32(&current)
 		if c > max {
@@ -45,7 +55,7 @@ func TestConcurrencyLimiterFileOps(t *testing.T) {
 }
 
 func TestConcurrencyLimiterListOps(t *testing.T) {
-	limiter := newConcurrencyLimiter(10, 3)
+	limiter := newConcurrencyLimiter(DefaultLimitGroups(10, 3), queueLimits{}, nil)
 
 	var current int32
 	var max int32
@@ -53,7 +63,11 @@ func TestConcurrencyLimiterListOps(t *testing.T) {
 
 	simulate := func(method string) {
 		defer wg.Done()
-		release := limiter.acquire(method)
+		release, err := limiter.acquire(context.Background(), method)
+		if err != nil {
+			t.Error(err)
+			return
+		}
 		atomic.AddInt32(&current, 1)
 		c := atomic.LoadInt32(&current)
 		if c > max {
@@ -78,3 +92,365 @@ func TestConcurrencyLimiterListOps(t *testing.T) {
 		t.Logf("Max concurrent listOps = %d", max)
 	}
 }
+
+// загрузки и скачивания делят один лимит: вместе их не больше fileOps
+func TestConcurrencyLimiterSharedFileOps(t *testing.T) {
+	const fileOps = 2
+	limiter := newConcurrencyLimiter(DefaultLimitGroups(fileOps, 100), queueLimits{}, nil)
+
+	var current, peak atomic.Int32
+	var wg sync.WaitGroup
+	for i := range 10 {
+		method := "/upload_service.v1.FileService/Upload"
+		if i%2 == 1 {
+			method = "/upload_service.v1.FileService/DownloadZip"
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := limiter.acquire(context.Background(), method)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			c := current.Add(1)
+			for p := peak.Load(); c > p && !peak.CompareAndSwap(p, c); p = peak.Load() {
+			}
+			time.Sleep(20 * time.Millisecond)
+			current.Add(-1)
+			release()
+		}()
+	}
+	wg.Wait()
+
+	if p := peak.Load(); p > fileOps {
+		t.Errorf("max concurrent Upload and DownloadZip calls = %d, expected <= %d", p, fileOps)
+	}
+}
+
+func TestConcurrencyLimiterQueue(t *testing.T) {
+	limiter := newConcurrencyLimiter(DefaultLimitGroups(1, 1), queueLimits{maxWait: 50 * time.Millisecond, maxLength: 1}, nil)
+	ctx := context.Background()
+
+	release, err := limiter.acquire(ctx, "/Upload")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// отменённый клиент не ждёт слот
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := limiter.acquire(cancelled, "/Upload"); !errors.Is(err, context.Canceled) {
+		t.Errorf("acquire with cancelled context: %v, expected context.Canceled", err)
+	}
+
+	// второй ожидающий при очереди длины 1 отклоняется сразу
+	waiting := make(chan error)
+	go func() {
+		_, err := limiter.acquire(ctx, "/Upload")
+		waiting <- err
+	}()
+	for limiter.stats().Groups["files"].Queued != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := limiter.acquire(ctx, "/Upload"); !errors.Is(err, errQueueFull) {
+		t.Errorf("acquire with full queue: %v, expected errQueueFull", err)
+	}
+
+	if err := <-waiting; !errors.Is(err, errQueueTimeout) {
+		t.Errorf("acquire after queue timeout: %v, expected errQueueTimeout", err)
+	}
+	if stats := limiter.stats().Groups["files"]; stats.Queued != 0 || stats.InFlight != 1 || stats.Rejected != 2 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	release()
+	release, err = limiter.acquire(ctx, "/Upload")
+	if err != nil {
+		t.Fatalf("acquire after release: %v", err)
+	}
+	release()
+}
+
+func TestConcurrencyLimiterRejection(t *testing.T) {
+	limiter := newConcurrencyLimiter(DefaultLimitGroups(1, 1), queueLimits{maxWait: 1500 * time.Millisecond}, nil)
+
+	header, err := limiter.rejection(errQueueTimeout)
+	st := status.Convert(err)
+	if st.Code() != codes.ResourceExhausted {
+		t.Fatalf("code = %v, expected ResourceExhausted", st.Code())
+	}
+	if got := header.Get("retry-after"); len(got) != 1 || got[0] != "2" {
+		t.Errorf("retry-after = %v, expected 2", got)
+	}
+	if len(st.Details()) != 1 {
+		t.Fatalf("expected RetryInfo in details, got %v", st.Details())
+	}
+	if info, ok := st.Details()[0].(*errdetails.RetryInfo); !ok || info.GetRetryDelay().AsDuration() != 1500*time.Millisecond {
+		t.Errorf("unexpected details %v", st.Details())
+	}
+
+	header, err = limiter.rejection(context.DeadlineExceeded)
+	if header != nil || status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("rejection(DeadlineExceeded) = %v, %v", header, err)
+	}
+}
+
+func TestClientLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+	limiter := newClientLimiter(ClientLimits{Rate: 2, Burst: 2}, map[string]ClientLimits{
+		"batch": {Concurrency: 1},
+	})
+	limiter.now = func() time.Time { return now }
+
+	// корзина на два вызова, дальше - два вызова в секунду
+	for range 2 {
+		if _, err := limiter.acquire("alice"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := limiter.acquire("alice")
+	var limitErr *clientLimitError
+	if !errors.As(err, &limitErr) || limitErr.limit != 2 || limitErr.retryAfter != 500*time.Millisecond {
+		t.Fatalf("acquire over rate limit: %v", err)
+	}
+	// другие клиенты не затронуты
+	if _, err := limiter.acquire("bob"); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(500 * time.Millisecond)
+	if _, err := limiter.acquire("alice"); err != nil {
+		t.Fatalf("acquire after refill: %v", err)
+	}
+
+	// для batch частота не ограничена, но одновременно разрешён один вызов
+	release, err := limiter.acquire("batch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := limiter.acquire("batch"); !errors.As(err, &limitErr) || limitErr.limit != 1 {
+		t.Fatalf("acquire over concurrency limit: %v", err)
+	}
+	release()
+	if _, err := limiter.acquire("batch"); err != nil {
+		t.Fatalf("acquire after release: %v", err)
+	}
+
+	header, err := newConcurrencyLimiter(DefaultLimitGroups(1, 1), queueLimits{}, limiter).rejection(limitErr)
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("code = %v, expected ResourceExhausted", status.Code(err))
+	}
+	for key, want := range map[string]string{"ratelimit-limit": "1", "ratelimit-remaining": "0", "ratelimit-reset": "1", "retry-after": "1"} {
+		if got := header.Get(key); len(got) != 1 || got[0] != want {
+			t.Errorf("%s = %v, expected %s", key, got, want)
+		}
+	}
+}
+
+func TestSemaphoreFairness(t *testing.T) {
+	sem := newSemaphore(1, queueLimits{})
+	ctx := context.Background()
+
+	release, err := sem.acquire(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// alice ставит в очередь три вызова раньше, чем bob - один
+	var order []string
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	enqueue := func(client string) {
+		wg.Add(1)
+		queued := sem.stats().Queued
+		go func() {
+			defer wg.Done()
+			release, err := sem.acquire(ctx, client)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			order = append(order, client)
+			mu.Unlock()
+			release()
+		}()
+		for sem.stats().Queued == queued {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	for _, client := range []string{"alice", "alice", "alice", "bob"} {
+		enqueue(client)
+	}
+
+	release()
+	wg.Wait()
+	if want := []string{"alice", "bob", "alice", "alice"}; !slices.Equal(order, want) {
+		t.Errorf("slots were granted in order %v, expected %v", order, want)
+	}
+}
+
+func TestLimitGroups(t *testing.T) {
+	groups := MergeLimitGroups(DefaultLimitGroups(2, 10), []LimitGroup{
+		{Name: "files", Limit: 4},
+		{Name: "zip", Limit: 1, Methods: []string{"DownloadZip"}},
+		{Name: "stats", Limit: 1, Methods: []string{"/upload_service.v1.FileService/GetStats"}},
+	})
+	limiter := newConcurrencyLimiter(groups, queueLimits{}, nil)
+
+	for method, want := range map[string]string{
+		"/upload_service.v1.FileService/Upload":          "files",
+		"/upload_service.v1.FileService/DownloadFile":    "files",
+		"/upload_service.v1.FileService/DownloadZip":     "zip",
+		"/upload_service.v1.FileService/GetDownloadLink": DefaultLimitGroup,
+		"/upload_service.v1.FileService/GetStats":        "stats",
+		"/upload_service.v1.FileService/ListFiles":       DefaultLimitGroup,
+		"/upload_service.v1.FileService/SomeNewMethod":   DefaultLimitGroup,
+		"/grpc.health.v1.Health/Watch":                   "system",
+	} {
+		if got := limiter.methods.group(method); got != want {
+			t.Errorf("group(%s) = %q, expected %q", method, got, want)
+		}
+	}
+
+	stats := limiter.stats().Groups
+	if stats["files"].Limit != 4 || stats[DefaultLimitGroup].Limit != 10 || stats["zip"].Limit != 1 {
+		t.Errorf("unexpected limits %+v", stats)
+	}
+	// группы без лимита не ограничивают вызовы: долгий health-стрим не занимает слот
+	if _, ok := stats["system"]; ok {
+		t.Error("system group must not be limited")
+	}
+	for range 3 {
+		if _, err := limiter.acquire(context.Background(), "/grpc.health.v1.Health/Watch"); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestAdaptiveLimiter(t *testing.T) {
+	// скачивания вынесены в свою группу, чтобы проверить рост только занятого лимита
+	groups := MergeLimitGroups(DefaultLimitGroups(4, 10), []LimitGroup{
+		{Name: "download", Limit: 4, Methods: []string{"DownloadFile", "DownloadZip"}},
+	})
+	limiter := newConcurrencyLimiter(groups, queueLimits{}, nil)
+	adaptive := NewAdaptiveLimiter(AdaptiveLimit{
+		Groups:        []string{"files", "download"},
+		Min:           2,
+		Max:           5,
+		LatencyTarget: 100 * time.Millisecond,
+		MaxErrorRate:  0.1,
+		Window:        time.Second,
+	})
+	now := time.Now()
+	adaptive.now = func() time.Time { return now }
+	adaptive.bind(limiter)
+
+	limits := func() (upload, download int) {
+		groups := limiter.stats().Groups
+		return groups["files"].Limit, groups["download"].Limit
+	}
+	// window наблюдает n вызовов хранилища и закрывает период
+	window := func(n int, latency time.Duration, err error) {
+		for range n {
+			adaptive.Observe(latency, err)
+		}
+		now = now.Add(time.Second)
+		adaptive.Observe(latency, nil)
+	}
+
+	// быстрое хранилище: растёт только занятый наполовину лимит загрузок
+	release := make([]func(), 0, 2)
+	for range 2 {
+		r, err := limiter.acquire(context.Background(), "/upload_service.v1.FileService/Upload")
+		if err != nil {
+			t.Fatal(err)
+		}
+		release = append(release, r)
+	}
+	window(10, 10*time.Millisecond, nil)
+	if upload, download := limits(); upload != 5 || download != 4 {
+		t.Errorf("after fast storage limits = %d, %d, expected 5, 4", upload, download)
+	}
+
+	// медленное хранилище снижает все лимиты
+	window(10, 500*time.Millisecond, nil)
+	if upload, download := limits(); upload != 4 || download != 3 {
+		t.Errorf("after slow storage limits = %d, %d, expected 4, 3", upload, download)
+	}
+	if stats := limiter.stats().Adaptive; stats == nil || !stats.Overload || stats.LatencyMs < 100 {
+		t.Errorf("unexpected adaptive stats %+v", stats)
+	}
+
+	// ошибки хранилища снижают лимиты даже при малой задержке, но не ниже Min
+	window(10, 10*time.Millisecond, errors.New("storage failure"))
+	window(10, 10*time.Millisecond, errors.New("storage failure"))
+	if upload, download := limits(); upload != 2 || download != 2 {
+		t.Errorf("after storage errors limits = %d, %d, expected 2, 2", upload, download)
+	}
+	for _, r := range release {
+		r()
+	}
+
+	// увеличенный лимит сразу отдаёт слоты ожидающим вызовам
+	sem := newSemaphore(1, queueLimits{})
+	if _, err := sem.acquire(context.Background(), "a"); err != nil {
+		t.Fatal(err)
+	}
+	acquired := make(chan struct{})
+	go func() {
+		if _, err := sem.acquire(context.Background(), "b"); err == nil {
+			close(acquired)
+		}
+	}()
+	for sem.stats().Queued != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	sem.setLimit(2)
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("waiting call did not get a slot after limit increase")
+	}
+}
+
+func TestAdaptiveLimiterUntimedResults(t *testing.T) {
+	limiter := newConcurrencyLimiter(DefaultLimitGroups(4, 10), queueLimits{}, nil)
+	adaptive := NewAdaptiveLimiter(AdaptiveLimit{
+		Groups:        []string{"files"},
+		Min:           1,
+		Max:           8,
+		LatencyTarget: 100 * time.Millisecond,
+		MaxErrorRate:  0.1,
+		Window:        time.Second,
+	})
+	now := time.Now()
+	adaptive.now = func() time.Time { return now }
+	adaptive.bind(limiter)
+
+	limit := func() int { return limiter.stats().Groups["files"].Limit }
+	failure := errors.New("storage failure")
+
+	// загрузки сообщают результат без длительности: редкая ошибка среди успешных
+	// не превышает допустимую долю, и лимит не снижается
+	for range 19 {
+		adaptive.Observe(0, nil)
+	}
+	adaptive.Observe(0, failure)
+	now = now.Add(time.Second)
+	adaptive.Observe(0, nil)
+	if got := limit(); got != 4 {
+		t.Errorf("after mostly successful uploads limit = %d, expected 4", got)
+	}
+	if stats := limiter.stats().Adaptive; stats == nil || stats.Overload || stats.ErrorRate > 0.1 {
+		t.Errorf("unexpected adaptive stats %+v", stats)
+	}
+
+	// без успешных результатов та же ошибка снижает лимит
+	adaptive.Observe(0, failure)
+	now = now.Add(time.Second)
+	adaptive.Observe(0, failure)
+	if got := limit(); got != 3 {
+		t.Errorf("after failed uploads limit = %d, expected 3", got)
+	}
+}
@@ -0,0 +1,187 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/1abobik1/upload_file_service/internal/auth"
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// TLSConfig - файлы сертификата сервера и CA для проверки клиентов.
+type TLSConfig struct {
+	CertFile string
+	KeyFile  string
+	// ClientCAFile включает mTLS: клиенты должны предъявить сертификат, подписанный этим CA
+	ClientCAFile string
+	// ClientCertOptional допускает клиентов без сертификата; предъявленный сертификат всё равно проверяется
+	ClientCertOptional bool
+	// ReloadInterval - как часто проверять, не изменились ли файлы на диске; 0 - не перечитывать
+	ReloadInterval time.Duration
+}
+
+// NewTLSConfig загружает сертификаты и возвращает конфигурацию TLS для Config.TLS.
+// Изменённые на диске файлы перечитываются при очередном подключении, но не чаще
+// ReloadInterval; уже установленные соединения продолжают работать со старым сертификатом.
+// Если новые файлы не читаются (например, сертификат уже заменён, а ключ ещё нет),
+// используется прежняя конфигурация.
+func NewTLSConfig(cfg TLSConfig) (*tls.Config, error) {
+	if cfg.CertFile == "" || cfg.KeyFile == "" {
+		return nil, errors.New("TLS certificate and key files are required")
+	}
+
+	r := &certReloader{cfg: cfg, now: time.Now}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return r.get(), nil
+		},
+	}, nil
+}
+
+type certReloader struct {
+	cfg TLSConfig
+	now func() time.Time
+
+	mu       sync.Mutex
+	current  *tls.Config
+	modTimes []time.Time // время изменения файлов, из которых загружен current
+	checked  time.Time
+}
+
+func (r *certReloader) files() []string {
+	files := []string{r.cfg.CertFile, r.cfg.KeyFile}
+	if r.cfg.ClientCAFile != "" {
+		files = append(files, r.cfg.ClientCAFile)
+	}
+	return files
+}
+
+func (r *certReloader) get() *tls.Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cfg.ReloadInterval > 0 && r.now().Sub(r.checked) >= r.cfg.ReloadInterval {
+		r.checked = r.now()
+		if r.changed() {
+			if err := r.load(); err != nil {
+				logrus.WithError(err).Warn("failed to reload TLS certificates, keeping previous ones")
+			} else {
+				logrus.Info("TLS certificates reloaded")
+			}
+		}
+	}
+	return r.current
+}
+
+func (r *certReloader) reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checked = r.now()
+	return r.load()
+}
+
+// changed сообщает, изменился ли какой-нибудь из файлов с последней загрузки.
+func (r *certReloader) changed() bool {
+	for i, file := range r.files() {
+		info, err := os.Stat(file)
+		if err != nil || !info.ModTime().Equal(r.modTimes[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// load читает файлы и заменяет current. Вызывается под mu.
+func (r *certReloader) load() error {
+	files := r.files()
+	modTimes := make([]time.Time, len(files))
+	for i, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		modTimes[i] = info.ModTime()
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	config := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	}
+
+	if r.cfg.ClientCAFile != "" {
+		data, err := os.ReadFile(r.cfg.ClientCAFile)
+		if err != nil {
+			return err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return fmt.Errorf("%s: no CA certificates found", r.cfg.ClientCAFile)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+		if r.cfg.ClientCertOptional {
+			config.ClientAuth = tls.VerifyClientCertIfGiven
+		}
+	}
+
+	r.current = config
+	r.modTimes = modTimes
+	return nil
+}
+
+// withClientCert кладёт в context проверенный сертификат клиента, если соединение установлено по mTLS.
+// Сертификат без URI в SAN и без Common Name отклоняется: по нему нельзя определить вызывающего.
+func withClientCert(ctx context.Context) (context.Context, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ctx, nil
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return ctx, nil
+	}
+	cert := info.State.VerifiedChains[0][0]
+	if auth.CertSubject(cert) == "" {
+		return nil, status.Error(codes.Unauthenticated, "client certificate has no URI SAN or common name")
+	}
+	return auth.NewClientCertContext(ctx, cert), nil
+}
+
+func clientCertUnaryInterceptor(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := withClientCert(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func clientCertStreamInterceptor(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := withClientCert(ss.Context())
+	if err != nil {
+		return err
+	}
+	wrapped := grpc_middleware.WrapServerStream(ss)
+	wrapped.WrappedContext = ctx
+	return handler(srv, wrapped)
+}
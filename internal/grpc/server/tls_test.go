@@ -0,0 +1,174 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	pb "github.com/1abobik1/proto-upload-service/gen/go/upload_service/v1"
+	"github.com/1abobik1/upload_file_service/internal/auth"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+// subjectEchoService возвращает идентификатор вызывающего в next_page_token.
+type subjectEchoService struct {
+	pb.UnimplementedFileServiceServer
+}
+
+func (subjectEchoService) ListFiles(ctx context.Context, _ *pb.ListRequest) (*pb.ListResponse, error) {
+	return &pb.ListResponse{NextPageToken: auth.Subject(ctx)}, nil
+}
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return testCA{cert: cert, key: key}
+}
+
+// issue выпускает сертификат с именем cn и пишет его и ключ в PEM-файлы в dir.
+func (ca testCA) issue(t *testing.T, dir, cn string, usage x509.ExtKeyUsage) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: cn},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile = filepath.Join(dir, cn+".crt")
+	keyFile = filepath.Join(dir, cn+".key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
+
+func (ca testCA) writePEM(t *testing.T, dir string) string {
+	path := filepath.Join(dir, "ca.crt")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw}), 0o600))
+	return path
+}
+
+func TestServerMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	caFile := ca.writePEM(t, dir)
+	certFile, keyFile := ca.issue(t, dir, "server", x509.ExtKeyUsageServerAuth)
+	clientCert, clientKey := ca.issue(t, dir, "alice", x509.ExtKeyUsageClientAuth)
+
+	tlsConfig, err := NewTLSConfig(TLSConfig{
+		CertFile:       certFile,
+		KeyFile:        keyFile,
+		ClientCAFile:   caFile,
+		ReloadInterval: time.Nanosecond,
+	})
+	require.NoError(t, err)
+
+	srv := New(Config{
+		MaxConcurrentStreams:    10,
+		FileOpsConcurrencyLimit: 10,
+		ListConcurrencyLimit:    10,
+		TLS:                     tlsConfig,
+	}, subjectEchoService{})
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go srv.grpcServer.Serve(lis)
+	defer srv.grpcServer.Stop()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	// call возвращает идентификатор, который увидел сервер, и CN его сертификата
+	call := func(certs ...tls.Certificate) (string, string, error) {
+		var serverCN string
+		config := &tls.Config{
+			RootCAs:      roots,
+			ServerName:   "localhost",
+			Certificates: certs,
+			VerifyConnection: func(cs tls.ConnectionState) error {
+				serverCN = cs.PeerCertificates[0].Subject.CommonName
+				return nil
+			},
+		}
+		conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(credentials.NewTLS(config)))
+		require.NoError(t, err)
+		defer conn.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		resp, err := pb.NewFileServiceClient(conn).ListFiles(ctx, &pb.ListRequest{})
+		return resp.GetNextPageToken(), serverCN, err
+	}
+
+	alice, err := tls.LoadX509KeyPair(clientCert, clientKey)
+	require.NoError(t, err)
+
+	subject, serverCN, err := call(alice)
+	require.NoError(t, err)
+	require.Equal(t, "alice", subject)
+	require.Equal(t, "server", serverCN)
+
+	// без клиентского сертификата рукопожатие не проходит
+	_, _, err = call()
+	require.Error(t, err)
+
+	// сертификат без URI и CN не определяет вызывающего и отклоняется
+	anonCert, anonKey := ca.issue(t, dir, "", x509.ExtKeyUsageClientAuth)
+	anon, err := tls.LoadX509KeyPair(anonCert, anonKey)
+	require.NoError(t, err)
+	_, _, err = call(anon)
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// новый сертификат подхватывается без перезапуска
+	renewedCert, renewedKey := ca.issue(t, dir, "server-renewed", x509.ExtKeyUsageServerAuth)
+	require.NoError(t, os.Rename(renewedCert, certFile))
+	require.NoError(t, os.Rename(renewedKey, keyFile))
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, later, later))
+
+	_, serverCN, err = call(alice)
+	require.NoError(t, err)
+	require.Equal(t, "server-renewed", serverCN)
+}
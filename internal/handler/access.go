@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"context"
+
+	pb "github.com/1abobik1/proto-upload-service/gen/go/upload_service/v1"
+	"github.com/1abobik1/upload_file_service/internal/apperrors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (h *FileHandler) GrantAccess(ctx context.Context, req *pb.GrantAccessRequest) (*pb.GrantAccessResponse, error) {
+	if req.GetFileId() == "" || req.GetSubject() == "" {
+		return nil, status.Error(codes.InvalidArgument, "file_id and subject are required")
+	}
+
+	file, err := h.service.GrantAccess(ctx, req.GetFileId(), req.GetSubject())
+	if err != nil {
+		return nil, apperrors.MapErrorToStatus(err)
+	}
+
+	return &pb.GrantAccessResponse{File: file}, nil
+}
+
+func (h *FileHandler) RevokeAccess(ctx context.Context, req *pb.RevokeAccessRequest) (*pb.RevokeAccessResponse, error) {
+	if req.GetFileId() == "" || req.GetSubject() == "" {
+		return nil, status.Error(codes.InvalidArgument, "file_id and subject are required")
+	}
+
+	file, err := h.service.RevokeAccess(ctx, req.GetFileId(), req.GetSubject())
+	if err != nil {
+		return nil, apperrors.MapErrorToStatus(err)
+	}
+
+	return &pb.RevokeAccessResponse{File: file}, nil
+}
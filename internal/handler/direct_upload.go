@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"context"
+	"math"
+
+	pb "github.com/1abobik1/proto-upload-service/gen/go/upload_service/v1"
+	"github.com/1abobik1/upload_file_service/internal/apperrors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (h *FileHandler) CreateDirectUpload(ctx context.Context, req *pb.CreateDirectUploadRequest) (*pb.CreateDirectUploadResponse, error) {
+	if req.GetFilename() == "" {
+		return nil, status.Error(codes.InvalidArgument, "filename is required")
+	}
+	if req.GetSize() > math.MaxInt64 {
+		return nil, apperrors.MapErrorToStatus(apperrors.ErrFileTooLarge)
+	}
+
+	upload, err := h.service.CreateDirectUpload(ctx, req.GetFilename(), req.GetContentType(), int64(req.GetSize()))
+	if err != nil {
+		return nil, apperrors.MapErrorToStatus(err)
+	}
+
+	return &pb.CreateDirectUploadResponse{
+		FileId:     upload.FileID,
+		Url:        upload.URL,
+		FormFields: upload.FormFields,
+		ExpiresAt:  timestamppb.New(upload.ExpiresAt),
+	}, nil
+}
+
+func (h *FileHandler) CompleteDirectUpload(ctx context.Context, req *pb.CompleteDirectUploadRequest) (*pb.UploadResponse, error) {
+	if req.GetFileId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "file_id is required")
+	}
+
+	rec, err := h.service.CompleteDirectUpload(ctx, req.GetFileId())
+	if err != nil {
+		return nil, apperrors.MapErrorToStatus(err)
+	}
+
+	return &pb.UploadResponse{
+		FileId: rec.FileID,
+		Size:   uint64(rec.Size),
+	}, nil
+}
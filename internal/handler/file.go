@@ -3,20 +3,52 @@ package handler
 import (
 	"context"
 	"io"
+	"math"
+	"time"
 
 	pb "github.com/1abobik1/proto-upload-service/gen/go/upload_service/v1"
 	"github.com/1abobik1/upload_file_service/internal/apperrors"
+	"github.com/1abobik1/upload_file_service/internal/models"
+	"github.com/1abobik1/upload_file_service/internal/service"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type FileService interface {
-	Upload(ctx context.Context, filename string, data []byte) (string, uint64, error)
-	DownloadLink(ctx context.Context, fileID string) (string, error)
-	ListFiles(ctx context.Context) ([]*pb.FileInfo, error)
-	DownloadZip(ctx context.Context, fileIDs []string) (io.ReadCloser, error)
-	Update(ctx context.Context, fileID string, data []byte) (string, uint64, error)
+	Upload(ctx context.Context, filename string, r io.Reader, expected models.Checksums) (models.FileRecord, error)
+	DownloadLink(ctx context.Context, fileID string, opts service.LinkOptions) (string, time.Time, error)
+	DownloadFile(ctx context.Context, fileID string, offset, length int64) (service.FileContent, error)
+	ListFiles(ctx context.Context, opts service.ListOptions) ([]*pb.FileInfo, string, error)
+	DownloadArchive(ctx context.Context, fileIDs []string, opts service.ArchiveOptions) (io.ReadCloser, error)
+	Update(ctx context.Context, fileID string, r io.Reader, expected models.Checksums, match service.Precondition) (models.FileRecord, error)
+
+	CreateUploadSession(ctx context.Context, filename string) (service.UploadSession, error)
+	AppendUploadSession(ctx context.Context, sessionID string, offset uint64, r io.Reader) (service.UploadSession, error)
+	UploadSessionStatus(ctx context.Context, sessionID string) (service.UploadSession, error)
+	FinalizeUploadSession(ctx context.Context, sessionID string) (models.FileRecord, error)
+
+	CreateDirectUpload(ctx context.Context, filename, contentType string, size int64) (service.DirectUpload, error)
+	CompleteDirectUpload(ctx context.Context, fileID string) (models.FileRecord, error)
+
+	DeleteFile(ctx context.Context, fileID string, permanent bool) (time.Time, error)
+	RestoreFile(ctx context.Context, fileID string) (*pb.FileInfo, error)
+	ListTrash(ctx context.Context) ([]*pb.TrashedFile, error)
+
+	ListVersions(ctx context.Context, fileID string) ([]*pb.FileVersion, error)
+	VersionDownloadLink(ctx context.Context, fileID string, version uint64, opts service.LinkOptions) (string, time.Time, error)
+	Rollback(ctx context.Context, fileID string, version uint64) (*pb.FileInfo, error)
+
+	Stats(ctx context.Context) (service.Stats, error)
+
+	CreateShareLink(ctx context.Context, fileID string, opts service.ShareOptions) (service.ShareLink, error)
+	ListShareLinks(ctx context.Context, fileID string) ([]service.ShareLink, error)
+	RevokeShareLink(ctx context.Context, token string) error
+	OpenShareLink(ctx context.Context, token, password string) (service.FileContent, error)
+
+	GrantAccess(ctx context.Context, fileID, subject string) (*pb.FileInfo, error)
+	RevokeAccess(ctx context.Context, fileID, subject string) (*pb.FileInfo, error)
 }
 
 type FileHandler struct {
@@ -40,19 +72,21 @@ func (h *FileHandler) Upload(stream pb.FileService_UploadServer) error {
 		return status.Error(codes.InvalidArgument, "filename is required in first chunk")
 	}
 
-	data, err := readUploadStream(stream)
-	if err != nil {
-		return apperrors.MapErrorToStatus(err)
-	}
+	reader := newUploadStreamReader(stream)
 
-	fileID, size, err := h.service.Upload(stream.Context(), filename, data)
+	rec, err := h.service.Upload(stream.Context(), filename, reader, fromChecksumsPB(firstChunk.GetChecksums()))
 	if err != nil {
+		// ошибка стрима важнее ошибки хранилища, которая является её следствием
+		if streamErr := reader.Err(); streamErr != nil {
+			return apperrors.MapErrorToStatus(streamErr)
+		}
 		return apperrors.MapErrorToStatus(err)
 	}
 
 	return stream.SendAndClose(&pb.UploadResponse{
-		FileId: fileID,
-		Size:   size,
+		FileId:    rec.FileID,
+		Size:      uint64(rec.Size),
+		Checksums: service.ToChecksumsPB(rec.Checksums),
 	})
 }
 
@@ -68,19 +102,26 @@ func (h *FileHandler) UpdateFile(stream pb.FileService_UpdateFileServer) error {
 		return status.Error(codes.InvalidArgument, "file_id is required in first chunk")
 	}
 
-	data, err := readUpdateStream(stream)
-	if err != nil {
-		return apperrors.MapErrorToStatus(err)
-	}
+	reader := newUpdateStreamReader(stream)
 
-	newFileID, newSize, err := h.service.Update(stream.Context(), fileID, data)
+	match := service.Precondition{
+		ETag:    firstChunk.GetIfMatchEtag(),
+		Version: firstChunk.GetIfMatchVersion(),
+	}
+	rec, err := h.service.Update(stream.Context(), fileID, reader, fromChecksumsPB(firstChunk.GetChecksums()), match)
 	if err != nil {
+		if streamErr := reader.Err(); streamErr != nil {
+			return apperrors.MapErrorToStatus(streamErr)
+		}
 		return apperrors.MapErrorToStatus(err)
 	}
 
 	return stream.SendAndClose(&pb.UpdateFileResponse{
-		FileId:  newFileID,
-		NewSize: newSize,
+		FileId:    rec.FileID,
+		NewSize:   uint64(rec.Size),
+		Checksums: service.ToChecksumsPB(rec.Checksums),
+		Etag:      rec.ETag,
+		Version:   rec.Version,
 	})
 }
 
@@ -90,21 +131,79 @@ func (h *FileHandler) GetDownloadLink(ctx context.Context, req *pb.DownloadLinkR
 		return nil, status.Error(codes.InvalidArgument, "file_id is required")
 	}
 
-	url, err := h.service.DownloadLink(ctx, req.GetFileId())
+	opts := service.LinkOptions{
+		Expiry:      time.Duration(req.GetExpirySeconds()) * time.Second,
+		ContentType: req.GetContentType(),
+	}
+	url, expiresAt, err := h.service.DownloadLink(ctx, req.GetFileId(), opts)
 	if err != nil {
 		return nil, apperrors.MapErrorToStatus(err)
 	}
 
-	return &pb.DownloadLinkResponse{Url: url}, nil
+	return &pb.DownloadLinkResponse{Url: url, ExpiresAt: timestamppb.New(expiresAt)}, nil
+}
+
+func (h *FileHandler) DownloadFile(req *pb.DownloadFileRequest, stream pb.FileService_DownloadFileServer) error {
+	const op = "location internal/handler/DownloadFile()"
+
+	if req.GetFileId() == "" {
+		return status.Error(codes.InvalidArgument, "file_id is required")
+	}
+	if req.GetOffset() > math.MaxInt64 || req.GetLength() > math.MaxInt64 {
+		return apperrors.MapErrorToStatus(apperrors.ErrInvalidRange)
+	}
+
+	content, err := h.service.DownloadFile(stream.Context(), req.GetFileId(), int64(req.GetOffset()), int64(req.GetLength()))
+	if err != nil {
+		return apperrors.MapErrorToStatus(err)
+	}
+	defer content.Close()
+
+	header := &pb.DownloadFileHeader{
+		File:   content.File,
+		Offset: uint64(content.Offset),
+		Length: uint64(content.Length),
+	}
+	if err := stream.Send(&pb.DownloadFileResponse{Data: &pb.DownloadFileResponse_Header{Header: header}}); err != nil {
+		logrus.WithError(err).Errorf("%s: failed to send header", op)
+		return err
+	}
+
+	return sendChunks(stream.Context(), op, content, func(chunk []byte) error {
+		return stream.Send(&pb.DownloadFileResponse{Data: &pb.DownloadFileResponse_Chunk{Chunk: chunk}})
+	})
 }
 
-func (h *FileHandler) ListFiles(ctx context.Context, _ *pb.ListRequest) (*pb.ListResponse, error) {
-	files, err := h.service.ListFiles(ctx)
+func (h *FileHandler) ListFiles(ctx context.Context, req *pb.ListRequest) (*pb.ListResponse, error) {
+	filter := req.GetFilter()
+
+	opts := service.ListOptions{
+		PageSize:       int(req.GetPageSize()),
+		PageToken:      req.GetPageToken(),
+		FilenamePrefix: filter.GetFilenamePrefix(),
+		ContentType:    filter.GetContentType(),
+		CreatedAfter:   asTime(filter.GetCreatedAfter()),
+		CreatedBefore:  asTime(filter.GetCreatedBefore()),
+		UpdatedAfter:   asTime(filter.GetUpdatedAfter()),
+		UpdatedBefore:  asTime(filter.GetUpdatedBefore()),
+		MinSize:        filter.GetMinSize(),
+		MaxSize:        filter.GetMaxSize(),
+		SortBy:         req.GetSortBy(),
+		Descending:     req.GetDescending(),
+	}
+	if opts.MaxSize != 0 && opts.MinSize > opts.MaxSize {
+		return nil, status.Error(codes.InvalidArgument, "min_size must not exceed max_size")
+	}
+
+	files, nextPageToken, err := h.service.ListFiles(ctx, opts)
 	if err != nil {
 		return nil, apperrors.MapErrorToStatus(err)
 	}
 
-	return &pb.ListResponse{Files: files}, nil
+	return &pb.ListResponse{
+		Files:         files,
+		NextPageToken: nextPageToken,
+	}, nil
 }
 
 func (h *FileHandler) DownloadZip(req *pb.DownloadZipRequest, stream pb.FileService_DownloadZipServer) error {
@@ -114,29 +213,16 @@ func (h *FileHandler) DownloadZip(req *pb.DownloadZipRequest, stream pb.FileServ
 		return status.Error(codes.InvalidArgument, "at least one file_id is required")
 	}
 
-	readCloser, err := h.service.DownloadZip(stream.Context(), req.GetFileIds())
+	readCloser, err := h.service.DownloadArchive(stream.Context(), req.GetFileIds(), service.ArchiveOptions{
+		Strict: req.GetStrict(),
+		Format: req.GetFormat(),
+	})
 	if err != nil {
 		return apperrors.MapErrorToStatus(err)
 	}
 	defer readCloser.Close()
 
-	// Отправляем чанки по 1MB
-	buf := make([]byte, 1<<20)
-	for {
-		n, err := readCloser.Read(buf)
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			logrus.WithError(err).Errorf("%s: read error", op)
-			return status.Error(codes.Internal, "read error: "+err.Error())
-		}
-
-		if err := stream.Send(&pb.DownloadZipResponse{Chunk: buf[:n]}); err != nil {
-			logrus.WithError(err).Errorf("%s: failed to send chunk", op)
-			return err
-		}
-	}
-
-	return nil
+	return sendChunks(stream.Context(), op, readCloser, func(chunk []byte) error {
+		return stream.Send(&pb.DownloadZipResponse{Chunk: chunk})
+	})
 }
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	pb "github.com/1abobik1/proto-upload-service/gen/go/upload_service/v1"
+	"github.com/1abobik1/upload_file_service/internal/apperrors"
+	"github.com/1abobik1/upload_file_service/internal/service"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (h *FileHandler) CreateShareLink(ctx context.Context, req *pb.CreateShareLinkRequest) (*pb.ShareLink, error) {
+	if req.GetFileId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "file_id is required")
+	}
+
+	opts := service.ShareOptions{
+		Password:     req.GetPassword(),
+		MaxDownloads: req.GetMaxDownloads(),
+		Expiry:       time.Duration(req.GetExpirySeconds()) * time.Second,
+	}
+	link, err := h.service.CreateShareLink(ctx, req.GetFileId(), opts)
+	if err != nil {
+		return nil, apperrors.MapErrorToStatus(err)
+	}
+
+	return toShareLinkPB(link), nil
+}
+
+func (h *FileHandler) ListShareLinks(ctx context.Context, req *pb.ListShareLinksRequest) (*pb.ListShareLinksResponse, error) {
+	links, err := h.service.ListShareLinks(ctx, req.GetFileId())
+	if err != nil {
+		return nil, apperrors.MapErrorToStatus(err)
+	}
+
+	resp := &pb.ListShareLinksResponse{Links: make([]*pb.ShareLink, 0, len(links))}
+	for _, link := range links {
+		resp.Links = append(resp.Links, toShareLinkPB(link))
+	}
+	return resp, nil
+}
+
+func (h *FileHandler) RevokeShareLink(ctx context.Context, req *pb.RevokeShareLinkRequest) (*pb.RevokeShareLinkResponse, error) {
+	if req.GetToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "token is required")
+	}
+
+	if err := h.service.RevokeShareLink(ctx, req.GetToken()); err != nil {
+		return nil, apperrors.MapErrorToStatus(err)
+	}
+
+	return &pb.RevokeShareLinkResponse{}, nil
+}
+
+// NewShareHTTPHandler отдаёт файлы по публичным ссылкам вида /s/<token>. Пароль ссылки
+// передаётся через HTTP Basic (имя пользователя не проверяется), поэтому браузер
+// сам предложит его ввести.
+func NewShareHTTPHandler(svc FileService) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+service.SharePath+"{token}", func(w http.ResponseWriter, r *http.Request) {
+		serveShareLink(w, r, svc)
+	})
+	return mux
+}
+
+func serveShareLink(w http.ResponseWriter, r *http.Request, svc FileService) {
+	const op = "location internal/handler/serveShareLink()"
+
+	_, password, _ := r.BasicAuth()
+	content, err := svc.OpenShareLink(r.Context(), r.PathValue("token"), password)
+	if err != nil {
+		writeShareError(w, err)
+		return
+	}
+	defer content.Close()
+
+	file := content.File
+	w.Header().Set("Content-Type", file.GetContentType())
+	w.Header().Set("Content-Length", strconv.FormatInt(content.Length, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": file.GetFilename()}))
+	w.Header().Set("Cache-Control", "no-store")
+	if file.GetEtag() != "" {
+		w.Header().Set("ETag", `"`+file.GetEtag()+`"`)
+	}
+
+	if _, err := io.Copy(w, content); err != nil {
+		// заголовки уже отправлены, клиент увидит оборванный ответ
+		logrus.WithError(err).Warnf("%s: failed to send file %s", op, file.GetFileId())
+	}
+}
+
+func writeShareError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, apperrors.ErrShareLinkNotFound), errors.Is(err, apperrors.ErrFileNotFound):
+		http.Error(w, "link not found", http.StatusNotFound)
+	case errors.Is(err, apperrors.ErrShareLinkExpired), errors.Is(err, apperrors.ErrShareLinkExhausted):
+		http.Error(w, "link expired", http.StatusGone)
+	case errors.Is(err, apperrors.ErrSharePasswordRequired), errors.Is(err, apperrors.ErrSharePasswordMismatch):
+		w.Header().Set("WWW-Authenticate", `Basic realm="share", charset="UTF-8"`)
+		http.Error(w, "password required", http.StatusUnauthorized)
+	case errors.Is(err, apperrors.ErrSharePasswordLocked):
+		http.Error(w, "too many wrong passwords, try again later", http.StatusTooManyRequests)
+	default:
+		logrus.WithError(err).Error("location internal/handler/writeShareError(): failed to open share link")
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+	}
+}
+
+func toShareLinkPB(link service.ShareLink) *pb.ShareLink {
+	resp := &pb.ShareLink{
+		Token:        link.Token,
+		FileId:       link.FileID,
+		Url:          link.URL,
+		HasPassword:  link.HasPassword,
+		MaxDownloads: link.MaxDownloads,
+		Downloads:    link.Downloads,
+		CreatedAt:    timestamppb.New(link.CreatedAt),
+	}
+	if !link.ExpiresAt.IsZero() {
+		resp.ExpiresAt = timestamppb.New(link.ExpiresAt)
+	}
+	return resp
+}
@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"context"
+
+	pb "github.com/1abobik1/proto-upload-service/gen/go/upload_service/v1"
+	"github.com/1abobik1/upload_file_service/internal/apperrors"
+)
+
+func (h *FileHandler) GetStats(ctx context.Context, _ *pb.GetStatsRequest) (*pb.GetStatsResponse, error) {
+	stats, err := h.service.Stats(ctx)
+	if err != nil {
+		return nil, apperrors.MapErrorToStatus(err)
+	}
+
+	return &pb.GetStatsResponse{
+		Files:        stats.Files,
+		LogicalBytes: stats.LogicalBytes,
+		StoredBytes:  stats.StoredBytes,
+		Blobs:        stats.Blobs,
+		DedupRatio:   stats.DedupRatio(),
+	}, nil
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"context"
+
+	pb "github.com/1abobik1/proto-upload-service/gen/go/upload_service/v1"
+	"github.com/1abobik1/upload_file_service/internal/apperrors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (h *FileHandler) DeleteFile(ctx context.Context, req *pb.DeleteFileRequest) (*pb.DeleteFileResponse, error) {
+	if req.GetFileId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "file_id is required")
+	}
+
+	purgeAt, err := h.service.DeleteFile(ctx, req.GetFileId(), req.GetPermanent())
+	if err != nil {
+		return nil, apperrors.MapErrorToStatus(err)
+	}
+
+	resp := &pb.DeleteFileResponse{FileId: req.GetFileId()}
+	if !purgeAt.IsZero() {
+		resp.PurgeAt = timestamppb.New(purgeAt)
+	}
+	return resp, nil
+}
+
+func (h *FileHandler) RestoreFile(ctx context.Context, req *pb.RestoreFileRequest) (*pb.RestoreFileResponse, error) {
+	if req.GetFileId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "file_id is required")
+	}
+
+	file, err := h.service.RestoreFile(ctx, req.GetFileId())
+	if err != nil {
+		return nil, apperrors.MapErrorToStatus(err)
+	}
+
+	return &pb.RestoreFileResponse{File: file}, nil
+}
+
+func (h *FileHandler) ListTrash(ctx context.Context, _ *pb.ListTrashRequest) (*pb.ListTrashResponse, error) {
+	files, err := h.service.ListTrash(ctx)
+	if err != nil {
+		return nil, apperrors.MapErrorToStatus(err)
+	}
+
+	return &pb.ListTrashResponse{Files: files}, nil
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"context"
+
+	pb "github.com/1abobik1/proto-upload-service/gen/go/upload_service/v1"
+	"github.com/1abobik1/upload_file_service/internal/apperrors"
+	"github.com/1abobik1/upload_file_service/internal/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (h *FileHandler) CreateUploadSession(ctx context.Context, req *pb.CreateUploadSessionRequest) (*pb.CreateUploadSessionResponse, error) {
+	if req.GetFilename() == "" {
+		return nil, status.Error(codes.InvalidArgument, "filename is required")
+	}
+
+	sess, err := h.service.CreateUploadSession(ctx, req.GetFilename())
+	if err != nil {
+		return nil, apperrors.MapErrorToStatus(err)
+	}
+
+	return &pb.CreateUploadSessionResponse{
+		SessionId: sess.ID,
+		PartSize:  sess.PartSize,
+		ExpiresAt: timestamppb.New(sess.ExpiresAt),
+	}, nil
+}
+
+func (h *FileHandler) AppendUploadSession(stream pb.FileService_AppendUploadSessionServer) error {
+
+	firstChunk, err := stream.Recv()
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "failed to receive first chunk")
+	}
+
+	header := firstChunk.GetHeader()
+	if header.GetSessionId() == "" {
+		return status.Error(codes.InvalidArgument, "header with session_id is required in first chunk")
+	}
+
+	reader := newAppendStreamReader(stream)
+
+	sess, err := h.service.AppendUploadSession(stream.Context(), header.GetSessionId(), header.GetOffset(), reader)
+	if err != nil {
+		if streamErr := reader.Err(); streamErr != nil {
+			return apperrors.MapErrorToStatus(streamErr)
+		}
+		return apperrors.MapErrorToStatus(err)
+	}
+
+	return stream.SendAndClose(toSessionStatus(sess))
+}
+
+func (h *FileHandler) GetUploadSessionStatus(ctx context.Context, req *pb.UploadSessionStatusRequest) (*pb.UploadSessionStatusResponse, error) {
+	if req.GetSessionId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "session_id is required")
+	}
+
+	sess, err := h.service.UploadSessionStatus(ctx, req.GetSessionId())
+	if err != nil {
+		return nil, apperrors.MapErrorToStatus(err)
+	}
+
+	return toSessionStatus(sess), nil
+}
+
+func (h *FileHandler) FinalizeUploadSession(ctx context.Context, req *pb.FinalizeUploadSessionRequest) (*pb.UploadResponse, error) {
+	if req.GetSessionId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "session_id is required")
+	}
+
+	rec, err := h.service.FinalizeUploadSession(ctx, req.GetSessionId())
+	if err != nil {
+		return nil, apperrors.MapErrorToStatus(err)
+	}
+
+	return &pb.UploadResponse{
+		FileId:    rec.FileID,
+		Size:      uint64(rec.Size),
+		Checksums: service.ToChecksumsPB(rec.Checksums),
+	}, nil
+}
+
+func toSessionStatus(sess service.UploadSession) *pb.UploadSessionStatusResponse {
+	return &pb.UploadSessionStatusResponse{
+		SessionId:     sess.ID,
+		CommittedSize: sess.CommittedSize,
+		Sealed:        sess.Sealed,
+		ExpiresAt:     timestamppb.New(sess.ExpiresAt),
+	}
+}
@@ -1,50 +1,141 @@
 package handler
 
 import (
+	"context"
 	"io"
+	"time"
 
 	pb "github.com/1abobik1/proto-upload-service/gen/go/upload_service/v1"
 	"github.com/1abobik1/upload_file_service/internal/apperrors"
+	"github.com/1abobik1/upload_file_service/internal/models"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
-// чтение чанков и их объединение в единый срез
-func readUploadStream(stream interface {
-	Recv() (*pb.UploadRequest, error)
-}) ([]byte, error) {
-	var data []byte
-	for {
-		req, err := stream.Recv()
-		if err == io.EOF {
-			break
-		}
+// chunkReader превращает последовательность чанков из gRPC-стрима в io.Reader.
+// В памяти держится только текущий чанк, поэтому размер файла не ограничен RAM.
+type chunkReader struct {
+	next func() ([]byte, error)
+	buf  []byte
+	err  error
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	for len(r.buf) == 0 {
+		chunk, err := r.next()
 		if err != nil {
-			return nil, err
-		}
-		if filename := req.GetFilename(); filename != "" {
-			return nil, apperrors.ErrFilenameProvidedTwice
+			r.err = err
+			return 0, err
 		}
-		data = append(data, req.GetChunk()...)
+		r.buf = chunk
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// Err возвращает ошибку чтения стрима, если она была (io.EOF не считается ошибкой).
+func (r *chunkReader) Err() error {
+	if r.err == io.EOF {
+		return nil
+	}
+	return r.err
+}
+
+// чтение чанков Upload-стрима в виде непрерывного потока данных
+func newUploadStreamReader(stream interface {
+	Recv() (*pb.UploadRequest, error)
+}) *chunkReader {
+	return &chunkReader{
+		next: func() ([]byte, error) {
+			req, err := stream.Recv()
+			if err != nil {
+				return nil, err
+			}
+			if filename := req.GetFilename(); filename != "" {
+				return nil, apperrors.ErrFilenameProvidedTwice
+			}
+			return req.GetChunk(), nil
+		},
 	}
-	return data, nil
 }
 
-// чтение чанков и их объединения в единый срез
-func readUpdateStream(stream interface {
+// чтение чанков UpdateFile-стрима в виде непрерывного потока данных
+func newUpdateStreamReader(stream interface {
 	Recv() (*pb.UpdateFileRequest, error)
-}) ([]byte, error) {
-	var data []byte
+}) *chunkReader {
+	return &chunkReader{
+		next: func() ([]byte, error) {
+			req, err := stream.Recv()
+			if err != nil {
+				return nil, err
+			}
+			if fileID := req.GetFileId(); fileID != "" {
+				return nil, apperrors.ErrFileIDProvidedTwice
+			}
+			return req.GetChunk(), nil
+		},
+	}
+}
+
+// чтение чанков AppendUploadSession-стрима в виде непрерывного потока данных
+func newAppendStreamReader(stream interface {
+	Recv() (*pb.AppendUploadSessionRequest, error)
+}) *chunkReader {
+	return &chunkReader{
+		next: func() ([]byte, error) {
+			req, err := stream.Recv()
+			if err != nil {
+				return nil, err
+			}
+			if req.GetHeader() != nil {
+				return nil, apperrors.ErrSessionHeaderProvidedTwice
+			}
+			return req.GetChunk(), nil
+		},
+	}
+}
+
+// sendChunks читает r до конца и отправляет данные чанками по 1MB.
+func sendChunks(ctx context.Context, op string, r io.Reader, send func(chunk []byte) error) error {
+	buf := make([]byte, 1<<20)
 	for {
-		req, err := stream.Recv()
+		n, err := r.Read(buf)
+		if n > 0 {
+			if sendErr := send(buf[:n]); sendErr != nil {
+				logrus.WithError(sendErr).Errorf("%s: failed to send chunk", op)
+				return sendErr
+			}
+		}
 		if err == io.EOF {
-			break
+			return nil
 		}
 		if err != nil {
-			return nil, err
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return status.FromContextError(ctxErr).Err()
+			}
+			logrus.WithError(err).Errorf("%s: read error", op)
+			return status.Error(codes.Internal, "read error: "+err.Error())
 		}
-		if fileID := req.GetFileId(); fileID != "" {
-			return nil, apperrors.ErrFileIDProvidedTwice
-		}
-		data = append(data, req.GetChunk()...)
 	}
-	return data, nil
+}
+
+// asTime возвращает нулевое время для незаданного timestamp, чтобы фильтр по нему не применялся.
+func asTime(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+func fromChecksumsPB(sums *pb.Checksums) models.Checksums {
+	return models.Checksums{
+		SHA256: sums.GetSha256(),
+		CRC32C: sums.GetCrc32C(),
+	}
 }
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/1abobik1/proto-upload-service/gen/go/upload_service/v1"
+	"github.com/1abobik1/upload_file_service/internal/apperrors"
+	"github.com/1abobik1/upload_file_service/internal/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (h *FileHandler) ListVersions(ctx context.Context, req *pb.ListVersionsRequest) (*pb.ListVersionsResponse, error) {
+	if req.GetFileId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "file_id is required")
+	}
+
+	versions, err := h.service.ListVersions(ctx, req.GetFileId())
+	if err != nil {
+		return nil, apperrors.MapErrorToStatus(err)
+	}
+
+	return &pb.ListVersionsResponse{Versions: versions}, nil
+}
+
+func (h *FileHandler) GetVersionDownloadLink(ctx context.Context, req *pb.VersionDownloadLinkRequest) (*pb.DownloadLinkResponse, error) {
+	if req.GetFileId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "file_id is required")
+	}
+	if req.GetVersion() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "version is required")
+	}
+
+	opts := service.LinkOptions{
+		Expiry:      time.Duration(req.GetExpirySeconds()) * time.Second,
+		ContentType: req.GetContentType(),
+	}
+	url, expiresAt, err := h.service.VersionDownloadLink(ctx, req.GetFileId(), req.GetVersion(), opts)
+	if err != nil {
+		return nil, apperrors.MapErrorToStatus(err)
+	}
+
+	return &pb.DownloadLinkResponse{Url: url, ExpiresAt: timestamppb.New(expiresAt)}, nil
+}
+
+func (h *FileHandler) RollbackFile(ctx context.Context, req *pb.RollbackFileRequest) (*pb.RollbackFileResponse, error) {
+	if req.GetFileId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "file_id is required")
+	}
+	if req.GetVersion() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "version is required")
+	}
+
+	file, err := h.service.Rollback(ctx, req.GetFileId(), req.GetVersion())
+	if err != nil {
+		return nil, apperrors.MapErrorToStatus(err)
+	}
+
+	return &pb.RollbackFileResponse{File: file}, nil
+}
@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// FileRecord - метаданные файла в локальном индексе.
+type FileRecord struct {
+	FileID      string    `json:"file_id"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Checksums   Checksums `json:"checksums"`
+	Blob        string    `json:"blob,omitempty"` // SHA-256 блоба с содержимым, если файл дедуплицирован
+	Version     uint64    `json:"version"`
+	ETag        string    `json:"etag,omitempty"`
+	Owner       string    `json:"owner,omitempty"`    // subject загрузившего; пусто - файл загружен без аутентификации
+	Grantees    []string  `json:"grantees,omitempty"` // кому владелец выдал доступ
+}
+
+// Checksums - контрольные суммы содержимого в hex; пустое поле означает, что сумма неизвестна.
+type Checksums struct {
+	SHA256 string `json:"sha256,omitempty"`
+	CRC32C string `json:"crc32c,omitempty"`
+}
+
+// ObjectInfo - сведения об объекте хранилища, не зависящие от конкретного бэкенда.
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+	ContentType  string
+	ETag         string
+	UserMetadata map[string]string // ключи в каноническом виде без префикса X-Amz-Meta-, например "Filename"
+
+	// Err заполняется, если при листинге произошла ошибка; остальные поля тогда пустые
+	Err error
+}
+
+// MultipartUpload - незавершённая multipart-загрузка в хранилище.
+type MultipartUpload struct {
+	Key       string
+	UploadID  string
+	Initiated time.Time
+}
+
+// UploadPolicy - ограничения presigned-загрузки объекта клиентом напрямую в хранилище.
+type UploadPolicy struct {
+	ContentType string // пусто - тип не ограничивается
+	MinSize     int64
+	MaxSize     int64
+	// Metadata - пользовательские метаданные, с которыми хранилище сохранит объект;
+	// подписываются вместе с политикой, клиент не может их изменить
+	Metadata map[string]string
+}
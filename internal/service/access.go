@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	pb "github.com/1abobik1/proto-upload-service/gen/go/upload_service/v1"
+	"github.com/1abobik1/upload_file_service/internal/apperrors"
+	"github.com/1abobik1/upload_file_service/internal/auth"
+	"github.com/1abobik1/upload_file_service/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// владелец файла - subject токена, с которым файл был загружен
+	MetaOwner = "Owner"
+	// пользователи, которым владелец выдал доступ; через запятую, каждый экранирован url.QueryEscape
+	MetaGrantees = "Grantees"
+)
+
+// accessLevel - какие права нужны для операции над файлом.
+type accessLevel int
+
+const (
+	// чтение: владелец, пользователи, которым выдан доступ, и все - для файлов без владельца
+	accessRead accessLevel = iota
+	// изменение содержимого и откат версии: владелец и пользователи, которым выдан доступ
+	accessWrite
+	// удаление, восстановление, выдача доступа и публичные ссылки: только владелец
+	accessOwner
+)
+
+// caller - вызывающий, чьи права проверяются.
+type caller struct {
+	subject string
+	// anonymous - в запросе нет ни токена, ни сертификата клиента: аутентификация выключена
+	// или метод освобождён от неё, права не проверяются
+	anonymous bool
+}
+
+// anyCaller - внутренние обращения сервиса, которым доступны все файлы.
+var anyCaller = caller{anonymous: true}
+
+func callerFrom(ctx context.Context) caller {
+	return caller{subject: auth.Subject(ctx), anonymous: !auth.Authenticated(ctx)}
+}
+
+// canAccess проверяет права вызывающего на файл. Без аутентификации проверка не выполняется,
+// а аутентифицированный вызывающий без идентификатора не получает доступа ни к чему.
+// Файлы без владельца, загруженные до включения аутентификации, доступны всем только на чтение.
+func canAccess(c caller, rec models.FileRecord, level accessLevel) bool {
+	switch {
+	case c.anonymous:
+		return true
+	case c.subject == "":
+		return false
+	case rec.Owner == c.subject:
+		return true
+	case rec.Owner == "":
+		return level == accessRead
+	}
+	return level != accessOwner && slices.Contains(rec.Grantees, c.subject)
+}
+
+// authorize возвращает ErrPermissionDenied, если вызывающему недоступен файл rec.
+func authorize(ctx context.Context, rec models.FileRecord, level accessLevel) error {
+	if !canAccess(callerFrom(ctx), rec, level) {
+		return apperrors.ErrPermissionDenied
+	}
+	return nil
+}
+
+// authorizeMetadata - authorize для файла, известного только по метаданным объекта.
+func authorizeMetadata(ctx context.Context, metadata map[string]string, level accessLevel) error {
+	return authorize(ctx, models.FileRecord{Owner: metadata[MetaOwner], Grantees: grantees(metadata)}, level)
+}
+
+// setOwner записывает вызывающего владельцем нового файла.
+func setOwner(ctx context.Context, metadata map[string]string) {
+	if subject := auth.Subject(ctx); subject != "" {
+		metadata[MetaOwner] = subject
+	}
+}
+
+// GrantAccess выдаёт пользователю subject доступ к файлу на чтение и изменение.
+// Доступом управляет только владелец, поэтому у файлов без владельца его выдать нельзя.
+func (s *FileService) GrantAccess(ctx context.Context, fileID, subject string) (*pb.FileInfo, error) {
+	return s.changeGrantees(ctx, fileID, func(list []string) []string {
+		if slices.Contains(list, subject) {
+			return list
+		}
+		return append(list, subject)
+	})
+}
+
+// RevokeAccess отзывает доступ пользователя subject к файлу.
+func (s *FileService) RevokeAccess(ctx context.Context, fileID, subject string) (*pb.FileInfo, error) {
+	return s.changeGrantees(ctx, fileID, func(list []string) []string {
+		return slices.DeleteFunc(list, func(g string) bool { return g == subject })
+	})
+}
+
+func (s *FileService) changeGrantees(ctx context.Context, fileID string, change func([]string) []string) (*pb.FileInfo, error) {
+	const op = "location internal/service/changeGrantees()"
+
+	if isReservedKey(fileID) {
+		return nil, apperrors.ErrFileNotFound
+	}
+
+	unlock := s.fileLocks.lock(fileID)
+	defer unlock()
+
+	info, err := s.storage.StatObject(ctx, s.bucket, fileID)
+	if err != nil {
+		logrus.WithError(err).Errorf("%s: failed to get file metadata", op)
+		if isNoSuchKey(err) {
+			return nil, apperrors.ErrFileNotFound
+		}
+		return nil, fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+	}
+	metadata := info.UserMetadata
+
+	if owner := metadata[MetaOwner]; owner == "" || owner != auth.Subject(ctx) {
+		return nil, apperrors.ErrPermissionDenied
+	}
+
+	setGrantees(metadata, change(grantees(metadata)))
+	if err := s.storage.CopyObject(ctx, s.bucket, fileID, fileID, metadata); err != nil {
+		logrus.WithError(err).Errorf("%s: failed to update file metadata", op)
+		return nil, fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+	}
+
+	rec := newRecord(fileID, metadata, info.ContentType, info.Size)
+	s.indexPut(ctx, rec)
+
+	return toFileInfo(rec), nil
+}
+
+func grantees(metadata map[string]string) []string {
+	if metadata[MetaGrantees] == "" {
+		return nil
+	}
+
+	var list []string
+	for _, g := range strings.Split(metadata[MetaGrantees], ",") {
+		if subject, err := url.QueryUnescape(g); err == nil {
+			list = append(list, subject)
+		}
+	}
+	return list
+}
+
+func setGrantees(metadata map[string]string, list []string) {
+	if len(list) == 0 {
+		delete(metadata, MetaGrantees)
+		return
+	}
+
+	escaped := make([]string, len(list))
+	for i, subject := range list {
+		escaped[i] = url.QueryEscape(subject)
+	}
+	metadata[MetaGrantees] = strings.Join(escaped, ",")
+}
@@ -0,0 +1,295 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	pb "github.com/1abobik1/proto-upload-service/gen/go/upload_service/v1"
+	"github.com/1abobik1/upload_file_service/internal/apperrors"
+	"github.com/1abobik1/upload_file_service/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// manifestName - запись архива со списком пропущенных файлов в нестрогом режиме
+const manifestName = "MANIFEST.json"
+
+// ArchiveOptions - параметры архива с несколькими файлами.
+type ArchiveOptions struct {
+	// Strict - отказ, если хотя бы один файл не удалось получить. Иначе такие файлы
+	// пропускаются и перечисляются в MANIFEST.json внутри архива.
+	Strict bool
+	Format pb.ArchiveFormat // UNSPECIFIED - zip
+}
+
+type archiveEntry struct {
+	rec  models.FileRecord
+	name string // имя в архиве, уникальное среди записей
+}
+
+type skippedFile struct {
+	FileID string `json:"file_id"`
+	Reason string `json:"reason"`
+}
+
+type manifestFile struct {
+	FileID string `json:"file_id"`
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+}
+
+type archiveManifest struct {
+	Files   []manifestFile `json:"files"`
+	Skipped []skippedFile  `json:"skipped"`
+}
+
+// archivePlan - содержимое архива, определённое до начала передачи: в строгом режиме
+// об отсутствующих файлах нужно сообщить раньше, чем клиент получит первые байты.
+type archivePlan struct {
+	entries  []archiveEntry
+	skipped  []skippedFile
+	manifest string
+	strict   bool
+	format   pb.ArchiveFormat
+}
+
+// DownloadArchive возвращает архив с указанными файлами. Архив формируется по мере чтения:
+// объекты читаются из хранилища по одному, и в памяти держится только буфер копирования.
+// Закрытие reader или отмена ctx останавливают формирование архива.
+func (s *FileService) DownloadArchive(ctx context.Context, fileIDs []string, opts ArchiveOptions) (io.ReadCloser, error) {
+	const op = "location internal/service/DownloadArchive()"
+
+	if _, ok := pb.ArchiveFormat_name[int32(opts.Format)]; !ok {
+		return nil, apperrors.ErrInvalidArchiveFormat
+	}
+
+	plan, err := s.planArchive(ctx, fileIDs, opts)
+	if err != nil {
+		logrus.WithError(err).Errorf("%s: failed to prepare archive", op)
+		return nil, err
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(s.writeArchive(ctx, pw, plan))
+	}()
+	return pr, nil
+}
+
+// planArchive получает метаданные файлов и назначает им уникальные имена в архиве.
+func (s *FileService) planArchive(ctx context.Context, fileIDs []string, opts ArchiveOptions) (*archivePlan, error) {
+	const op = "location internal/service/planArchive()"
+
+	plan := &archivePlan{strict: opts.Strict, format: opts.Format}
+	used := make(map[string]bool, len(fileIDs))
+
+	for _, fileID := range fileIDs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		rec, err := s.archiveRecord(ctx, fileID)
+		if err != nil {
+			if opts.Strict {
+				return nil, err
+			}
+			logrus.WithError(err).Warnf("%s: failed to get metadata of %s, skipping", op, fileID)
+			plan.skipped = append(plan.skipped, skippedFile{FileID: fileID, Reason: skipReason(err)})
+			continue
+		}
+
+		filename := rec.Filename
+		if strings.TrimSpace(filename) == "" {
+			filename = fileID
+		}
+		// имя из метаданных не должно выводить запись за пределы каталога распаковки
+		filename = sanitizeFilename(filename)
+		plan.entries = append(plan.entries, archiveEntry{rec: rec, name: uniqueName(used, filename)})
+	}
+
+	// имя манифеста назначается последним, чтобы не переименовывать файл пользователя с таким же именем
+	plan.manifest = uniqueName(used, manifestName)
+	return plan, nil
+}
+
+func (s *FileService) archiveRecord(ctx context.Context, fileID string) (models.FileRecord, error) {
+	if isReservedKey(fileID) {
+		return models.FileRecord{}, fmt.Errorf("%w: %s", apperrors.ErrFileNotFound, fileID)
+	}
+	rec, err := s.fileRecord(ctx, fileID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrFileNotFound) {
+			return models.FileRecord{}, fmt.Errorf("%w: %s", apperrors.ErrFileNotFound, fileID)
+		}
+		return models.FileRecord{}, fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+	}
+	if err := authorize(ctx, rec, accessRead); err != nil {
+		return models.FileRecord{}, fmt.Errorf("%w: %s", err, fileID)
+	}
+	return rec, nil
+}
+
+// writeArchive пишет архив в w, получая объекты из хранилища по одному.
+func (s *FileService) writeArchive(ctx context.Context, w io.Writer, plan *archivePlan) error {
+	const op = "location internal/service/DownloadArchive()"
+
+	archive, err := newArchiveWriter(w, plan.format)
+	if err != nil {
+		return err
+	}
+	var written []manifestFile
+
+	for _, entry := range plan.entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// размер в заголовке берётся у объекта, а не из плана: файл могли обновить после планирования
+		key := dataKey(entry.rec)
+		info, err := s.storage.StatObject(ctx, s.bucket, key)
+		var obj io.ReadCloser
+		if err == nil {
+			obj, err = s.storage.GetObject(ctx, s.bucket, key)
+		}
+		var body *bufio.Reader
+		if err == nil {
+			// хранилище может сообщить об ошибке только при первом чтении, а пропустить файл можно до заголовка
+			body = bufio.NewReader(&ctxReader{ctx: ctx, r: obj})
+			if _, err = body.Peek(1); err == io.EOF {
+				err = nil
+			}
+			if err != nil {
+				obj.Close()
+			}
+		}
+		if err != nil {
+			if plan.strict {
+				return fmt.Errorf("failed to get object %s: %w", entry.rec.FileID, err)
+			}
+			logrus.WithError(err).Warnf("%s: failed to get object %s, skipping", op, entry.rec.FileID)
+			plan.skipped = append(plan.skipped, skippedFile{FileID: entry.rec.FileID, Reason: skipReason(err)})
+			continue
+		}
+
+		writer, err := archive.create(entryHeader{
+			name:      entry.name,
+			size:      info.Size,
+			createdAt: entry.rec.CreatedAt,
+			modTime:   entry.rec.UpdatedAt,
+		})
+		if err != nil {
+			obj.Close()
+			return fmt.Errorf("failed to create archive entry for %s: %w", entry.rec.FileID, err)
+		}
+
+		// запись уже начата, пропустить файл без порчи архива нельзя
+		n, err := io.Copy(writer, io.LimitReader(body, info.Size))
+		changed := n != info.Size
+		if err == nil && !changed {
+			// данные сверх размера из заголовка тоже значат, что объект перезаписали
+			_, extra := body.Peek(1)
+			changed = extra == nil
+		}
+		obj.Close()
+		if err != nil {
+			logrus.WithError(err).Errorf("%s: error copying data for %s", op, entry.rec.FileID)
+			return fmt.Errorf("failed to copy %s: %w", entry.rec.FileID, err)
+		}
+		if changed {
+			// объект перезаписали во время передачи: запись дополняется нулями до размера
+			// из заголовка, чтобы архив остался целым, а файл считается пропущенным
+			if plan.strict {
+				return fmt.Errorf("%s changed while archiving", entry.rec.FileID)
+			}
+			logrus.Warnf("%s: object %s changed while archiving, skipping", op, entry.rec.FileID)
+			if n < info.Size {
+				if _, err := io.CopyN(writer, zeros{}, info.Size-n); err != nil {
+					return fmt.Errorf("failed to copy %s: %w", entry.rec.FileID, err)
+				}
+			}
+			plan.skipped = append(plan.skipped, skippedFile{FileID: entry.rec.FileID, Reason: "changed"})
+			continue
+		}
+		written = append(written, manifestFile{FileID: entry.rec.FileID, Name: entry.name, Size: info.Size})
+	}
+
+	if len(plan.skipped) > 0 {
+		if err := writeManifest(archive, plan.manifest, written, plan.skipped); err != nil {
+			return err
+		}
+	}
+
+	if err := archive.close(); err != nil {
+		logrus.WithError(err).Errorf("%s: failed to close archive", op)
+		return fmt.Errorf("failed to close archive: %w", err)
+	}
+	return nil
+}
+
+// writeManifest добавляет в архив список записанных и пропущенных файлов.
+func writeManifest(archive archiveWriter, name string, written []manifestFile, skipped []skippedFile) error {
+	data, err := json.MarshalIndent(archiveManifest{Files: written, Skipped: skipped}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	now := time.Now()
+	writer, err := archive.create(entryHeader{name: name, size: int64(len(data)), createdAt: now, modTime: now})
+	if err != nil {
+		return fmt.Errorf("failed to create manifest entry: %w", err)
+	}
+	if _, err := writer.Write(data); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+func skipReason(err error) string {
+	switch {
+	case errors.Is(err, apperrors.ErrFileNotFound), isNoSuchKey(err):
+		return "not found"
+	case errors.Is(err, apperrors.ErrPermissionDenied):
+		return "permission denied"
+	default:
+		return "storage error"
+	}
+}
+
+// uniqueName возвращает name или, если оно уже занято, "name (2).ext", "name (3).ext" и т.д.
+func uniqueName(used map[string]bool, name string) string {
+	candidate := name
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for i := 2; used[candidate]; i++ {
+		candidate = fmt.Sprintf("%s (%d)%s", base, i, ext)
+	}
+	used[candidate] = true
+	return candidate
+}
+
+// ctxReader прерывает чтение, как только отменён ctx.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *ctxReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+// zeros - бесконечный поток нулевых байт.
+type zeros struct{}
+
+func (zeros) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
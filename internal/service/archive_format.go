@@ -0,0 +1,103 @@
+package service
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"strconv"
+	"time"
+
+	pb "github.com/1abobik1/proto-upload-service/gen/go/upload_service/v1"
+	"github.com/1abobik1/upload_file_service/internal/apperrors"
+	"github.com/klauspost/compress/zstd"
+)
+
+// entryHeader - сведения о файле, которые записываются в заголовок записи архива.
+type entryHeader struct {
+	name      string
+	size      int64 // tar пишет размер до данных, поэтому он должен совпадать с содержимым
+	createdAt time.Time
+	modTime   time.Time
+}
+
+// archiveWriter - формат архива. Записи пишутся последовательно: writer из create
+// действителен до следующего вызова create или close.
+type archiveWriter interface {
+	create(h entryHeader) (io.Writer, error)
+	close() error
+}
+
+func newArchiveWriter(w io.Writer, format pb.ArchiveFormat) (archiveWriter, error) {
+	switch format {
+	case pb.ArchiveFormat_ARCHIVE_FORMAT_UNSPECIFIED, pb.ArchiveFormat_ARCHIVE_FORMAT_ZIP:
+		return &zipArchive{w: zip.NewWriter(w)}, nil
+	case pb.ArchiveFormat_ARCHIVE_FORMAT_TAR:
+		return &tarArchive{w: tar.NewWriter(w)}, nil
+	case pb.ArchiveFormat_ARCHIVE_FORMAT_TAR_GZ:
+		gz := gzip.NewWriter(w)
+		return &tarArchive{w: tar.NewWriter(gz), compressor: gz}, nil
+	case pb.ArchiveFormat_ARCHIVE_FORMAT_TAR_ZST:
+		zw, err := zstd.NewWriter(w)
+		if err != nil {
+			return nil, err
+		}
+		return &tarArchive{w: tar.NewWriter(zw), compressor: zw}, nil
+	default:
+		return nil, apperrors.ErrInvalidArchiveFormat
+	}
+}
+
+// zipArchive пишет записи с дескриптором данных, поэтому размеры заранее не нужны;
+// для файлов и архивов больше 4GB archive/zip сам добавляет записи ZIP64.
+type zipArchive struct {
+	w *zip.Writer
+}
+
+func (a *zipArchive) create(h entryHeader) (io.Writer, error) {
+	return a.w.CreateHeader(&zip.FileHeader{
+		Name:     h.name,
+		Method:   zip.Deflate,
+		Modified: h.modTime,
+	})
+}
+
+func (a *zipArchive) close() error {
+	return a.w.Close()
+}
+
+type tarArchive struct {
+	w          *tar.Writer
+	compressor io.WriteCloser // nil - tar без сжатия
+}
+
+func (a *tarArchive) create(h entryHeader) (io.Writer, error) {
+	header := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     h.name,
+		Size:     h.size,
+		Mode:     0o644,
+		ModTime:  h.modTime,
+		Format:   tar.FormatPAX,
+	}
+	// время создания в PAX-расширении понимают bsdtar и libarchive, остальные его игнорируют
+	if !h.createdAt.IsZero() {
+		header.PAXRecords = map[string]string{
+			"LIBARCHIVE.creationtime": strconv.FormatInt(h.createdAt.Unix(), 10),
+		}
+	}
+	if err := a.w.WriteHeader(header); err != nil {
+		return nil, err
+	}
+	return a.w, nil
+}
+
+func (a *tarArchive) close() error {
+	if err := a.w.Close(); err != nil {
+		return err
+	}
+	if a.compressor != nil {
+		return a.compressor.Close()
+	}
+	return nil
+}
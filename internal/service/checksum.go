@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"hash/crc32"
+	"io"
+	"strings"
+
+	"github.com/1abobik1/upload_file_service/internal/apperrors"
+	"github.com/1abobik1/upload_file_service/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	MetaSHA256 = "Sha256"
+	MetaCRC32C = "Crc32c"
+)
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// WithCRC32C включает подсчёт CRC32C вместе с SHA-256. Если клиент прислал CRC32C,
+// она проверяется независимо от этой настройки.
+func WithCRC32C(enabled bool) Option {
+	return func(s *FileService) {
+		s.crc32c = enabled
+	}
+}
+
+// checksumReader считает контрольные суммы и размер проходящих данных.
+// На io.EOF суммы сверяются с ожидаемыми: при несовпадении вместо io.EOF возвращается
+// ErrChecksumMismatch, и хранилище отменяет запись объекта.
+type checksumReader struct {
+	r        io.Reader
+	sha256   hash.Hash
+	crc32c   hash.Hash32 // nil - CRC32C не считается
+	expected models.Checksums
+	n        uint64
+}
+
+func (s *FileService) newChecksumReader(r io.Reader, expected models.Checksums) *checksumReader {
+	c := &checksumReader{
+		r:        r,
+		sha256:   sha256.New(),
+		expected: expected,
+	}
+	if s.crc32c || expected.CRC32C != "" {
+		c.crc32c = crc32.New(crc32cTable)
+	}
+	return c
+}
+
+func (c *checksumReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += uint64(n)
+	c.sha256.Write(p[:n])
+	if c.crc32c != nil {
+		c.crc32c.Write(p[:n])
+	}
+
+	if err == io.EOF {
+		if verifyErr := c.verify(); verifyErr != nil {
+			return n, verifyErr
+		}
+	}
+	return n, err
+}
+
+func (c *checksumReader) verify() error {
+	sums := c.sums()
+	if c.expected.SHA256 != "" && c.expected.SHA256 != sums.SHA256 {
+		return apperrors.ErrChecksumMismatch
+	}
+	if c.expected.CRC32C != "" && c.expected.CRC32C != sums.CRC32C {
+		return apperrors.ErrChecksumMismatch
+	}
+	return nil
+}
+
+func (c *checksumReader) sums() models.Checksums {
+	return checksumsOf(c.sha256, c.crc32c)
+}
+
+func checksumsOf(sha hash.Hash, crc hash.Hash32) models.Checksums {
+	sums := models.Checksums{SHA256: hex.EncodeToString(sha.Sum(nil))}
+	if crc != nil {
+		sums.CRC32C = hex.EncodeToString(crc.Sum(nil))
+	}
+	return sums
+}
+
+// normalizeChecksums проверяет формат присланных клиентом сумм и приводит их к нижнему регистру.
+func normalizeChecksums(sums models.Checksums) (models.Checksums, error) {
+	sums.SHA256 = strings.ToLower(sums.SHA256)
+	sums.CRC32C = strings.ToLower(sums.CRC32C)
+
+	if sums.SHA256 != "" && !isHex(sums.SHA256, sha256.Size) {
+		return models.Checksums{}, apperrors.ErrInvalidChecksum
+	}
+	if sums.CRC32C != "" && !isHex(sums.CRC32C, crc32.Size) {
+		return models.Checksums{}, apperrors.ErrInvalidChecksum
+	}
+	return sums, nil
+}
+
+func isHex(s string, size int) bool {
+	b, err := hex.DecodeString(s)
+	return err == nil && len(b) == size
+}
+
+// setChecksums записывает суммы в метаданные объекта; пустые суммы удаляются,
+// чтобы не остались суммы предыдущего содержимого.
+func setChecksums(metadata map[string]string, sums models.Checksums) {
+	for key, value := range map[string]string{MetaSHA256: sums.SHA256, MetaCRC32C: sums.CRC32C} {
+		if value == "" {
+			delete(metadata, key)
+		} else {
+			metadata[key] = value
+		}
+	}
+}
+
+// storeChecksums дописывает посчитанные суммы в метаданные уже сохранённого объекта,
+// если они не были известны до записи. Копия объекта в самого себя меняет только метаданные,
+// содержимое не перезаписывается. Ошибка только логируется: данные уже сохранены
+// и проверены, а суммы клиент получает в ответе.
+func (s *FileService) storeChecksums(ctx context.Context, fileID string, metadata map[string]string, sums models.Checksums) {
+	const op = "location internal/service/storeChecksums()"
+
+	if metadata[MetaSHA256] == sums.SHA256 && metadata[MetaCRC32C] == sums.CRC32C {
+		return
+	}
+
+	setChecksums(metadata, sums)
+	if err := s.storage.CopyObject(ctx, s.bucket, fileID, fileID, metadata); err != nil {
+		logrus.WithError(err).Warnf("%s: failed to store checksums of %s", op, fileID)
+	}
+}
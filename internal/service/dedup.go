@@ -0,0 +1,303 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+
+	"github.com/1abobik1/upload_file_service/internal/apperrors"
+	"github.com/1abobik1/upload_file_service/internal/models"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// метаданные указателя: идентификатор блоба с содержимым и размер файла;
+	// SHA-256 содержимого хранится в MetaSHA256, как у обычных файлов
+	MetaBlob = "Blob"
+	MetaSize = "Size"
+	// MetaRefcount - счётчик ссылок в метаданных блобов, созданных до появления записей refsPrefix
+	MetaRefcount = "Refcount"
+
+	// содержимое блоба хранится под blobPrefix + идентификатор блоба
+	blobPrefix = ".blobs/"
+	// stagingPrefix - промежуточные объекты прежних версий сервиса; новые загрузки пишутся сразу в блоб
+	stagingPrefix = ".staging/"
+	// refsPrefix + SHA-256 - запись о блобе с таким содержимым и числе ссылок на него
+	refsPrefix = metaPrefix + "refs/"
+)
+
+// WithDedup включает дедупликацию: содержимое файлов хранится в блобах, одинаковое
+// содержимое - в одном блобе, а под file_id лежит пустой указатель на блоб.
+// Файлы, загруженные до включения, продолжают храниться как обычные объекты.
+func WithDedup(enabled bool) Option {
+	return func(s *FileService) {
+		s.dedup = enabled
+	}
+}
+
+// blobRefs - запись о блобе с содержимым одного SHA-256. Запись меняется только
+// условной записью (CompareAndSwapObject), поэтому счётчик ссылок верен и при нескольких
+// экземплярах сервиса. Запись с нулём ссылок остаётся после удаления блоба и занимается
+// следующим блобом с тем же содержимым.
+type blobRefs struct {
+	Blob string `json:"blob"`
+	Size int64  `json:"size"`
+	Refs int    `json:"refs"`
+}
+
+// putDeduplicated сохраняет содержимое в блоб и записывает под fileID указатель на него.
+// Данные пишутся сразу в новый блоб; если такое содержимое уже хранится, новый блоб
+// удаляется, а указатель ссылается на существующий.
+func (s *FileService) putDeduplicated(ctx context.Context, fileID, contentType string, body io.Reader, expected models.Checksums, metadata map[string]string) (models.Checksums, int64, error) {
+	sums := s.newChecksumReader(body, expected)
+
+	blob := ""
+	if expected.SHA256 != "" {
+		blob = s.storedBlob(ctx, expected.SHA256)
+	}
+	if blob != "" {
+		// такое содержимое уже хранится: данные только проверяются и не записываются повторно
+		if _, err := io.Copy(io.Discard, sums); err != nil {
+			return models.Checksums{}, 0, storageError(err)
+		}
+		if err := s.addBlobRef(ctx, expected.SHA256, blob); err != nil {
+			return models.Checksums{}, 0, fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+		}
+	} else {
+		id := uuid.New().String()
+		if err := s.storage.PutObject(ctx, s.bucket, blobKey(id), contentType, sums, -1, nil); err != nil {
+			return models.Checksums{}, 0, storageError(err)
+		}
+
+		var err error
+		blob, err = s.registerBlob(ctx, sums.sums().SHA256, id, int64(sums.n))
+		if err != nil {
+			return models.Checksums{}, 0, fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+		}
+	}
+
+	checksums := sums.sums()
+	setChecksums(metadata, checksums)
+	if err := s.putPointer(ctx, fileID, contentType, blob, int64(sums.n), metadata); err != nil {
+		s.releaseBlob(ctx, metadata)
+		return models.Checksums{}, 0, fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+	}
+
+	return checksums, int64(sums.n), nil
+}
+
+// adoptBlob регистрирует блоб id, в который уже записано содержимое файла, и сохраняет
+// под fileID указатель на него.
+func (s *FileService) adoptBlob(ctx context.Context, fileID, id, contentType string, sums models.Checksums, size int64, metadata map[string]string) error {
+	blob, err := s.registerBlob(ctx, sums.SHA256, id, size)
+	if err != nil {
+		return err
+	}
+
+	setChecksums(metadata, sums)
+	if err := s.putPointer(ctx, fileID, contentType, blob, size, metadata); err != nil {
+		s.releaseBlob(ctx, metadata)
+		return err
+	}
+	return nil
+}
+
+func (s *FileService) putPointer(ctx context.Context, fileID, contentType, blob string, size int64, metadata map[string]string) error {
+	metadata[MetaBlob] = blob
+	metadata[MetaSize] = strconv.FormatInt(size, 10)
+	return s.storage.PutObject(ctx, s.bucket, fileID, contentType, bytes.NewReader(nil), 0, metadata)
+}
+
+// storedBlob возвращает идентификатор хранящегося блоба с содержимым sha или пустую строку.
+func (s *FileService) storedBlob(ctx context.Context, sha string) string {
+	var refs blobRefs
+	if err := s.readMeta(ctx, refsKey(sha), &refs); err != nil {
+		refs = s.legacyRefs(ctx, sha)
+	}
+	if refs.Refs == 0 {
+		return ""
+	}
+	return refs.Blob
+}
+
+// registerBlob добавляет ссылку на содержимое sha, только что записанное в блоб id.
+// Если такое содержимое уже хранится, ссылка добавляется к существующему блобу, а id
+// удаляется. Возвращает идентификатор блоба, на который нужно сослаться.
+func (s *FileService) registerBlob(ctx context.Context, sha, id string, size int64) (string, error) {
+	refs, err := updateMeta(ctx, s, refsKey(sha), func(refs *blobRefs, found bool) error {
+		if !found {
+			*refs = s.legacyRefs(ctx, sha)
+		}
+		if refs.Refs > 0 {
+			refs.Refs++
+		} else {
+			*refs = blobRefs{Blob: id, Size: size, Refs: 1}
+		}
+		return nil
+	})
+	if err != nil {
+		s.removeBlobData(ctx, id)
+		return "", err
+	}
+
+	if refs.Blob != id {
+		s.removeBlobData(ctx, id)
+	} else {
+		s.stats.add(statsDelta{Blobs: 1, StoredBytes: size})
+	}
+	return refs.Blob, nil
+}
+
+// addBlobRef добавляет ссылку на хранящийся блоб, например от прежней версии файла.
+func (s *FileService) addBlobRef(ctx context.Context, sha, blob string) error {
+	_, err := updateMeta(ctx, s, refsKey(sha), func(refs *blobRefs, found bool) error {
+		if !found {
+			*refs = s.legacyRefs(ctx, sha)
+		}
+		if refs.Refs == 0 || refs.Blob != blob {
+			// последнюю ссылку удалили, пока проверялись данные
+			return fmt.Errorf("blob %s was removed", blob)
+		}
+		refs.Refs++
+		return nil
+	})
+	return err
+}
+
+// addPointerRef добавляет ссылку на блоб указателя с метаданными metadata; для обычных файлов ничего не делает.
+func (s *FileService) addPointerRef(ctx context.Context, metadata map[string]string) error {
+	sha, blob := pointerBlob(metadata)
+	if blob == "" {
+		return nil
+	}
+	return s.addBlobRef(ctx, sha, blob)
+}
+
+// releaseBlob снимает ссылку указателя с метаданными metadata на блоб и удаляет блоб,
+// когда ссылок не осталось. Для обычных файлов ничего не делает.
+func (s *FileService) releaseBlob(ctx context.Context, metadata map[string]string) {
+	const op = "location internal/service/releaseBlob()"
+
+	sha, blob := pointerBlob(metadata)
+	if blob == "" {
+		return
+	}
+
+	removed := false
+	refs, err := updateMeta(ctx, s, refsKey(sha), func(refs *blobRefs, found bool) error {
+		removed = false
+		if !found {
+			*refs = s.legacyRefs(ctx, sha)
+		}
+		if refs.Refs == 0 || refs.Blob != blob {
+			// блоб уже удалён
+			return errMetaUnchanged
+		}
+		refs.Refs--
+		removed = refs.Refs == 0
+		return nil
+	})
+	if err != nil {
+		logrus.WithError(err).Warnf("%s: failed to release blob %s", op, blob)
+		return
+	}
+
+	if removed {
+		s.removeBlobData(ctx, blob)
+		s.stats.add(statsDelta{Blobs: -1, StoredBytes: -refs.Size})
+	}
+}
+
+func (s *FileService) removeBlobData(ctx context.Context, blob string) {
+	const op = "location internal/service/removeBlobData()"
+
+	if err := s.storage.RemoveObject(ctx, s.bucket, blobKey(blob)); err != nil {
+		logrus.WithError(err).Warnf("%s: failed to remove blob %s", op, blob)
+	}
+}
+
+// legacyRefs читает счётчик ссылок из метаданных блоба, созданного до появления записей
+// refsPrefix: такие блобы хранятся под SHA-256 содержимого. Первое изменение счётчика
+// переносит его в запись.
+func (s *FileService) legacyRefs(ctx context.Context, sha string) blobRefs {
+	info, err := s.storage.StatObject(ctx, s.bucket, blobKey(sha))
+	if err != nil {
+		return blobRefs{}
+	}
+	return blobRefs{Blob: sha, Size: info.Size, Refs: refcount(info.UserMetadata)}
+}
+
+// pointerBlob возвращает SHA-256 и идентификатор блоба указателя; для обычных файлов blob пустой.
+// У указателей, созданных до появления записей refsPrefix, идентификатор совпадает с SHA-256.
+func pointerBlob(metadata map[string]string) (sha, blob string) {
+	blob = metadata[MetaBlob]
+	sha = metadata[MetaSHA256]
+	if sha == "" {
+		sha = blob
+	}
+	return sha, blob
+}
+
+// dataKey - ключ объекта, в котором лежит содержимое файла.
+func dataKey(rec models.FileRecord) string {
+	if rec.Blob != "" {
+		return blobKey(rec.Blob)
+	}
+	return rec.FileID
+}
+
+func blobKey(blob string) string {
+	return blobPrefix + blob
+}
+
+func refsKey(sha string) string {
+	return refsPrefix + sha
+}
+
+func refcount(metadata map[string]string) int {
+	n, _ := strconv.Atoi(metadata[MetaRefcount])
+	return n
+}
+
+// keyedMutex - мьютексы по ключу. Нулевое значение готово к использованию,
+// запись о ключе удаляется, когда мьютекс никто не ждёт.
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+type keyedLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+func (k *keyedMutex) lock(key string) (unlock func()) {
+	k.mu.Lock()
+	if k.locks == nil {
+		k.locks = make(map[string]*keyedLock)
+	}
+	l := k.locks[key]
+	if l == nil {
+		l = &keyedLock{}
+		k.locks[key] = l
+	}
+	l.refs++
+	k.mu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+
+		k.mu.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(k.locks, key)
+		}
+		k.mu.Unlock()
+	}
+}
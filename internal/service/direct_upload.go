@@ -0,0 +1,376 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/1abobik1/upload_file_service/internal/apperrors"
+	"github.com/1abobik1/upload_file_service/internal/auth"
+	"github.com/1abobik1/upload_file_service/internal/models"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultDirectUploadExpiry  = time.Hour
+	defaultDirectUploadMaxSize = 5 << 30
+
+	// клиент загружает объект под pendingPrefix + file_id; файлом он становится после CompleteDirectUpload
+	pendingPrefix = ".pending/"
+
+	// метаданные загруженного по форме объекта: объявленные размер и тип; имя файла,
+	// владелец и срок действия формы хранятся в MetaFilename, MetaOwner и MetaExpiresAt
+	MetaUploadSize = "Uploadsize"
+	MetaUploadType = "Uploadtype"
+)
+
+// WithDirectUpload задаёт срок действия формы прямой загрузки и максимальный размер файла.
+func WithDirectUpload(expiry time.Duration, maxSize int64) Option {
+	return func(s *FileService) {
+		if expiry > 0 {
+			s.directUploads.expiry = min(expiry, maxPresignExpiry)
+		}
+		if maxSize > 0 {
+			s.directUploads.maxSize = maxSize
+		}
+	}
+}
+
+// DirectUpload - форма для загрузки файла напрямую в хранилище multipart/form-data
+// POST-запросом на URL: сначала поля FormFields, последним - поле file с содержимым.
+type DirectUpload struct {
+	FileID     string
+	URL        string
+	FormFields map[string]string
+	ExpiresAt  time.Time
+}
+
+type directUpload struct {
+	fileID      string
+	owner       string // subject создавшего; другим пользователям форма не видна
+	filename    string
+	contentType string // пусто - тип не ограничивался
+	size        int64  // 0 - размер не объявлялся
+	expiresAt   time.Time
+}
+
+// reservation - метаданные, которые подписываются вместе с формой и сохраняются
+// хранилищем на загруженном объекте. Пустые значения не передаются: хранилище их не принимает.
+func (u *directUpload) reservation() map[string]string {
+	metadata := map[string]string{
+		MetaFilename:  u.filename,
+		MetaExpiresAt: u.expiresAt.Format(time.RFC3339),
+	}
+	if u.owner != "" {
+		metadata[MetaOwner] = u.owner
+	}
+	if u.contentType != "" {
+		metadata[MetaUploadType] = u.contentType
+	}
+	if u.size > 0 {
+		metadata[MetaUploadSize] = strconv.FormatInt(u.size, 10)
+	}
+	return metadata
+}
+
+// reservedUpload восстанавливает форму по метаданным загруженного по ней объекта.
+func reservedUpload(fileID string, metadata map[string]string) (*directUpload, bool) {
+	expiresAt, err := time.Parse(time.RFC3339, metadata[MetaExpiresAt])
+	if err != nil || metadata[MetaFilename] == "" {
+		return nil, false
+	}
+	size, _ := strconv.ParseInt(metadata[MetaUploadSize], 10, 64)
+	return &directUpload{
+		fileID:      fileID,
+		owner:       metadata[MetaOwner],
+		filename:    metadata[MetaFilename],
+		contentType: metadata[MetaUploadType],
+		size:        size,
+		expiresAt:   expiresAt,
+	}, true
+}
+
+// directUploads хранит выданные формы до завершения загрузки или истечения срока.
+// После загрузки форма восстанавливается из метаданных объекта, поэтому завершить
+// загрузку можно и после перезапуска сервиса.
+type directUploads struct {
+	mu      sync.Mutex
+	byID    map[string]*directUpload
+	expiry  time.Duration
+	maxSize int64
+}
+
+func newDirectUploads(expiry time.Duration, maxSize int64) *directUploads {
+	return &directUploads{
+		byID:    make(map[string]*directUpload),
+		expiry:  expiry,
+		maxSize: maxSize,
+	}
+}
+
+func (du *directUploads) get(fileID, owner string) (*directUpload, error) {
+	du.mu.Lock()
+	defer du.mu.Unlock()
+
+	upload, ok := du.byID[fileID]
+	if !ok || upload.owner != owner || time.Now().After(upload.expiresAt) {
+		return nil, apperrors.ErrDirectUploadNotFound
+	}
+	return upload, nil
+}
+
+func (du *directUploads) put(upload *directUpload) {
+	du.mu.Lock()
+	defer du.mu.Unlock()
+	du.byID[upload.fileID] = upload
+}
+
+func (du *directUploads) remove(fileID string) {
+	du.mu.Lock()
+	defer du.mu.Unlock()
+	delete(du.byID, fileID)
+}
+
+// removeExpired удаляет просроченные формы и возвращает, сколько их было.
+func (du *directUploads) removeExpired(now time.Time) int {
+	du.mu.Lock()
+	defer du.mu.Unlock()
+
+	n := 0
+	for id, upload := range du.byID {
+		if now.After(upload.expiresAt) {
+			delete(du.byID, id)
+			n++
+		}
+	}
+	return n
+}
+
+// CreateDirectUpload резервирует file_id и выдаёт подписанную форму для загрузки файла
+// напрямую в хранилище. size > 0 и contentType, если заданы, проверяются хранилищем при загрузке
+// и повторно в CompleteDirectUpload. Имя файла, владелец и срок действия формы подписываются
+// вместе с ней и сохраняются в метаданных загруженного объекта.
+func (s *FileService) CreateDirectUpload(ctx context.Context, filename, contentType string, size int64) (DirectUpload, error) {
+	const op = "location internal/service/CreateDirectUpload()"
+
+	if size > s.directUploads.maxSize {
+		return DirectUpload{}, apperrors.ErrFileTooLarge
+	}
+	if contentType != "" {
+		if _, _, err := mime.ParseMediaType(contentType); err != nil {
+			return DirectUpload{}, apperrors.ErrInvalidContentType
+		}
+	}
+
+	filename, fileID := prepareFilename(filename, contentType)
+
+	upload := &directUpload{
+		fileID:      fileID,
+		owner:       auth.Subject(ctx),
+		filename:    filename,
+		contentType: contentType,
+		size:        size,
+		expiresAt:   time.Now().Add(s.directUploads.expiry),
+	}
+
+	policy := models.UploadPolicy{
+		ContentType: contentType,
+		MinSize:     size,
+		MaxSize:     s.directUploads.maxSize,
+		Metadata:    upload.reservation(),
+	}
+	if size > 0 {
+		policy.MaxSize = size
+	}
+
+	u, fields, err := s.storage.PresignedPostPolicy(ctx, s.bucket, pendingPrefix+fileID, s.directUploads.expiry, policy)
+	if err != nil {
+		logrus.WithError(err).Errorf("%s: failed to presign upload of %s", op, fileID)
+		return DirectUpload{}, fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+	}
+
+	s.directUploads.put(upload)
+
+	return DirectUpload{
+		FileID:     fileID,
+		URL:        u.String(),
+		FormFields: fields,
+		ExpiresAt:  upload.expiresAt,
+	}, nil
+}
+
+// CompleteDirectUpload проверяет загруженный клиентом объект и делает его файлом с метаданными.
+// Если объект не совпадает с объявленным размером или типом, он удаляется, а форму
+// можно использовать повторно, пока не истёк её срок. Контрольные суммы считаются при
+// завершении чтением объекта, при включённой дедупликации содержимое переносится в блоб.
+func (s *FileService) CompleteDirectUpload(ctx context.Context, fileID string) (models.FileRecord, error) {
+	const op = "location internal/service/CompleteDirectUpload()"
+
+	unlock := s.fileLocks.lock(fileID)
+	defer unlock()
+
+	subject := auth.Subject(ctx)
+	pending := pendingPrefix + fileID
+	info, err := s.storage.StatObject(ctx, s.bucket, pending)
+	if err != nil {
+		if isNoSuchKey(err) {
+			// о форме, по которой ещё ничего не загружено, знает только выдавший её экземпляр
+			if _, err := s.directUploads.get(fileID, subject); err != nil {
+				return models.FileRecord{}, err
+			}
+			return models.FileRecord{}, apperrors.ErrDirectUploadIncomplete
+		}
+		logrus.WithError(err).Errorf("%s: failed to stat %s", op, pending)
+		return models.FileRecord{}, fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+	}
+
+	upload, ok := reservedUpload(fileID, info.UserMetadata)
+	if !ok || upload.owner != subject || time.Now().After(upload.expiresAt) {
+		return models.FileRecord{}, apperrors.ErrDirectUploadNotFound
+	}
+
+	if !upload.matches(info, s.directUploads.maxSize) {
+		logrus.Warnf("%s: object %s (%d bytes, %q) does not match direct upload", op, pending, info.Size, info.ContentType)
+		s.removePending(ctx, fileID)
+		return models.FileRecord{}, apperrors.ErrDirectUploadMismatch
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	metadata := map[string]string{
+		MetaFilename:  upload.filename,
+		MetaCreatedAt: now,
+		MetaUpdatedAt: now,
+		MetaETag:      newETag(),
+	}
+	if upload.owner != "" {
+		metadata[MetaOwner] = upload.owner
+	}
+	sums, err := s.promotePending(ctx, fileID, info, metadata)
+	if err != nil {
+		logrus.WithError(err).Errorf("%s: failed to promote %s", op, pending)
+		return models.FileRecord{}, fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+	}
+	s.directUploads.remove(fileID)
+	s.removePending(ctx, fileID)
+
+	rec := newRecord(fileID, metadata, info.ContentType, info.Size)
+	rec.Checksums = sums
+	s.indexPut(ctx, rec)
+
+	return rec, nil
+}
+
+// promotePending сохраняет загруженный объект под fileID с метаданными metadata: копией
+// объекта или, при дедупликации, указателем на блоб, - и возвращает его контрольные суммы.
+// Суммы считаются по копии: объект под pendingPrefix клиент может перезаписать той же формой.
+func (s *FileService) promotePending(ctx context.Context, fileID string, info models.ObjectInfo, metadata map[string]string) (models.Checksums, error) {
+	const op = "location internal/service/promotePending()"
+
+	if !s.dedup {
+		if err := s.storage.CopyObject(ctx, s.bucket, info.Key, fileID, metadata); err != nil {
+			return models.Checksums{}, err
+		}
+		sums, err := s.readChecksums(ctx, fileID)
+		if err != nil {
+			if err := s.storage.RemoveObject(ctx, s.bucket, fileID); err != nil {
+				logrus.WithError(err).Warnf("%s: failed to remove copy of %s", op, info.Key)
+			}
+			return models.Checksums{}, err
+		}
+		s.countObject(fileID, metadata, info.Size)
+		s.storeChecksums(ctx, fileID, metadata, sums)
+		return sums, nil
+	}
+
+	id := uuid.New().String()
+	if err := s.storage.CopyObject(ctx, s.bucket, info.Key, blobKey(id), nil); err != nil {
+		return models.Checksums{}, err
+	}
+	sums, err := s.readChecksums(ctx, blobKey(id))
+	if err != nil {
+		s.removeBlobData(ctx, id)
+		return models.Checksums{}, err
+	}
+	if err := s.adoptBlob(ctx, fileID, id, info.ContentType, sums, info.Size, metadata); err != nil {
+		return models.Checksums{}, err
+	}
+	s.countObject(fileID, metadata, 0)
+	return sums, nil
+}
+
+// readChecksums считает контрольные суммы объекта key, читая его целиком.
+func (s *FileService) readChecksums(ctx context.Context, key string) (models.Checksums, error) {
+	obj, err := s.storage.GetObject(ctx, s.bucket, key)
+	if err != nil {
+		return models.Checksums{}, err
+	}
+	defer obj.Close()
+
+	sums := s.newChecksumReader(obj, models.Checksums{})
+	if _, err := io.Copy(io.Discard, sums); err != nil {
+		return models.Checksums{}, err
+	}
+	return sums.sums(), nil
+}
+
+func (u *directUpload) matches(info models.ObjectInfo, maxSize int64) bool {
+	if u.size > 0 && info.Size != u.size {
+		return false
+	}
+	if info.Size > maxSize {
+		return false
+	}
+	return u.contentType == "" || info.ContentType == u.contentType
+}
+
+func (s *FileService) removePending(ctx context.Context, fileID string) {
+	const op = "location internal/service/removePending()"
+
+	if err := s.storage.RemoveObject(ctx, s.bucket, pendingPrefix+fileID); err != nil {
+		logrus.WithError(err).Warnf("%s: failed to remove pending object of %s", op, fileID)
+	}
+}
+
+// CleanupExpiredDirectUploads забывает просроченные формы и удаляет объекты, загруженные
+// по ним, но не завершённые. Срок формы берётся из метаданных объекта, поэтому объекты
+// не теряются и после перезапуска. Объекты без этих метаданных удаляются через срок
+// действия формы после загрузки.
+func (s *FileService) CleanupExpiredDirectUploads(ctx context.Context) int {
+	const op = "location internal/service/CleanupExpiredDirectUploads()"
+
+	now := time.Now()
+	n := s.directUploads.removeExpired(now)
+
+	for obj := range s.storage.ListObjects(ctx, s.bucket, pendingPrefix, "") {
+		if obj.Err != nil {
+			logrus.WithError(obj.Err).Warnf("%s: failed to list pending objects", op)
+			break
+		}
+		fileID := strings.TrimPrefix(obj.Key, pendingPrefix)
+		deadline := obj.LastModified.Add(s.directUploads.expiry)
+		if upload, ok := reservedUpload(fileID, obj.UserMetadata); ok {
+			deadline = upload.expiresAt
+		}
+		if now.Before(deadline) {
+			continue
+		}
+		s.removePending(ctx, fileID)
+	}
+	return n
+}
+
+// RunDirectUploadJanitor периодически чистит просроченные прямые загрузки, пока не отменён ctx.
+func (s *FileService) RunDirectUploadJanitor(ctx context.Context, interval time.Duration) {
+	runEvery(ctx, interval, func() {
+		if n := s.CleanupExpiredDirectUploads(ctx); n > 0 {
+			logrus.Infof("cleaned up %d expired direct uploads", n)
+		}
+	})
+}
@@ -1,221 +1,495 @@
 package service
 
 import (
-	"archive/zip"
-	"bytes"
+	"bufio"
 	"context"
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"mime"
 	"net/http"
 	"net/url"
+	"path"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	pb "github.com/1abobik1/proto-upload-service/gen/go/upload_service/v1"
 	"github.com/1abobik1/upload_file_service/internal/apperrors"
+	"github.com/1abobik1/upload_file_service/internal/models"
 	"github.com/google/uuid"
-	"github.com/minio/minio-go/v7"
 	"github.com/sirupsen/logrus"
-	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 const (
 	MetaFilename  = "Filename"
 	MetaCreatedAt = "Createdat"
 	MetaUpdatedAt = "Updatedat"
+	// ETag меняется при каждой записи содержимого; у файлов, загруженных до его появления,
+	// он пустой до первого обновления
+	MetaETag = "Etag"
 )
 
+// sniffLen - сколько байт нужно http.DetectContentType для определения типа
+const sniffLen = 512
+
+// MinIOStorageI - контракт хранилища объектов. Реализации сообщают об отсутствии объекта
+// через apperrors.ErrObjectNotFound.
 type MinIOStorageI interface {
 	PutObject(ctx context.Context, bucket, objectName, contentType string, reader io.Reader, objectSize int64, metadata map[string]string) error
 	GetObject(ctx context.Context, bucket string, objectName string) (io.ReadCloser, error)
-	StatObject(ctx context.Context, bucket string, objectName string) (map[string]string, time.Time, int64, error)
-	ListObjects(ctx context.Context, bucket string) <-chan minio.ObjectInfo
-	PresignedGetObject(ctx context.Context, bucket string, objectName string, expiry time.Duration) (*url.URL, error)
+	GetObjectRange(ctx context.Context, bucket, objectName string, offset, length int64) (io.ReadCloser, error)
+	StatObject(ctx context.Context, bucket string, objectName string) (models.ObjectInfo, error)
+	ListObjects(ctx context.Context, bucket string, prefix string, startAfter string) <-chan models.ObjectInfo
+	PresignedGetObject(ctx context.Context, bucket string, objectName string, expiry time.Duration, reqParams url.Values) (*url.URL, error)
+	PresignedPostPolicy(ctx context.Context, bucket, objectName string, expiry time.Duration, policy models.UploadPolicy) (*url.URL, map[string]string, error)
+	// CopyObject с srcObject == dstObject только заменяет метаданные, не перезаписывая содержимое
+	CopyObject(ctx context.Context, bucket, srcObject, dstObject string, metadata map[string]string) error
+	RemoveObject(ctx context.Context, bucket string, objectName string) error
+	// ReadObject читает небольшой объект целиком вместе с его ETag
+	ReadObject(ctx context.Context, bucket, objectName string) ([]byte, string, error)
+	// CompareAndSwapObject записывает data, только если ETag объекта всё ещё равен etag
+	// (пустой etag - объекта ещё нет); иначе возвращает apperrors.ErrObjectModified
+	CompareAndSwapObject(ctx context.Context, bucket, objectName, etag string, data []byte) error
+	NewMultipartUpload(ctx context.Context, bucket, objectName, contentType string, metadata map[string]string) (string, error)
+	PutObjectPart(ctx context.Context, bucket, objectName, uploadID string, partNumber int, reader io.Reader, size int64) (string, error)
+	CompleteMultipartUpload(ctx context.Context, bucket, objectName, uploadID string, etags []string) error
+	AbortMultipartUpload(ctx context.Context, bucket, objectName, uploadID string) error
+	ListMultipartUploads(ctx context.Context, bucket string) ([]models.MultipartUpload, error)
 }
 
 type FileService struct {
 	storage MinIOStorageI
 	bucket  string
+
+	index          FileIndexI // nil - индекс выключен, метаданные читаются из хранилища
+	sessions       *uploadSessions
+	directUploads  *directUploads
+	trashRetention time.Duration
+	crc32c         bool
+	dedup          bool
+	stats          statsCounter // изменения статистики, ещё не записанные в бакет
+	fileLocks      keyedMutex   // сериализует изменения содержимого одного файла
+	shareLocks     keyedMutex   // сериализует подсчёт скачиваний по ссылке
+	shareAttempts  *passwordAttempts
+	shareURL       string // пусто - публичные ссылки выключены
+
+	versionMaxCount int           // 0 - без ограничения по количеству
+	versionMaxAge   time.Duration // 0 - без ограничения по возрасту
+
+	linkExpiry    time.Duration
+	linkMaxExpiry time.Duration
 }
 
-func NewFileService(storage MinIOStorageI, bucket string) *FileService {
-	return &FileService{
-		storage: storage,
-		bucket:  bucket,
+// Option настраивает необязательные параметры FileService.
+type Option func(*FileService)
+
+func NewFileService(storage MinIOStorageI, bucket string, opts ...Option) *FileService {
+	s := &FileService{
+		storage:        storage,
+		bucket:         bucket,
+		sessions:       newUploadSessions(defaultUploadSessionTTL, defaultUploadPartSize),
+		directUploads:  newDirectUploads(defaultDirectUploadExpiry, defaultDirectUploadMaxSize),
+		trashRetention: defaultTrashRetention,
+		shareAttempts:  newPasswordAttempts(defaultSharePasswordMaxAttempts, defaultSharePasswordLockout),
+
+		versionMaxCount: defaultVersionMaxCount,
+
+		linkExpiry:    defaultLinkExpiry,
+		linkMaxExpiry: maxPresignExpiry,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
-func (s *FileService) Upload(ctx context.Context, filename string, data []byte) (string, uint64, error) {
+// Upload сохраняет файл и возвращает его запись. Непустые суммы в expected сверяются
+// с полученными данными; при несовпадении файл не сохраняется.
+func (s *FileService) Upload(ctx context.Context, filename string, r io.Reader, expected models.Checksums) (models.FileRecord, error) {
 	const op = "location internal/service/Upload()"
 
-	contentType := http.DetectContentType(data)
-	ext := getExtensionFromMIME(contentType)
-
-	// на случай, если клиент по какой-то причине не указал расширение файла в названии
-	originalExt := filepath.Ext(filename)
-	if originalExt == "" && ext != "" {
-		filename = fmt.Sprintf("%s%s", filename, ext)
+	expected, err := normalizeChecksums(expected)
+	if err != nil {
+		return models.FileRecord{}, err
 	}
 
-	fileID := generateFileID(filename, ext)
+	contentType, body, err := sniffContentType(r)
+	if err != nil {
+		logrus.WithError(err).Errorf("%s: failed to read file header", op)
+		return models.FileRecord{}, err
+	}
+	filename, fileID := prepareFilename(filename, contentType)
 
 	now := time.Now().Format(time.RFC3339)
-	err := s.storage.PutObject(
-		ctx,
-		s.bucket,
-		fileID,
-		contentType,
-		bytes.NewReader(data),
-		int64(len(data)),
-		map[string]string{
-			MetaFilename:  filename,
-			MetaCreatedAt: now,
-			MetaUpdatedAt: now,
-		},
-	)
+	metadata := map[string]string{
+		MetaFilename:  filename,
+		MetaCreatedAt: now,
+		MetaUpdatedAt: now,
+		MetaETag:      newETag(),
+	}
+	setOwner(ctx, metadata)
+
+	sums, size, err := s.putContent(ctx, fileID, contentType, body, expected, metadata)
 	if err != nil {
 		logrus.WithError(err).Errorf("%s: failed to put object", op)
-		return "", 0, fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+		return models.FileRecord{}, err
 	}
 
-	return fileID, uint64(len(data)), nil
+	rec := newRecord(fileID, metadata, contentType, size)
+	rec.Checksums = sums
+	s.indexPut(ctx, rec)
+
+	return rec, nil
 }
 
-func (s *FileService) Update(ctx context.Context, fileID string, data []byte) (string, uint64, error) {
+// Precondition - условие обновления файла; пустые поля не проверяются.
+type Precondition struct {
+	ETag    string
+	Version uint64
+}
+
+func (p Precondition) check(metadata map[string]string) error {
+	if p.ETag != "" && p.ETag != metadata[MetaETag] {
+		return apperrors.ErrPreconditionFailed
+	}
+	if p.Version != 0 && p.Version != fileVersion(metadata) {
+		return apperrors.ErrPreconditionFailed
+	}
+	return nil
+}
+
+// Update заменяет содержимое файла, сохраняя его имя и дату создания. Прежнее содержимое
+// сохраняется как предыдущая версия файла.
+// Непустые суммы в expected сверяются с новыми данными; при несовпадении файл не меняется.
+// Если файл уже не соответствует match, обновление отклоняется с ErrPreconditionFailed.
+func (s *FileService) Update(ctx context.Context, fileID string, r io.Reader, expected models.Checksums, match Precondition) (models.FileRecord, error) {
 	const op = "location internal/service/Update()"
 
-	metadata, _, _, err := s.storage.StatObject(ctx, s.bucket, fileID)
+	if isReservedKey(fileID) {
+		return models.FileRecord{}, apperrors.ErrFileNotFound
+	}
+
+	expected, err := normalizeChecksums(expected)
+	if err != nil {
+		return models.FileRecord{}, err
+	}
+
+	// проверка условия и запись должны быть атомарны относительно других обновлений
+	unlock := s.fileLocks.lock(fileID)
+	defer unlock()
+
+	info, err := s.storage.StatObject(ctx, s.bucket, fileID)
 	if err != nil {
 		logrus.WithError(err).Errorf("%s: failed to get file metadata", op)
-		if minioErr, ok := err.(minio.ErrorResponse); ok && minioErr.Code == "NoSuchKey" {
-			return "", 0, apperrors.ErrFileNotFound
+		if isNoSuchKey(err) {
+			return models.FileRecord{}, apperrors.ErrFileNotFound
 		}
-		return "", 0, fmt.Errorf("failed to get file metadata: %w", err)
+		return models.FileRecord{}, fmt.Errorf("failed to get file metadata: %w", err)
+	}
+	metadata := info.UserMetadata
+
+	if err := authorizeMetadata(ctx, metadata, accessWrite); err != nil {
+		return models.FileRecord{}, err
+	}
+	if err := match.check(metadata); err != nil {
+		return models.FileRecord{}, err
+	}
+
+	contentType, body, err := sniffContentType(r)
+	if err != nil {
+		logrus.WithError(err).Errorf("%s: failed to read file header", op)
+		return models.FileRecord{}, err
 	}
 
+	archived, err := s.archiveVersion(ctx, fileID, info)
+	if err != nil {
+		logrus.WithError(err).Errorf("%s: failed to archive current version", op)
+		return models.FileRecord{}, err
+	}
+
+	previous := maps.Clone(metadata)
 	now := time.Now().Format(time.RFC3339)
 	metadata[MetaUpdatedAt] = now
+	metadata[MetaVersion] = strconv.FormatUint(fileVersion(metadata)+1, 10)
+	metadata[MetaETag] = newETag()
+	// суммы и блоб относятся к старому содержимому
+	setChecksums(metadata, models.Checksums{})
+	delete(metadata, MetaBlob)
+	delete(metadata, MetaSize)
+
+	sums, size, err := s.putContent(ctx, fileID, contentType, body, expected, metadata)
+	if err != nil {
+		logrus.WithError(err).Errorf("%s: failed to update object", op)
+		s.removeVersion(ctx, archived)
+		return models.FileRecord{}, err
+	}
+	s.uncountObject(fileID, previous, info.Size)
+	s.releaseBlob(ctx, previous)
+	s.pruneVersions(ctx, fileID, time.Now())
 
-	contentType := http.DetectContentType(data)
+	rec := newRecord(fileID, metadata, contentType, size)
+	rec.Checksums = sums
+	s.indexPut(ctx, rec)
 
-	err = s.storage.PutObject(
+	return rec, nil
+}
+
+// putContent сохраняет содержимое файла под ключом fileID вместе с metadata и возвращает
+// контрольные суммы и размер. С включённой дедупликацией данные попадают в общий блоб,
+// а под fileID сохраняется указатель на него. metadata дополняется суммами.
+func (s *FileService) putContent(ctx context.Context, fileID, contentType string, body io.Reader, expected models.Checksums, metadata map[string]string) (models.Checksums, int64, error) {
+	if s.dedup {
+		sums, size, err := s.putDeduplicated(ctx, fileID, contentType, body, expected, metadata)
+		if err == nil {
+			s.countObject(fileID, metadata, 0)
+		}
+		return sums, size, err
+	}
+
+	// присланные клиентом суммы известны заранее и будут проверены при чтении
+	setChecksums(metadata, expected)
+
+	sums := s.newChecksumReader(body, expected)
+	err := s.storage.PutObject(
 		ctx,
 		s.bucket,
 		fileID,
 		contentType,
-		bytes.NewReader(data),
-		int64(len(data)),
+		sums,
+		-1,
 		metadata,
 	)
 	if err != nil {
-		logrus.WithError(err).Errorf("%s: failed to update object", op)
-		return "", 0, fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+		return models.Checksums{}, 0, storageError(err)
 	}
+	s.countObject(fileID, metadata, int64(sums.n))
+	s.storeChecksums(ctx, fileID, metadata, sums.sums())
 
-	return fileID, uint64(len(data)), nil
+	return sums.sums(), int64(sums.n), nil
 }
 
-func (s *FileService) DownloadLink(ctx context.Context, fileID string) (string, error) {
+// DownloadLink возвращает presigned-ссылку на файл и время, до которого она действует.
+func (s *FileService) DownloadLink(ctx context.Context, fileID string, opts LinkOptions) (string, time.Time, error) {
 	const op = "location internal/service/DownloadLink()"
 
-	_, _, _, err := s.storage.StatObject(ctx, s.bucket, fileID)
+	if isReservedKey(fileID) {
+		return "", time.Time{}, apperrors.ErrFileNotFound
+	}
+
+	rec, err := s.fileRecord(ctx, fileID)
 	if err != nil {
 		logrus.WithError(err).Errorf("%s: failed to get file metadata", op)
-		if minioErr, ok := err.(minio.ErrorResponse); ok && minioErr.Code == "NoSuchKey" {
-			return "", apperrors.ErrFileNotFound
-		}
-		return "", fmt.Errorf("failed to get file metadata: %w", err)
+		return "", time.Time{}, err
+	}
+	if err := authorize(ctx, rec, accessRead); err != nil {
+		return "", time.Time{}, err
 	}
 
-	url, err := s.storage.PresignedGetObject(ctx, s.bucket, fileID, time.Hour)
+	link, expiresAt, err := s.presign(ctx, dataKey(rec), rec.Filename, opts)
 	if err != nil {
 		logrus.WithError(err).Errorf("%s: failed to generate presigned URL", op)
-		return "", fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+		return "", time.Time{}, err
 	}
 
-	return url.String(), nil
+	return link, expiresAt, nil
 }
 
-func (s *FileService) ListFiles(ctx context.Context) ([]*pb.FileInfo, error) {
-	const op = "location internal/service/ListFiles()"
+// FileContent - открытый для чтения диапазон содержимого файла.
+type FileContent struct {
+	io.ReadCloser
+	File   *pb.FileInfo // метаданные всего файла
+	Offset int64
+	Length int64
+}
 
-	var files []*pb.FileInfo
+// DownloadFile открывает содержимое файла для передачи через сервис, начиная с offset.
+// length = 0 означает "до конца файла"; диапазон, выходящий за конец файла, обрезается.
+func (s *FileService) DownloadFile(ctx context.Context, fileID string, offset, length int64) (FileContent, error) {
+	const op = "location internal/service/DownloadFile()"
 
-	for obj := range s.storage.ListObjects(ctx, s.bucket) {
-		if obj.Err != nil {
-			logrus.WithError(obj.Err).Warnf("%s: skipping object due to error", op)
-			continue
-		}
+	if isReservedKey(fileID) {
+		return FileContent{}, apperrors.ErrFileNotFound
+	}
 
-		metadata, _, size, err := s.storage.StatObject(ctx, s.bucket, obj.Key)
-		if err != nil {
-			logrus.WithError(err).Warnf("%s: failed to stat object %s", op, obj.Key)
-			continue
-		}
+	rec, err := s.fileRecord(ctx, fileID)
+	if err != nil {
+		logrus.WithError(err).Errorf("%s: failed to get file metadata", op)
+		return FileContent{}, err
+	}
+	if err := authorize(ctx, rec, accessRead); err != nil {
+		return FileContent{}, err
+	}
 
-		createdAt, _ := time.Parse(time.RFC3339, metadata[MetaCreatedAt])
-		updatedAt, _ := time.Parse(time.RFC3339, metadata[MetaUpdatedAt])
+	if offset < 0 || length < 0 || offset > rec.Size {
+		return FileContent{}, apperrors.ErrInvalidRange
+	}
+	if length == 0 || length > rec.Size-offset {
+		length = rec.Size - offset
+	}
+	content := FileContent{
+		ReadCloser: io.NopCloser(strings.NewReader("")),
+		File:       toFileInfo(rec),
+		Offset:     offset,
+		Length:     length,
+	}
+	if length == 0 {
+		return content, nil
+	}
 
-		files = append(files, &pb.FileInfo{
-			FileId:    obj.Key,
-			Filename:  metadata[MetaFilename],
-			CreatedAt: timestamppb.New(createdAt),
-			UpdatedAt: timestamppb.New(updatedAt),
-			Size:      uint64(size),
-		})
+	content.ReadCloser, err = s.storage.GetObjectRange(ctx, s.bucket, dataKey(rec), offset, length)
+	if err != nil {
+		logrus.WithError(err).Errorf("%s: failed to get object", op)
+		if isNoSuchKey(err) {
+			return FileContent{}, apperrors.ErrFileNotFound
+		}
+		return FileContent{}, fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
 	}
 
-	return files, nil
+	return content, nil
 }
 
-func (s *FileService) DownloadZip(ctx context.Context, fileIDs []string) (io.ReadCloser, error) {
-	const op = "location internal/service/DownloadZip()"
+// ListFiles возвращает страницу доступных вызывающему файлов и токен следующей страницы
+// (пустой на последней).
+// В порядке по умолчанию (по file_id) листинг останавливается, как только страница заполнена;
+// для остальных сортировок подходящие файлы собираются целиком и сортируются.
+func (s *FileService) ListFiles(ctx context.Context, opts ListOptions) ([]*pb.FileInfo, string, error) {
+	last, err := decodePageToken(opts.PageToken, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	pageSize := opts.pageSize()
+
+	startAfter := ""
+	if opts.keyOrder() && last != nil {
+		startAfter = last.GetFileId()
+	}
 
-	buf := new(bytes.Buffer)
-	zipWriter := zip.NewWriter(buf)
+	var files []*pb.FileInfo
+	c := callerFrom(ctx)
 
-	for _, fileID := range fileIDs {
-		obj, err := s.storage.GetObject(ctx, s.bucket, fileID)
-		if err != nil {
-			logrus.WithError(err).Warnf("%s: failed to get object %s, skipping", op, fileID)
-			continue
+	err = s.listRecords(ctx, startAfter, func(rec models.FileRecord) bool {
+		if !canAccess(c, rec, accessRead) {
+			return true
 		}
-
-		metadata, _, _, err := s.storage.StatObject(ctx, s.bucket, fileID)
-		if err != nil {
-			obj.Close()
-			continue
+		file := toFileInfo(rec)
+		if !opts.matches(file) {
+			return true
 		}
+		files = append(files, file)
 
-		filename := metadata[MetaFilename]
-		if filename == "" || filename == " " {
-			filename = fileID
+		// +1 файл, чтобы понять, есть ли следующая страница
+		return !opts.keyOrder() || len(files) <= pageSize
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+	}
+
+	if !opts.keyOrder() {
+		sortFiles(files, opts)
+		if last != nil {
+			idx, _ := slices.BinarySearchFunc(files, last, func(f, target *pb.FileInfo) int {
+				if opts.Descending {
+					return compareFiles(target, f, opts.SortBy)
+				}
+				return compareFiles(f, target, opts.SortBy)
+			})
+			// пропускаем файлы до курсора включительно
+			for idx < len(files) && compareFiles(files[idx], last, opts.SortBy) == 0 {
+				idx++
+			}
+			files = files[idx:]
 		}
+	}
+
+	if len(files) <= pageSize {
+		return files, "", nil
+	}
+	files = files[:pageSize]
+	return files, encodePageToken(files[len(files)-1], opts), nil
+}
+
+// sniffContentType определяет MIME-тип по первым байтам потока, не вычитывая его целиком.
+// Возвращает reader, который отдаёт данные начиная с самого первого байта.
+func sniffContentType(r io.Reader) (string, io.Reader, error) {
+	br := bufio.NewReaderSize(r, sniffLen)
+	head, err := br.Peek(sniffLen)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return "", nil, err
+	}
+	return http.DetectContentType(head), br, nil
+}
+
+func newETag() string {
+	return strings.ReplaceAll(uuid.NewString(), "-", "")
+}
 
-		writer, err := zipWriter.Create(filename)
-		if err != nil {
-			logrus.WithError(err).Warnf("%s: failed to create zip entry for %s, skipping", op, fileID)
-			obj.Close()
-			continue
+// storageError оборачивает ошибку записи в хранилище; несовпадение сумм возвращается как есть.
+func storageError(err error) error {
+	if errors.Is(err, apperrors.ErrChecksumMismatch) {
+		return apperrors.ErrChecksumMismatch
+	}
+	return fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+}
+
+// isNoSuchKey проверяет, что хранилище ответило отсутствием объекта.
+func isNoSuchKey(err error) bool {
+	return errors.Is(err, apperrors.ErrObjectNotFound)
+}
+
+// isReservedKey - служебные ключи бакета (корзина, версии, блобы, незавершённые загрузки,
+// публичные ссылки, служебные записи), которые не являются файлами пользователя.
+func isReservedKey(key string) bool {
+	for _, prefix := range []string{trashPrefix, versionPrefix, blobPrefix, stagingPrefix, pendingPrefix, sharePrefix, metaPrefix} {
+		if strings.HasPrefix(key, prefix) {
+			return true
 		}
+	}
+	return false
+}
 
-		if _, err := io.Copy(writer, obj); err != nil {
-			logrus.WithError(err).Warnf("%s: error copying data for %s", op, fileID)
-			obj.Close()
-			continue
+// runEvery вызывает fn каждые interval, пока не отменён ctx.
+func runEvery(ctx context.Context, interval time.Duration, fn func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			fn()
 		}
-		obj.Close()
 	}
+}
 
-	if err := zipWriter.Close(); err != nil {
-		logrus.WithError(err).Errorf("%s: failed to close zip writer", op)
-		return nil, fmt.Errorf("failed to close zip writer: %w", err)
+// prepareFilename очищает имя файла, дополняет его расширением по MIME-типу и генерирует ключ объекта.
+func prepareFilename(filename, contentType string) (string, string) {
+	filename = sanitizeFilename(filename)
+	ext := getExtensionFromMIME(contentType)
+
+	// на случай, если клиент по какой-то причине не указал расширение файла в названии
+	originalExt := filepath.Ext(filename)
+	if originalExt == "" && ext != "" {
+		filename = fmt.Sprintf("%s%s", filename, ext)
 	}
 
-	return io.NopCloser(bytes.NewReader(buf.Bytes())), nil
+	return filename, generateFileID(filename, ext)
+}
+
+// sanitizeFilename оставляет от присланного клиентом имени только последний элемент пути
+// без ведущих точек, чтобы ключ объекта не попал под служебный префикс (".trash/", ".blobs/" и т.п.),
+// а имя - в архив как путь вне каталога распаковки.
+func sanitizeFilename(filename string) string {
+	filename = path.Base(strings.ReplaceAll(filename, "\\", "/"))
+	filename = strings.TrimLeft(filename, "./")
+	if filename == "" {
+		return "file"
+	}
+	return filename
 }
 
 func getExtensionFromMIME(contentType string) string {
@@ -0,0 +1,1047 @@
+package service
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/1abobik1/proto-upload-service/gen/go/upload_service/v1"
+	"github.com/1abobik1/upload_file_service/internal/apperrors"
+	"github.com/1abobik1/upload_file_service/internal/auth"
+	"github.com/1abobik1/upload_file_service/internal/models"
+	"github.com/1abobik1/upload_file_service/internal/storage"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testBucket = "test-bucket"
+
+func TestListFilesPagination(t *testing.T) {
+	svc := NewFileService(storage.NewMemoryStorage(), testBucket)
+	ctx := context.Background()
+
+	for i := range 5 {
+		_, err := svc.Upload(ctx, fmt.Sprintf("file%d.txt", i), strings.NewReader(strings.Repeat("x", i+1)), models.Checksums{})
+		require.NoError(t, err)
+	}
+
+	listAll := func(opts ListOptions) []string {
+		var names []string
+		for {
+			files, next, err := svc.ListFiles(ctx, opts)
+			require.NoError(t, err)
+			require.LessOrEqual(t, len(files), opts.PageSize)
+			for _, f := range files {
+				names = append(names, f.GetFilename())
+			}
+			if next == "" {
+				return names
+			}
+			opts.PageToken = next
+		}
+	}
+
+	require.Equal(t,
+		[]string{"file0.txt", "file1.txt", "file2.txt", "file3.txt", "file4.txt"},
+		listAll(ListOptions{PageSize: 2}),
+	)
+	require.Equal(t,
+		[]string{"file4.txt", "file3.txt", "file2.txt", "file1.txt", "file0.txt"},
+		listAll(ListOptions{PageSize: 2, SortBy: pb.ListSortField_LIST_SORT_FIELD_SIZE, Descending: true}),
+	)
+	require.Equal(t,
+		[]string{"file2.txt", "file3.txt"},
+		listAll(ListOptions{PageSize: 1, MinSize: 3, MaxSize: 4}),
+	)
+
+	// токен привязан к сортировке, с которой он выдан
+	_, next, err := svc.ListFiles(ctx, ListOptions{PageSize: 2})
+	require.NoError(t, err)
+	_, _, err = svc.ListFiles(ctx, ListOptions{PageSize: 2, PageToken: next, Descending: true})
+	require.ErrorIs(t, err, apperrors.ErrInvalidPageToken)
+
+	// клиенты без page_size получают все файлы одной страницей
+	for i := range 200 {
+		_, err := svc.Upload(ctx, fmt.Sprintf("more%d.txt", i), strings.NewReader("x"), models.Checksums{})
+		require.NoError(t, err)
+	}
+	files, next, err := svc.ListFiles(ctx, ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, files, 205)
+	require.Empty(t, next)
+}
+
+func TestUploadReservedFilename(t *testing.T) {
+	svc := NewFileService(storage.NewMemoryStorage(), testBucket)
+	ctx := context.Background()
+
+	var ids []string
+	for _, name := range []string{".trash/x.txt", ".versions/abc/1.txt", "../../.shares/token", ".blobs\\sum", "..", ""} {
+		rec, err := svc.Upload(ctx, name, strings.NewReader("data"), models.Checksums{})
+		require.NoError(t, err)
+		require.False(t, isReservedKey(rec.FileID), rec.FileID)
+		require.NotContains(t, rec.Filename, "/")
+		require.NotContains(t, rec.Filename, "\\")
+		require.False(t, strings.HasPrefix(rec.Filename, "."), rec.Filename)
+		ids = append(ids, rec.FileID)
+	}
+
+	files, _, err := svc.ListFiles(ctx, ListOptions{})
+	require.NoError(t, err)
+	listed := make([]string, 0, len(files))
+	for _, f := range files {
+		listed = append(listed, f.GetFileId())
+	}
+	require.ElementsMatch(t, ids, listed)
+}
+
+func TestDeleteAndRestoreFile(t *testing.T) {
+	svc := NewFileService(storage.NewMemoryStorage(), testBucket)
+	ctx := context.Background()
+
+	rec, err := svc.Upload(ctx, "notes.txt", strings.NewReader("hello"), models.Checksums{})
+	require.NoError(t, err)
+	fileID := rec.FileID
+
+	_, err = svc.DeleteFile(ctx, fileID, false)
+	require.NoError(t, err)
+
+	files, _, err := svc.ListFiles(ctx, ListOptions{})
+	require.NoError(t, err)
+	require.Empty(t, files)
+
+	trash, err := svc.ListTrash(ctx)
+	require.NoError(t, err)
+	require.Len(t, trash, 1)
+	require.Equal(t, fileID, trash[0].GetFile().GetFileId())
+
+	restored, err := svc.RestoreFile(ctx, fileID)
+	require.NoError(t, err)
+	require.Equal(t, "notes.txt", restored.GetFilename())
+	require.Equal(t, "text/plain; charset=utf-8", restored.GetContentType())
+
+	_, err = svc.RestoreFile(ctx, fileID)
+	require.ErrorIs(t, err, apperrors.ErrFileNotFound)
+
+	_, err = svc.DeleteFile(ctx, fileID, true)
+	require.NoError(t, err)
+	trash, err = svc.ListTrash(ctx)
+	require.NoError(t, err)
+	require.Empty(t, trash)
+}
+
+func TestConcurrentDeleteAndRestore(t *testing.T) {
+	// медленное копирование расширяет окно между переносом файла и удалением исходного объекта
+	svc := NewFileService(slowCopyStorage{MinIOStorageI: storage.NewMemoryStorage(), delay: 10 * time.Millisecond}, testBucket)
+	ctx := context.Background()
+
+	rec, err := svc.Upload(ctx, "notes.txt", strings.NewReader("hello"), models.Checksums{})
+	require.NoError(t, err)
+
+	// run выполняет op параллельно и возвращает число успешных вызовов
+	run := func(op func() error) int {
+		const calls = 10
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		succeeded := 0
+		for range calls {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if op() == nil {
+					mu.Lock()
+					succeeded++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+		return succeeded
+	}
+
+	// файл переносится в корзину и возвращается из неё ровно один раз
+	require.Equal(t, 1, run(func() error {
+		_, err := svc.DeleteFile(ctx, rec.FileID, false)
+		return err
+	}))
+	require.Equal(t, 1, run(func() error {
+		_, err := svc.RestoreFile(ctx, rec.FileID)
+		return err
+	}))
+
+	trash, err := svc.ListTrash(ctx)
+	require.NoError(t, err)
+	require.Empty(t, trash)
+	content, err := svc.DownloadFile(ctx, rec.FileID, 0, 0)
+	require.NoError(t, err)
+	content.Close()
+}
+
+// slowCopyStorage задерживает ответ CopyObject на delay после копирования.
+type slowCopyStorage struct {
+	MinIOStorageI
+	delay time.Duration
+}
+
+func (s slowCopyStorage) CopyObject(ctx context.Context, bucket, srcObject, dstObject string, metadata map[string]string) error {
+	err := s.MinIOStorageI.CopyObject(ctx, bucket, srcObject, dstObject, metadata)
+	time.Sleep(s.delay)
+	return err
+}
+
+func TestUploadSessionResume(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	svc := NewFileService(store, testBucket)
+	ctx := context.Background()
+
+	data := bytes.Repeat([]byte("0123456789"), (minUploadPartSize*2+100)/10)
+
+	sess, err := svc.CreateUploadSession(ctx, "big.bin")
+	require.NoError(t, err)
+	require.EqualValues(t, defaultUploadPartSize, sess.PartSize)
+
+	// стрим обрывается посреди второй части: сохраняется только первая
+	broken := io.MultiReader(bytes.NewReader(data[:defaultUploadPartSize+10]), errReader{errors.New("connection reset")})
+	state, err := svc.AppendUploadSession(ctx, sess.ID, 0, broken)
+	require.Error(t, err)
+	require.EqualValues(t, defaultUploadPartSize, state.CommittedSize)
+
+	_, err = svc.AppendUploadSession(ctx, sess.ID, 0, bytes.NewReader(data))
+	require.ErrorIs(t, err, apperrors.ErrUploadOffsetMismatch)
+
+	state, err = svc.AppendUploadSession(ctx, sess.ID, state.CommittedSize, bytes.NewReader(data[state.CommittedSize:]))
+	require.NoError(t, err)
+	require.True(t, state.Sealed)
+
+	rec, err := svc.FinalizeUploadSession(ctx, sess.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, len(data), rec.Size)
+	sum := sha256.Sum256(data)
+	require.Equal(t, hex.EncodeToString(sum[:]), rec.Checksums.SHA256)
+
+	obj, err := store.GetObject(ctx, testBucket, rec.FileID)
+	require.NoError(t, err)
+	defer obj.Close()
+	got, err := io.ReadAll(obj)
+	require.NoError(t, err)
+	require.Equal(t, data, got)
+
+	_, err = svc.UploadSessionStatus(ctx, sess.ID)
+	require.ErrorIs(t, err, apperrors.ErrUploadSessionNotFound)
+}
+
+func TestUploadSessionAcrossInstances(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	ctx := context.Background()
+
+	data := bytes.Repeat([]byte("0123456789"), (minUploadPartSize+100)/10)
+
+	svc := NewFileService(store, testBucket, WithUploadSessionTTL(time.Hour), WithUploadPartSize(minUploadPartSize))
+	sess, err := svc.CreateUploadSession(ctx, "big.bin")
+	require.NoError(t, err)
+	_, err = svc.AppendUploadSession(ctx, sess.ID, 0, io.MultiReader(bytes.NewReader(data[:minUploadPartSize+10]), errReader{errors.New("connection reset")}))
+	require.Error(t, err)
+
+	// уборка экземпляра с коротким TTL не трогает части сессии, пока не истёк срок самой сессии
+	janitor := NewFileService(store, testBucket, WithUploadSessionTTL(time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+	require.Zero(t, janitor.CleanupExpiredUploadSessions(ctx))
+
+	// сессия хранится в бакете, поэтому её продолжает перезапущенный экземпляр
+	restarted := NewFileService(store, testBucket, WithUploadSessionTTL(time.Hour), WithUploadPartSize(minUploadPartSize))
+	state, err := restarted.UploadSessionStatus(ctx, sess.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, minUploadPartSize, state.CommittedSize)
+
+	// пока один экземпляр пишет в сессию, второй получает ErrUploadSessionBusy
+	_, writer, err := svc.lockSession(ctx, sess.ID, "")
+	require.NoError(t, err)
+	_, err = restarted.AppendUploadSession(ctx, sess.ID, state.CommittedSize, bytes.NewReader(data[state.CommittedSize:]))
+	require.ErrorIs(t, err, apperrors.ErrUploadSessionBusy)
+	svc.unlockSession(ctx, sess.ID, writer)
+
+	state, err = restarted.AppendUploadSession(ctx, sess.ID, state.CommittedSize, bytes.NewReader(data[state.CommittedSize:]))
+	require.NoError(t, err)
+	require.True(t, state.Sealed)
+
+	rec, err := svc.FinalizeUploadSession(ctx, sess.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, len(data), rec.Size)
+	sum := sha256.Sum256(data)
+	require.Equal(t, hex.EncodeToString(sum[:]), rec.Checksums.SHA256)
+	require.Empty(t, objectKeys(store, sessionPrefix))
+}
+
+func TestUploadSessionCleanup(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	ctx := context.Background()
+
+	svc := NewFileService(store, testBucket, WithUploadSessionTTL(time.Millisecond))
+	expired, err := svc.CreateUploadSession(ctx, "lost.txt")
+	require.NoError(t, err)
+	_, err = svc.AppendUploadSession(ctx, expired.ID, 0, strings.NewReader("partial"))
+	require.NoError(t, err)
+
+	// загрузка без записи сессии осталась от прежней версии сервиса
+	_, err = store.NewMultipartUpload(ctx, testBucket, "orphan.txt", "text/plain", nil)
+	require.NoError(t, err)
+
+	live := NewFileService(store, testBucket, WithUploadSessionTTL(time.Hour))
+	sess, err := live.CreateUploadSession(ctx, "live.txt")
+	require.NoError(t, err)
+	_, err = live.AppendUploadSession(ctx, sess.ID, 0, strings.NewReader("live"))
+	require.NoError(t, err)
+
+	time.Sleep(5 * time.Millisecond)
+	require.Equal(t, 2, svc.CleanupExpiredUploadSessions(ctx))
+
+	uploads, err := store.ListMultipartUploads(ctx, testBucket)
+	require.NoError(t, err)
+	require.Len(t, uploads, 1)
+	_, err = svc.UploadSessionStatus(ctx, expired.ID)
+	require.ErrorIs(t, err, apperrors.ErrUploadSessionNotFound)
+	require.Equal(t, []string{sessionKey(sess.ID)}, objectKeys(store, sessionPrefix))
+}
+
+func TestDirectUpload(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	svc := NewFileService(store, testBucket, WithDirectUpload(time.Hour, 1<<20))
+	ctx := context.Background()
+
+	_, err := svc.CreateDirectUpload(ctx, "huge.bin", "", 2<<20)
+	require.ErrorIs(t, err, apperrors.ErrFileTooLarge)
+
+	upload, err := svc.CreateDirectUpload(ctx, "report", "application/pdf", 4)
+	require.NoError(t, err)
+	require.Equal(t, "application/pdf", upload.FormFields["Content-Type"])
+	key := upload.FormFields["key"]
+	require.Equal(t, pendingPrefix+upload.FileID, key)
+
+	_, err = svc.CompleteDirectUpload(ctx, upload.FileID)
+	require.ErrorIs(t, err, apperrors.ErrDirectUploadIncomplete)
+
+	// объект не того размера удаляется, форму можно использовать ещё раз
+	metadata := postedMetadata(upload.FormFields)
+	require.NoError(t, store.PutObject(ctx, testBucket, key, "application/pdf", strings.NewReader("%PDF-1"), -1, metadata))
+	_, err = svc.CompleteDirectUpload(ctx, upload.FileID)
+	require.ErrorIs(t, err, apperrors.ErrDirectUploadMismatch)
+	_, err = store.StatObject(ctx, testBucket, key)
+	require.ErrorIs(t, err, apperrors.ErrObjectNotFound)
+
+	// клиент загружает файл в хранилище сам; до завершения файла нет в списке
+	require.NoError(t, store.PutObject(ctx, testBucket, key, "application/pdf", strings.NewReader("%PDF"), -1, metadata))
+	files, _, err := svc.ListFiles(ctx, ListOptions{PageSize: 10})
+	require.NoError(t, err)
+	require.Empty(t, files)
+
+	// форма хранится в метаданных объекта: загрузку завершает и перезапущенный сервис,
+	// а уборка не удаляет объект до истечения срока формы
+	svc = NewFileService(store, testBucket, WithDirectUpload(time.Hour, 1<<20))
+	require.Zero(t, svc.CleanupExpiredDirectUploads(ctx))
+	rec, err := svc.CompleteDirectUpload(ctx, upload.FileID)
+	require.NoError(t, err)
+	require.Equal(t, "report.pdf", rec.Filename)
+	require.EqualValues(t, 4, rec.Size)
+	sum := sha256.Sum256([]byte("%PDF"))
+	require.Equal(t, hex.EncodeToString(sum[:]), rec.Checksums.SHA256)
+
+	info, err := store.StatObject(ctx, testBucket, upload.FileID)
+	require.NoError(t, err)
+	require.Equal(t, rec.Checksums.SHA256, info.UserMetadata[MetaSHA256])
+	require.NotContains(t, info.UserMetadata, MetaExpiresAt)
+
+	files, _, err = svc.ListFiles(ctx, ListOptions{PageSize: 10})
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.Equal(t, upload.FileID, files[0].GetFileId())
+	require.Equal(t, "report.pdf", files[0].GetFilename())
+	require.Equal(t, "application/pdf", files[0].GetContentType())
+
+	_, err = store.StatObject(ctx, testBucket, key)
+	require.ErrorIs(t, err, apperrors.ErrObjectNotFound)
+	_, err = svc.CompleteDirectUpload(ctx, upload.FileID)
+	require.ErrorIs(t, err, apperrors.ErrDirectUploadNotFound)
+}
+
+func TestDirectUploadDeduplication(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	svc := NewFileService(store, testBucket, WithDedup(true))
+	ctx := context.Background()
+
+	data := []byte("uploaded twice")
+	first, err := svc.Upload(ctx, "a.txt", bytes.NewReader(data), models.Checksums{})
+	require.NoError(t, err)
+
+	upload, err := svc.CreateDirectUpload(ctx, "b.txt", "", 0)
+	require.NoError(t, err)
+	require.NoError(t, store.PutObject(ctx, testBucket, upload.FormFields["key"], "text/plain", bytes.NewReader(data), -1, postedMetadata(upload.FormFields)))
+
+	rec, err := svc.CompleteDirectUpload(ctx, upload.FileID)
+	require.NoError(t, err)
+	require.Equal(t, first.Blob, rec.Blob)
+	require.Equal(t, first.Checksums.SHA256, rec.Checksums.SHA256)
+	require.Equal(t, 2, blobRefCount(t, svc, first.Checksums.SHA256))
+	require.Len(t, objectKeys(store, blobPrefix), 1)
+	require.Empty(t, objectKeys(store, pendingPrefix))
+	require.EqualValues(t, len(data), rec.Size)
+}
+
+// postedMetadata - метаданные, которые хранилище сохраняет из полей формы прямой загрузки.
+func postedMetadata(fields map[string]string) map[string]string {
+	metadata := make(map[string]string)
+	for name, value := range fields {
+		if key, ok := strings.CutPrefix(name, "x-amz-meta-"); ok {
+			metadata[key] = value
+		}
+	}
+	return metadata
+}
+
+func TestUploadChecksums(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	svc := NewFileService(store, testBucket, WithCRC32C(true))
+	ctx := context.Background()
+
+	data := []byte("checksummed content")
+	sum := sha256.Sum256(data)
+	sha := hex.EncodeToString(sum[:])
+
+	rec, err := svc.Upload(ctx, "a.txt", bytes.NewReader(data), models.Checksums{})
+	require.NoError(t, err)
+	require.Equal(t, sha, rec.Checksums.SHA256)
+	require.Len(t, rec.Checksums.CRC32C, 8)
+
+	// суммы сохраняются в метаданных объекта и видны в листинге
+	info, err := store.StatObject(ctx, testBucket, rec.FileID)
+	require.NoError(t, err)
+	require.Equal(t, sha, info.UserMetadata[MetaSHA256])
+	files, _, err := svc.ListFiles(ctx, ListOptions{})
+	require.NoError(t, err)
+	require.Equal(t, sha, files[0].GetChecksums().GetSha256())
+
+	_, err = svc.Upload(ctx, "b.txt", bytes.NewReader(data), models.Checksums{SHA256: strings.ToUpper(sha)})
+	require.NoError(t, err)
+
+	_, err = svc.Upload(ctx, "c.txt", bytes.NewReader(data), models.Checksums{SHA256: "abc"})
+	require.ErrorIs(t, err, apperrors.ErrInvalidChecksum)
+
+	wrong := sha256.Sum256([]byte("other"))
+	_, err = svc.Update(ctx, rec.FileID, strings.NewReader("other data"), models.Checksums{SHA256: hex.EncodeToString(wrong[:])}, Precondition{})
+	require.ErrorIs(t, err, apperrors.ErrChecksumMismatch)
+
+	// при несовпадении содержимое файла не меняется
+	obj, err := store.GetObject(ctx, testBucket, rec.FileID)
+	require.NoError(t, err)
+	defer obj.Close()
+	got, err := io.ReadAll(obj)
+	require.NoError(t, err)
+	require.Equal(t, data, got)
+
+	files, _, err = svc.ListFiles(ctx, ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+}
+
+func TestUploadDeduplication(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	svc := NewFileService(store, testBucket, WithDedup(true))
+	ctx := context.Background()
+
+	data := []byte("same content in every file")
+	sum := sha256.Sum256(data)
+	sha := hex.EncodeToString(sum[:])
+
+	first, err := svc.Upload(ctx, "a.txt", bytes.NewReader(data), models.Checksums{})
+	require.NoError(t, err)
+	// с известной суммой существующий блоб переиспользуется без повторной записи
+	second, err := svc.Upload(ctx, "b.txt", bytes.NewReader(data), models.Checksums{SHA256: sha})
+	require.NoError(t, err)
+	require.NotEmpty(t, first.Blob)
+	require.Equal(t, first.Blob, second.Blob)
+	require.EqualValues(t, len(data), second.Size)
+	require.Equal(t, 2, blobRefCount(t, svc, sha))
+	require.Len(t, objectKeys(store, blobPrefix), 1)
+
+	stats, err := svc.Stats(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, stats.Files)
+	require.EqualValues(t, 1, stats.Blobs)
+	require.EqualValues(t, 2*len(data), stats.LogicalBytes)
+	require.EqualValues(t, len(data), stats.StoredBytes)
+	require.Equal(t, 2.0, stats.DedupRatio())
+
+	files, _, err := svc.ListFiles(ctx, ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	// удаление одного файла не затрагивает содержимое другого
+	_, err = svc.DeleteFile(ctx, first.FileID, true)
+	require.NoError(t, err)
+	rec, err := svc.Update(ctx, second.FileID, strings.NewReader("new content"), models.Checksums{}, Precondition{})
+	require.NoError(t, err)
+
+	obj, err := store.GetObject(ctx, testBucket, dataKey(rec))
+	require.NoError(t, err)
+	defer obj.Close()
+	got, err := io.ReadAll(obj)
+	require.NoError(t, err)
+	require.Equal(t, "new content", string(got))
+
+	// на прежнее содержимое ссылается только сохранённая версия
+	require.Equal(t, 1, blobRefCount(t, svc, sha))
+
+	// статистика обновляется без обхода бакета, записанные изменения видны другому экземпляру
+	stats, err = svc.Stats(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, stats.Files)
+	require.EqualValues(t, 2, stats.Blobs)
+	require.EqualValues(t, len(data)+len("new content"), stats.LogicalBytes)
+	require.EqualValues(t, len(data)+len("new content"), stats.StoredBytes)
+	other, err := NewFileService(store, testBucket).Stats(ctx)
+	require.NoError(t, err)
+	require.Equal(t, stats, other)
+
+	_, err = svc.DeleteFile(ctx, second.FileID, true)
+	require.NoError(t, err)
+	_, err = store.StatObject(ctx, testBucket, blobKey(first.Blob))
+	require.ErrorIs(t, err, apperrors.ErrObjectNotFound)
+	require.Empty(t, objectKeys(store, blobPrefix))
+
+	stats, err = svc.Stats(ctx)
+	require.NoError(t, err)
+	require.Equal(t, Stats{}, stats)
+}
+
+func TestDeduplicationAcrossInstances(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	// экземпляры сервиса с общим хранилищем не делят память, счётчик ссылок согласуется через хранилище
+	instances := []*FileService{
+		NewFileService(store, testBucket, WithDedup(true)),
+		NewFileService(store, testBucket, WithDedup(true)),
+	}
+	ctx := context.Background()
+
+	data := []byte("content uploaded by every replica at once")
+	sum := sha256.Sum256(data)
+	sha := hex.EncodeToString(sum[:])
+
+	const uploads = 20
+	var wg sync.WaitGroup
+	recs := make([]models.FileRecord, uploads)
+	for i := range uploads {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rec, err := instances[i%2].Upload(ctx, "same.txt", bytes.NewReader(data), models.Checksums{})
+			assert.NoError(t, err)
+			recs[i] = rec
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, uploads, blobRefCount(t, instances[0], sha))
+	require.Len(t, objectKeys(store, blobPrefix), 1)
+
+	for i, rec := range recs {
+		_, err := instances[i%2].DeleteFile(ctx, rec.FileID, true)
+		require.NoError(t, err)
+	}
+	require.Empty(t, objectKeys(store, blobPrefix))
+}
+
+func blobRefCount(t *testing.T, svc *FileService, sha string) int {
+	t.Helper()
+
+	var refs blobRefs
+	require.NoError(t, svc.readMeta(context.Background(), refsKey(sha), &refs))
+	return refs.Refs
+}
+
+func objectKeys(store MinIOStorageI, prefix string) []string {
+	var keys []string
+	for obj := range store.ListObjects(context.Background(), testBucket, prefix, "") {
+		keys = append(keys, obj.Key)
+	}
+	return keys
+}
+
+func TestFileVersions(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	svc := NewFileService(store, testBucket, WithVersionRetention(2, 0))
+	ctx := context.Background()
+
+	rec, err := svc.Upload(ctx, "doc.txt", strings.NewReader("v1"), models.Checksums{})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, rec.Version)
+
+	for _, content := range []string{"v2", "v3", "v4"} {
+		rec, err = svc.Update(ctx, rec.FileID, strings.NewReader(content), models.Checksums{}, Precondition{})
+		require.NoError(t, err)
+	}
+	require.EqualValues(t, 4, rec.Version)
+
+	// хранятся только две прежние версии
+	versions, err := svc.ListVersions(ctx, rec.FileID)
+	require.NoError(t, err)
+	require.Len(t, versions, 3)
+	require.True(t, versions[0].GetCurrent())
+	require.EqualValues(t, []uint64{4, 3, 2}, []uint64{versions[0].GetVersion(), versions[1].GetVersion(), versions[2].GetVersion()})
+
+	_, _, err = svc.VersionDownloadLink(ctx, rec.FileID, 1, LinkOptions{})
+	require.ErrorIs(t, err, apperrors.ErrVersionNotFound)
+
+	file, err := svc.Rollback(ctx, rec.FileID, 2)
+	require.NoError(t, err)
+	require.EqualValues(t, 5, file.GetVersion())
+	require.Equal(t, "doc.txt", file.GetFilename())
+
+	obj, err := store.GetObject(ctx, testBucket, rec.FileID)
+	require.NoError(t, err)
+	defer obj.Close()
+	got, err := io.ReadAll(obj)
+	require.NoError(t, err)
+	require.Equal(t, "v2", string(got))
+
+	// содержимое до отката тоже стало версией
+	versions, err = svc.ListVersions(ctx, rec.FileID)
+	require.NoError(t, err)
+	require.EqualValues(t, 4, versions[1].GetVersion())
+
+	_, err = svc.DeleteFile(ctx, rec.FileID, true)
+	require.NoError(t, err)
+	for obj := range store.ListObjects(ctx, testBucket, versionPrefix, "") {
+		t.Fatalf("version %s was not removed", obj.Key)
+	}
+}
+
+func TestUpdatePrecondition(t *testing.T) {
+	svc := NewFileService(storage.NewMemoryStorage(), testBucket)
+	ctx := context.Background()
+
+	rec, err := svc.Upload(ctx, "shared.txt", strings.NewReader("base"), models.Checksums{})
+	require.NoError(t, err)
+	require.NotEmpty(t, rec.ETag)
+
+	// оба клиента прочитали одну и ту же версию, второй должен получить отказ
+	first, err := svc.Update(ctx, rec.FileID, strings.NewReader("first"), models.Checksums{}, Precondition{ETag: rec.ETag})
+	require.NoError(t, err)
+	require.NotEqual(t, rec.ETag, first.ETag)
+
+	_, err = svc.Update(ctx, rec.FileID, strings.NewReader("second"), models.Checksums{}, Precondition{ETag: rec.ETag})
+	require.ErrorIs(t, err, apperrors.ErrPreconditionFailed)
+	_, err = svc.Update(ctx, rec.FileID, strings.NewReader("second"), models.Checksums{}, Precondition{Version: rec.Version})
+	require.ErrorIs(t, err, apperrors.ErrPreconditionFailed)
+
+	second, err := svc.Update(ctx, rec.FileID, strings.NewReader("second"), models.Checksums{}, Precondition{ETag: first.ETag, Version: first.Version})
+	require.NoError(t, err)
+	require.EqualValues(t, 3, second.Version)
+
+	files, _, err := svc.ListFiles(ctx, ListOptions{})
+	require.NoError(t, err)
+	require.Equal(t, second.ETag, files[0].GetEtag())
+}
+
+func TestDownloadZipStreaming(t *testing.T) {
+	svc := NewFileService(storage.NewMemoryStorage(), testBucket)
+	ctx := context.Background()
+
+	first, err := svc.Upload(ctx, "a.txt", strings.NewReader("first file"), models.Checksums{})
+	require.NoError(t, err)
+	second, err := svc.Upload(ctx, "b.txt", strings.NewReader("second file"), models.Checksums{})
+	require.NoError(t, err)
+
+	archive, err := svc.DownloadArchive(ctx, []string{first.FileID, second.FileID}, ArchiveOptions{})
+	require.NoError(t, err)
+	data, err := io.ReadAll(archive)
+	require.NoError(t, err)
+	require.NoError(t, archive.Close())
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	require.Len(t, zr.File, 2)
+	require.Equal(t, "a.txt", zr.File[0].Name)
+	require.Equal(t, first.UpdatedAt.Unix(), zr.File[0].Modified.Unix())
+
+	// после отмены контекста архив перестаёт формироваться
+	cancelCtx, cancel := context.WithCancel(ctx)
+	archive, err = svc.DownloadArchive(cancelCtx, []string{first.FileID}, ArchiveOptions{})
+	require.NoError(t, err)
+	defer archive.Close()
+	cancel()
+	_, err = io.ReadAll(archive)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestDownloadZipSkippedAndDuplicateNames(t *testing.T) {
+	svc := NewFileService(storage.NewMemoryStorage(), testBucket)
+	ctx := context.Background()
+
+	first, err := svc.Upload(ctx, "photo.jpg", strings.NewReader("one"), models.Checksums{})
+	require.NoError(t, err)
+	second, err := svc.Upload(ctx, "photo.jpg", strings.NewReader("two"), models.Checksums{})
+	require.NoError(t, err)
+	ids := []string{first.FileID, "missing-id", second.FileID}
+
+	_, err = svc.DownloadArchive(ctx, ids, ArchiveOptions{Strict: true})
+	require.ErrorIs(t, err, apperrors.ErrFileNotFound)
+
+	archive, err := svc.DownloadArchive(ctx, ids, ArchiveOptions{})
+	require.NoError(t, err)
+	data, err := io.ReadAll(archive)
+	require.NoError(t, err)
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	require.Equal(t, []string{"photo.jpg", "photo (2).jpg", manifestName}, names)
+
+	rc, err := zr.File[2].Open()
+	require.NoError(t, err)
+	defer rc.Close()
+	var manifest archiveManifest
+	require.NoError(t, json.NewDecoder(rc).Decode(&manifest))
+	require.Len(t, manifest.Files, 2)
+	require.Equal(t, []skippedFile{{FileID: "missing-id", Reason: "not found"}}, manifest.Skipped)
+}
+
+func TestDownloadArchiveSanitizesNames(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	svc := NewFileService(store, testBucket)
+	ctx := context.Background()
+
+	// имена из метаданных могли попасть в хранилище в обход Upload
+	var ids []string
+	for i, filename := range []string{"../../etc/passwd", "/abs/report.txt", `..\..\win.ini`, ".."} {
+		id := fmt.Sprintf("file-%d", i)
+		err := store.PutObject(ctx, testBucket, id, "text/plain", strings.NewReader("data"), -1, map[string]string{MetaFilename: filename})
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+	want := []string{"passwd", "report.txt", "win.ini", "file"}
+
+	archive, err := svc.DownloadArchive(ctx, ids, ArchiveOptions{})
+	require.NoError(t, err)
+	data, err := io.ReadAll(archive)
+	require.NoError(t, err)
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	require.Equal(t, want, names)
+
+	archive, err = svc.DownloadArchive(ctx, ids, ArchiveOptions{Format: pb.ArchiveFormat_ARCHIVE_FORMAT_TAR})
+	require.NoError(t, err)
+	tr := tar.NewReader(archive)
+	names = nil
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		names = append(names, hdr.Name)
+	}
+	require.Equal(t, want, names)
+}
+
+func TestDownloadArchiveTar(t *testing.T) {
+	svc := NewFileService(storage.NewMemoryStorage(), testBucket)
+	ctx := context.Background()
+
+	rec, err := svc.Upload(ctx, "report.csv", strings.NewReader("a,b\n1,2\n"), models.Checksums{})
+	require.NoError(t, err)
+
+	for format, decompress := range map[pb.ArchiveFormat]func(io.Reader) (io.Reader, error){
+		pb.ArchiveFormat_ARCHIVE_FORMAT_TAR: func(r io.Reader) (io.Reader, error) { return r, nil },
+		pb.ArchiveFormat_ARCHIVE_FORMAT_TAR_GZ: func(r io.Reader) (io.Reader, error) {
+			return gzip.NewReader(r)
+		},
+		pb.ArchiveFormat_ARCHIVE_FORMAT_TAR_ZST: func(r io.Reader) (io.Reader, error) {
+			return zstd.NewReader(r)
+		},
+	} {
+		t.Run(format.String(), func(t *testing.T) {
+			archive, err := svc.DownloadArchive(ctx, []string{rec.FileID}, ArchiveOptions{Format: format})
+			require.NoError(t, err)
+			defer archive.Close()
+
+			r, err := decompress(archive)
+			require.NoError(t, err)
+			tr := tar.NewReader(r)
+
+			header, err := tr.Next()
+			require.NoError(t, err)
+			require.Equal(t, "report.csv", header.Name)
+			require.EqualValues(t, rec.Size, header.Size)
+			require.Equal(t, rec.UpdatedAt.Unix(), header.ModTime.Unix())
+			data, err := io.ReadAll(tr)
+			require.NoError(t, err)
+			require.Equal(t, "a,b\n1,2\n", string(data))
+
+			_, err = tr.Next()
+			require.ErrorIs(t, err, io.EOF)
+		})
+	}
+
+	_, err = svc.DownloadArchive(ctx, []string{rec.FileID}, ArchiveOptions{Format: pb.ArchiveFormat(42)})
+	require.ErrorIs(t, err, apperrors.ErrInvalidArchiveFormat)
+}
+
+func TestDownloadArchiveChangedFile(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	svc := NewFileService(store, testBucket)
+	ctx := context.Background()
+
+	rec, err := svc.Upload(ctx, "report.csv", strings.NewReader("a,b\n"), models.Checksums{})
+	require.NoError(t, err)
+	other, err := svc.Upload(ctx, "other.txt", strings.NewReader("other"), models.Checksums{})
+	require.NoError(t, err)
+
+	readTar := func(svc *FileService, plan *archivePlan) map[string]string {
+		var buf bytes.Buffer
+		require.NoError(t, svc.writeArchive(ctx, &buf, plan))
+		files := make(map[string]string)
+		tr := tar.NewReader(&buf)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				return files
+			}
+			require.NoError(t, err)
+			data, err := io.ReadAll(tr)
+			require.NoError(t, err)
+			require.EqualValues(t, hdr.Size, len(data))
+			files[hdr.Name] = string(data)
+		}
+	}
+	opts := ArchiveOptions{Format: pb.ArchiveFormat_ARCHIVE_FORMAT_TAR}
+
+	// файл обновили после планирования: размер в заголовке берётся у объекта
+	plan, err := svc.planArchive(ctx, []string{rec.FileID, other.FileID}, opts)
+	require.NoError(t, err)
+	_, err = svc.Update(ctx, rec.FileID, strings.NewReader("a,b\n1,2\n"), models.Checksums{}, Precondition{})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"report.csv": "a,b\n1,2\n", "other.txt": "other"}, readTar(svc, plan))
+
+	// файл перезаписали во время передачи: архив остаётся целым, файл попадает в манифест
+	truncated := NewFileService(truncatingStorage{MinIOStorageI: store, key: rec.FileID}, testBucket)
+	plan, err = truncated.planArchive(ctx, []string{rec.FileID, other.FileID}, opts)
+	require.NoError(t, err)
+	files := readTar(truncated, plan)
+	require.Equal(t, "other", files["other.txt"])
+	var manifest archiveManifest
+	require.NoError(t, json.Unmarshal([]byte(files[manifestName]), &manifest))
+	require.Equal(t, []skippedFile{{FileID: rec.FileID, Reason: "changed"}}, manifest.Skipped)
+	require.Equal(t, []manifestFile{{FileID: other.FileID, Name: "other.txt", Size: 5}}, manifest.Files)
+
+	plan, err = truncated.planArchive(ctx, []string{rec.FileID}, ArchiveOptions{Format: opts.Format, Strict: true})
+	require.NoError(t, err)
+	require.Error(t, truncated.writeArchive(ctx, io.Discard, plan))
+}
+
+// truncatingStorage отдаёт объект key без последнего байта, как будто его перезаписали во время чтения.
+type truncatingStorage struct {
+	MinIOStorageI
+	key string
+}
+
+func (s truncatingStorage) GetObject(ctx context.Context, bucket, objectName string) (io.ReadCloser, error) {
+	obj, err := s.MinIOStorageI.GetObject(ctx, bucket, objectName)
+	if err != nil || objectName != s.key {
+		return obj, err
+	}
+	data, err := io.ReadAll(obj)
+	obj.Close()
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(data[:len(data)-1])), nil
+}
+
+func TestFileAccessControl(t *testing.T) {
+	svc := NewFileService(storage.NewMemoryStorage(), testBucket)
+	alice := auth.NewContext(context.Background(), auth.Claims{"sub": "alice"})
+	bob := auth.NewContext(context.Background(), auth.Claims{"sub": "bob"})
+	carol := auth.NewContext(context.Background(), auth.Claims{"sub": "carol"})
+
+	private, err := svc.Upload(alice, "private.txt", strings.NewReader("secret"), models.Checksums{})
+	require.NoError(t, err)
+	require.Equal(t, "alice", private.Owner)
+	shared, err := svc.Upload(alice, "shared.txt", strings.NewReader("shared"), models.Checksums{})
+	require.NoError(t, err)
+	// файл, загруженный до включения аутентификации, доступен всем только на чтение
+	legacy, err := svc.Upload(context.Background(), "legacy.txt", strings.NewReader("legacy"), models.Checksums{})
+	require.NoError(t, err)
+	require.Empty(t, legacy.Owner)
+
+	_, err = svc.GrantAccess(bob, shared.FileID, "carol")
+	require.ErrorIs(t, err, apperrors.ErrPermissionDenied)
+	_, err = svc.GrantAccess(alice, legacy.FileID, "bob")
+	require.ErrorIs(t, err, apperrors.ErrPermissionDenied)
+	info, err := svc.GrantAccess(alice, shared.FileID, "bob")
+	require.NoError(t, err)
+	require.Equal(t, []string{"bob"}, info.GetSharedWith())
+
+	listIDs := func(ctx context.Context) []string {
+		files, _, err := svc.ListFiles(ctx, ListOptions{PageSize: 10})
+		require.NoError(t, err)
+		var ids []string
+		for _, f := range files {
+			ids = append(ids, f.GetFileId())
+		}
+		return ids
+	}
+	require.ElementsMatch(t, []string{private.FileID, shared.FileID, legacy.FileID}, listIDs(alice))
+	require.ElementsMatch(t, []string{shared.FileID, legacy.FileID}, listIDs(bob))
+	require.ElementsMatch(t, []string{legacy.FileID}, listIDs(carol))
+
+	_, _, err = svc.DownloadLink(bob, private.FileID, LinkOptions{})
+	require.ErrorIs(t, err, apperrors.ErrPermissionDenied)
+	_, _, err = svc.DownloadLink(bob, shared.FileID, LinkOptions{})
+	require.NoError(t, err)
+
+	_, _, err = svc.DownloadLink(carol, legacy.FileID, LinkOptions{})
+	require.NoError(t, err)
+	_, err = svc.Update(carol, legacy.FileID, strings.NewReader("carol"), models.Checksums{}, Precondition{})
+	require.ErrorIs(t, err, apperrors.ErrPermissionDenied)
+	_, err = svc.Rollback(carol, legacy.FileID, 1)
+	require.ErrorIs(t, err, apperrors.ErrPermissionDenied)
+	_, err = svc.DeleteFile(carol, legacy.FileID, false)
+	require.ErrorIs(t, err, apperrors.ErrPermissionDenied)
+
+	// получивший доступ может менять содержимое, но не удалять файл
+	_, err = svc.Update(carol, shared.FileID, strings.NewReader("carol"), models.Checksums{}, Precondition{})
+	require.ErrorIs(t, err, apperrors.ErrPermissionDenied)
+	updated, err := svc.Update(bob, shared.FileID, strings.NewReader("bob"), models.Checksums{}, Precondition{})
+	require.NoError(t, err)
+	require.Equal(t, "alice", updated.Owner)
+	_, err = svc.DeleteFile(bob, shared.FileID, false)
+	require.ErrorIs(t, err, apperrors.ErrPermissionDenied)
+
+	ids := []string{private.FileID, shared.FileID}
+	_, err = svc.DownloadArchive(bob, ids, ArchiveOptions{Strict: true})
+	require.ErrorIs(t, err, apperrors.ErrPermissionDenied)
+
+	archive, err := svc.DownloadArchive(bob, ids, ArchiveOptions{})
+	require.NoError(t, err)
+	data, err := io.ReadAll(archive)
+	require.NoError(t, err)
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	require.Len(t, zr.File, 2)
+	require.Equal(t, "shared.txt", zr.File[0].Name)
+	rc, err := zr.File[1].Open()
+	require.NoError(t, err)
+	defer rc.Close()
+	var manifest archiveManifest
+	require.NoError(t, json.NewDecoder(rc).Decode(&manifest))
+	require.Equal(t, []skippedFile{{FileID: private.FileID, Reason: "permission denied"}}, manifest.Skipped)
+
+	_, err = svc.RevokeAccess(alice, shared.FileID, "bob")
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{legacy.FileID}, listIDs(bob))
+
+	// проверенный токен без sub - не отключённая аутентификация: чужие файлы ему недоступны
+	nobody := auth.NewContext(context.Background(), auth.Claims{"exp": float64(time.Now().Add(time.Hour).Unix())})
+	require.Empty(t, listIDs(nobody))
+	_, _, err = svc.DownloadLink(nobody, private.FileID, LinkOptions{})
+	require.ErrorIs(t, err, apperrors.ErrPermissionDenied)
+	_, _, err = svc.DownloadLink(nobody, legacy.FileID, LinkOptions{})
+	require.ErrorIs(t, err, apperrors.ErrPermissionDenied)
+	_, err = svc.DeleteFile(nobody, private.FileID, true)
+	require.ErrorIs(t, err, apperrors.ErrPermissionDenied)
+	_, err = svc.GrantAccess(nobody, private.FileID, "mallory")
+	require.ErrorIs(t, err, apperrors.ErrPermissionDenied)
+}
+
+// failingStorage отвечает ошибкой на StatObject.
+type failingStorage struct {
+	MinIOStorageI
+	err error
+}
+
+func (s failingStorage) StatObject(context.Context, string, string) (models.ObjectInfo, error) {
+	return models.ObjectInfo{}, s.err
+}
+
+func TestStorageObserver(t *testing.T) {
+	type observation struct {
+		latency time.Duration
+		err     error
+	}
+	var observed []observation
+	observer := func(latency time.Duration, err error) {
+		observed = append(observed, observation{latency, err})
+	}
+	store := storage.NewMemoryStorage()
+	svc := NewFileService(store, testBucket, WithStorageObserver(observer))
+	ctx := context.Background()
+
+	rec, err := svc.Upload(ctx, "observed.txt", strings.NewReader("observed"), models.Checksums{})
+	require.NoError(t, err)
+	content, err := svc.DownloadFile(ctx, rec.FileID, 0, 0)
+	require.NoError(t, err)
+	content.Close()
+
+	// отсутствующий файл и обрыв загрузки клиентом - не ошибки хранилища
+	_, err = svc.DownloadFile(ctx, "missing.txt", 0, 0)
+	require.ErrorIs(t, err, apperrors.ErrFileNotFound)
+	_, err = svc.Upload(ctx, "broken.txt", io.MultiReader(strings.NewReader(strings.Repeat("partial data ", 100)), errReader{io.ErrUnexpectedEOF}), models.Checksums{})
+	require.Error(t, err)
+
+	require.NotEmpty(t, observed)
+	for _, o := range observed {
+		require.NoError(t, o.err)
+	}
+
+	failure := errors.New("storage is unavailable")
+
+	// время копии зависит от размера объекта: учитывается только результат, с нулевой длительностью
+	observed = nil
+	svc = NewFileService(failingCopyStorage{MinIOStorageI: store}, testBucket, WithStorageObserver(observer))
+	require.NoError(t, svc.storage.CopyObject(ctx, testBucket, rec.FileID, rec.FileID, nil))
+	require.Equal(t, []observation{{0, nil}}, observed)
+	observed = nil
+	svc = NewFileService(failingCopyStorage{MinIOStorageI: store, err: failure}, testBucket, WithStorageObserver(observer))
+	require.Error(t, svc.storage.CopyObject(ctx, testBucket, rec.FileID, rec.FileID, nil))
+	require.Equal(t, []observation{{0, failure}}, observed)
+
+	observed = nil
+	svc = NewFileService(failingStorage{MinIOStorageI: store, err: failure}, testBucket, WithStorageObserver(observer))
+	_, err = svc.DownloadFile(ctx, rec.FileID, 0, 0)
+	require.Error(t, err)
+	require.NotEmpty(t, observed)
+	require.ErrorIs(t, observed[0].err, failure)
+}
+
+// failingCopyStorage отвечает ошибкой err на CopyObject.
+type failingCopyStorage struct {
+	MinIOStorageI
+	err error
+}
+
+func (s failingCopyStorage) CopyObject(context.Context, string, string, string, map[string]string) error {
+	return s.err
+}
+
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) { return 0, r.err }
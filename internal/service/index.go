@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	pb "github.com/1abobik1/proto-upload-service/gen/go/upload_service/v1"
+	"github.com/1abobik1/upload_file_service/internal/apperrors"
+	"github.com/1abobik1/upload_file_service/internal/models"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// FileIndexI - локальный индекс метаданных. Хранилище остаётся источником истины:
+// ошибки индекса только логируются, а сам индекс можно перестроить через RebuildIndex.
+type FileIndexI interface {
+	Put(ctx context.Context, rec models.FileRecord) error
+	Get(ctx context.Context, fileID string) (models.FileRecord, bool, error)
+	Delete(ctx context.Context, fileID string) error
+	List(ctx context.Context, startAfter string, fn func(models.FileRecord) bool) error
+	Rebuild(ctx context.Context, fill func(put func(models.FileRecord) error) error) error
+	Built(ctx context.Context) (bool, error)
+}
+
+// WithIndex включает чтение метаданных из локального индекса вместо StatObject.
+func WithIndex(index FileIndexI) Option {
+	return func(s *FileService) {
+		s.index = index
+	}
+}
+
+// EnsureIndex перестраивает индекс, если он ещё ни разу не строился.
+func (s *FileService) EnsureIndex(ctx context.Context) error {
+	if s.index == nil {
+		return nil
+	}
+
+	built, err := s.index.Built(ctx)
+	if err != nil {
+		return err
+	}
+	if built {
+		return nil
+	}
+
+	n, err := s.RebuildIndex(ctx)
+	if err != nil {
+		return err
+	}
+	logrus.Infof("metadata index built with %d files", n)
+	return nil
+}
+
+// RebuildIndex восстанавливает индекс по содержимому бакета (ListObjects + StatObject для каждого ключа).
+func (s *FileService) RebuildIndex(ctx context.Context) (int, error) {
+	const op = "location internal/service/RebuildIndex()"
+
+	if s.index == nil {
+		return 0, errors.New("metadata index is not configured")
+	}
+
+	count := 0
+	err := s.index.Rebuild(ctx, func(put func(models.FileRecord) error) error {
+		for obj := range s.storage.ListObjects(ctx, s.bucket, "", "") {
+			if obj.Err != nil {
+				return fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, obj.Err))
+			}
+			if isReservedKey(obj.Key) {
+				continue
+			}
+
+			info, err := s.storage.StatObject(ctx, s.bucket, obj.Key)
+			if err != nil {
+				if isNoSuchKey(err) {
+					// объект удалили во время перестройки
+					continue
+				}
+				return fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+			}
+
+			if err := put(newRecord(obj.Key, info.UserMetadata, info.ContentType, info.Size)); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		logrus.WithError(err).Errorf("%s: failed to rebuild index", op)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// fileRecord возвращает метаданные файла из индекса, а при промахе - из хранилища.
+func (s *FileService) fileRecord(ctx context.Context, fileID string) (models.FileRecord, error) {
+	const op = "location internal/service/fileRecord()"
+
+	if s.index != nil {
+		rec, found, err := s.index.Get(ctx, fileID)
+		if err != nil {
+			logrus.WithError(err).Warnf("%s: failed to read index for %s", op, fileID)
+		}
+		if found {
+			return rec, nil
+		}
+	}
+
+	info, err := s.storage.StatObject(ctx, s.bucket, fileID)
+	if err != nil {
+		if isNoSuchKey(err) {
+			return models.FileRecord{}, apperrors.ErrFileNotFound
+		}
+		return models.FileRecord{}, fmt.Errorf("failed to get file metadata: %w", err)
+	}
+	return newRecord(fileID, info.UserMetadata, info.ContentType, info.Size), nil
+}
+
+// listRecords обходит файлы в порядке ключей начиная после startAfter, пока fn возвращает true.
+// При включённом индексе хранилище не читается.
+func (s *FileService) listRecords(ctx context.Context, startAfter string, fn func(models.FileRecord) bool) error {
+	const op = "location internal/service/listRecords()"
+
+	if s.index != nil {
+		return s.index.List(ctx, startAfter, fn)
+	}
+
+	// отмена останавливает листинг, когда fn больше не нужны файлы
+	listCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for obj := range s.storage.ListObjects(listCtx, s.bucket, "", startAfter) {
+		if obj.Err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			logrus.WithError(obj.Err).Warnf("%s: skipping object due to error", op)
+			continue
+		}
+		if isReservedKey(obj.Key) {
+			continue
+		}
+
+		if !fn(newRecord(obj.Key, obj.UserMetadata, obj.ContentType, obj.Size)) {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (s *FileService) indexPut(ctx context.Context, rec models.FileRecord) {
+	if s.index == nil {
+		return
+	}
+	if err := s.index.Put(ctx, rec); err != nil {
+		logrus.WithError(err).Warnf("failed to update index for %s", rec.FileID)
+	}
+}
+
+func (s *FileService) indexDelete(ctx context.Context, fileID string) {
+	if s.index == nil {
+		return
+	}
+	if err := s.index.Delete(ctx, fileID); err != nil {
+		logrus.WithError(err).Warnf("failed to delete %s from index", fileID)
+	}
+}
+
+func newRecord(fileID string, metadata map[string]string, contentType string, size int64) models.FileRecord {
+	createdAt, _ := time.Parse(time.RFC3339, metadata[MetaCreatedAt])
+	updatedAt, _ := time.Parse(time.RFC3339, metadata[MetaUpdatedAt])
+
+	// объект-указатель пустой, размер файла хранится в метаданных
+	blob := metadata[MetaBlob]
+	if blob != "" {
+		size, _ = strconv.ParseInt(metadata[MetaSize], 10, 64)
+	}
+
+	return models.FileRecord{
+		FileID:      fileID,
+		Filename:    metadata[MetaFilename],
+		ContentType: contentType,
+		Size:        size,
+		CreatedAt:   createdAt,
+		UpdatedAt:   updatedAt,
+		Checksums: models.Checksums{
+			SHA256: metadata[MetaSHA256],
+			CRC32C: metadata[MetaCRC32C],
+		},
+		Blob:     blob,
+		Version:  fileVersion(metadata),
+		ETag:     metadata[MetaETag],
+		Owner:    metadata[MetaOwner],
+		Grantees: grantees(metadata),
+	}
+}
+
+func toFileInfo(rec models.FileRecord) *pb.FileInfo {
+	return &pb.FileInfo{
+		FileId:      rec.FileID,
+		Filename:    rec.Filename,
+		CreatedAt:   timestamppb.New(rec.CreatedAt),
+		UpdatedAt:   timestamppb.New(rec.UpdatedAt),
+		Size:        uint64(rec.Size),
+		ContentType: rec.ContentType,
+		Checksums:   ToChecksumsPB(rec.Checksums),
+		Version:     rec.Version,
+		Etag:        rec.ETag,
+		Owner:       rec.Owner,
+		SharedWith:  rec.Grantees,
+	}
+}
+
+// ToChecksumsPB возвращает nil, если ни одна сумма не известна.
+func ToChecksumsPB(sums models.Checksums) *pb.Checksums {
+	if sums == (models.Checksums{}) {
+		return nil
+	}
+	return &pb.Checksums{
+		Sha256: sums.SHA256,
+		Crc32C: sums.CRC32C,
+	}
+}
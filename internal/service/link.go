@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/url"
+	"time"
+
+	"github.com/1abobik1/upload_file_service/internal/apperrors"
+)
+
+const (
+	defaultLinkExpiry = time.Hour
+	// presigned-ссылки MinIO не могут действовать дольше недели
+	maxPresignExpiry = 7 * 24 * time.Hour
+)
+
+// LinkOptions - параметры ссылки на скачивание.
+type LinkOptions struct {
+	Expiry      time.Duration // 0 - срок по умолчанию; больше максимума - обрезается до максимума
+	ContentType string        // переопределяет Content-Type ответа; пусто - тип файла
+}
+
+// WithDownloadLinkExpiry задаёт срок действия ссылок по умолчанию и максимальный срок,
+// который может запросить клиент.
+func WithDownloadLinkExpiry(defaultExpiry, maxExpiry time.Duration) Option {
+	return func(s *FileService) {
+		if maxExpiry > 0 {
+			s.linkMaxExpiry = min(maxExpiry, maxPresignExpiry)
+		}
+		if defaultExpiry > 0 {
+			s.linkExpiry = defaultExpiry
+		}
+		s.linkExpiry = min(s.linkExpiry, s.linkMaxExpiry)
+	}
+}
+
+// presign выдаёт ссылку на объект key. Браузер сохранит файл под именем filename,
+// а не под ключом объекта. Возвращает ссылку и время, до которого она действует.
+func (s *FileService) presign(ctx context.Context, key, filename string, opts LinkOptions) (string, time.Time, error) {
+	if opts.Expiry < 0 {
+		return "", time.Time{}, apperrors.ErrInvalidLinkExpiry
+	}
+	expiry := s.linkExpiry
+	if opts.Expiry > 0 {
+		expiry = min(opts.Expiry, s.linkMaxExpiry)
+	}
+
+	params := url.Values{}
+	if filename != "" {
+		params.Set("response-content-disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	}
+	if opts.ContentType != "" {
+		if _, _, err := mime.ParseMediaType(opts.ContentType); err != nil {
+			return "", time.Time{}, apperrors.ErrInvalidContentType
+		}
+		params.Set("response-content-type", opts.ContentType)
+	}
+
+	expiresAt := time.Now().Add(expiry)
+	link, err := s.storage.PresignedGetObject(ctx, s.bucket, key, expiry, params)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+	}
+	return link.String(), expiresAt, nil
+}
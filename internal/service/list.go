@@ -0,0 +1,184 @@
+package service
+
+import (
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	pb "github.com/1abobik1/proto-upload-service/gen/go/upload_service/v1"
+	"github.com/1abobik1/upload_file_service/internal/apperrors"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const maxListPageSize = 1000
+
+// ListOptions - параметры постраничного просмотра файлов. Нулевые значения фильтров не учитываются.
+type ListOptions struct {
+	PageSize  int
+	PageToken string
+
+	FilenamePrefix string
+	ContentType    string // точный MIME-тип или группа вида "image/*"
+	CreatedAfter   time.Time
+	CreatedBefore  time.Time
+	UpdatedAfter   time.Time
+	UpdatedBefore  time.Time
+	MinSize        uint64
+	MaxSize        uint64
+
+	SortBy     pb.ListSortField
+	Descending bool
+}
+
+// pageSize возвращает размер страницы с учётом верхней границы. Без PageSize файлы отдаются
+// одной страницей, как клиентам, написанным до появления пагинации.
+func (o ListOptions) pageSize() int {
+	switch {
+	case o.PageSize <= 0:
+		return math.MaxInt
+	case o.PageSize > maxListPageSize:
+		return maxListPageSize
+	default:
+		return o.PageSize
+	}
+}
+
+// keyOrder - порядок совпадает с порядком листинга хранилища, поэтому можно не читать бакет целиком.
+func (o ListOptions) keyOrder() bool {
+	return o.SortBy == pb.ListSortField_LIST_SORT_FIELD_UNSPECIFIED && !o.Descending
+}
+
+func (o ListOptions) matches(f *pb.FileInfo) bool {
+	if o.FilenamePrefix != "" && !strings.HasPrefix(f.GetFilename(), o.FilenamePrefix) {
+		return false
+	}
+	if o.ContentType != "" && !matchContentType(f.GetContentType(), o.ContentType) {
+		return false
+	}
+
+	createdAt, updatedAt := f.GetCreatedAt().AsTime(), f.GetUpdatedAt().AsTime()
+	if !o.CreatedAfter.IsZero() && createdAt.Before(o.CreatedAfter) {
+		return false
+	}
+	if !o.CreatedBefore.IsZero() && !createdAt.Before(o.CreatedBefore) {
+		return false
+	}
+	if !o.UpdatedAfter.IsZero() && updatedAt.Before(o.UpdatedAfter) {
+		return false
+	}
+	if !o.UpdatedBefore.IsZero() && !updatedAt.Before(o.UpdatedBefore) {
+		return false
+	}
+
+	if f.GetSize() < o.MinSize {
+		return false
+	}
+	if o.MaxSize != 0 && f.GetSize() > o.MaxSize {
+		return false
+	}
+	return true
+}
+
+// matchContentType сравнивает MIME-тип без параметров (charset и т.п.) с шаблоном.
+func matchContentType(contentType, pattern string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.TrimSpace(mediaType)
+
+	if group, ok := strings.CutSuffix(pattern, "/*"); ok {
+		return strings.EqualFold(strings.SplitN(mediaType, "/", 2)[0], group)
+	}
+	return strings.EqualFold(mediaType, pattern)
+}
+
+// compareFiles сравнивает файлы по полю сортировки, при равенстве - по file_id,
+// чтобы порядок был строгим и курсор страницы однозначным.
+func compareFiles(a, b *pb.FileInfo, sortBy pb.ListSortField) int {
+	var c int
+	switch sortBy {
+	case pb.ListSortField_LIST_SORT_FIELD_FILENAME:
+		c = strings.Compare(a.GetFilename(), b.GetFilename())
+	case pb.ListSortField_LIST_SORT_FIELD_CREATED_AT:
+		c = a.GetCreatedAt().AsTime().Compare(b.GetCreatedAt().AsTime())
+	case pb.ListSortField_LIST_SORT_FIELD_UPDATED_AT:
+		c = a.GetUpdatedAt().AsTime().Compare(b.GetUpdatedAt().AsTime())
+	case pb.ListSortField_LIST_SORT_FIELD_SIZE:
+		c = cmp.Compare(a.GetSize(), b.GetSize())
+	}
+	if c != 0 {
+		return c
+	}
+	return strings.Compare(a.GetFileId(), b.GetFileId())
+}
+
+func sortFiles(files []*pb.FileInfo, opts ListOptions) {
+	slices.SortFunc(files, func(a, b *pb.FileInfo) int {
+		if opts.Descending {
+			return compareFiles(b, a, opts.SortBy)
+		}
+		return compareFiles(a, b, opts.SortBy)
+	})
+}
+
+// pageCursor - содержимое page_token: последний файл предыдущей страницы и параметры сортировки,
+// с которыми он был получен.
+type pageCursor struct {
+	SortBy     pb.ListSortField `json:"s"`
+	Descending bool             `json:"d"`
+	Last       *cursorFile      `json:"l"`
+}
+
+// cursorFile хранит только поля, участвующие в сортировке.
+type cursorFile struct {
+	FileID    string    `json:"id"`
+	Filename  string    `json:"n,omitempty"`
+	CreatedAt time.Time `json:"c"`
+	UpdatedAt time.Time `json:"u"`
+	Size      uint64    `json:"sz,omitempty"`
+}
+
+func encodePageToken(last *pb.FileInfo, opts ListOptions) string {
+	cursor := pageCursor{
+		SortBy:     opts.SortBy,
+		Descending: opts.Descending,
+		Last: &cursorFile{
+			FileID:    last.GetFileId(),
+			Filename:  last.GetFilename(),
+			CreatedAt: last.GetCreatedAt().AsTime(),
+			UpdatedAt: last.GetUpdatedAt().AsTime(),
+			Size:      last.GetSize(),
+		},
+	}
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodePageToken возвращает последний файл предыдущей страницы или nil для первой страницы.
+func decodePageToken(token string, opts ListOptions) (*pb.FileInfo, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, apperrors.ErrInvalidPageToken
+	}
+	var cursor pageCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Last == nil {
+		return nil, apperrors.ErrInvalidPageToken
+	}
+	if cursor.SortBy != opts.SortBy || cursor.Descending != opts.Descending {
+		return nil, apperrors.ErrInvalidPageToken
+	}
+
+	return &pb.FileInfo{
+		FileId:    cursor.Last.FileID,
+		Filename:  cursor.Last.Filename,
+		CreatedAt: timestamppb.New(cursor.Last.CreatedAt),
+		UpdatedAt: timestamppb.New(cursor.Last.UpdatedAt),
+		Size:      cursor.Last.Size,
+	}, nil
+}
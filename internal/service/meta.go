@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/1abobik1/upload_file_service/internal/apperrors"
+)
+
+// metaPrefix - служебные JSON-записи сервиса в бакете: ссылки на блобы и статистика.
+const metaPrefix = ".meta/"
+
+// maxMetaAttempts - сколько раз updateMeta перечитывает запись, которую одновременно
+// изменил другой запрос или экземпляр сервиса.
+const maxMetaAttempts = 16
+
+// errMetaUnchanged возвращается из функции изменения updateMeta, когда запись менять не нужно.
+var errMetaUnchanged = errors.New("meta record unchanged")
+
+// readMeta читает служебную запись key в v.
+func (s *FileService) readMeta(ctx context.Context, key string, v any) error {
+	data, _, err := s.storage.ReadObject(ctx, s.bucket, key)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("corrupted meta record %s: %w", key, err)
+	}
+	return nil
+}
+
+// updateMeta читает служебную запись key, меняет её функцией update и записывает обратно
+// условной записью по ETag. Если запись за это время изменилась, всё повторяется,
+// поэтому update должна зависеть только от переданной записи. found - запись уже существует.
+// Если update вернула errMetaUnchanged, запись не перезаписывается и возвращается без ошибки.
+func updateMeta[T any](ctx context.Context, s *FileService, key string, update func(rec *T, found bool) error) (T, error) {
+	for range maxMetaAttempts {
+		var rec T
+
+		data, etag, err := s.storage.ReadObject(ctx, s.bucket, key)
+		found := err == nil
+		switch {
+		case isNoSuchKey(err):
+		case err != nil:
+			return rec, err
+		default:
+			if err := json.Unmarshal(data, &rec); err != nil {
+				return rec, fmt.Errorf("corrupted meta record %s: %w", key, err)
+			}
+		}
+
+		if err := update(&rec, found); err != nil {
+			if errors.Is(err, errMetaUnchanged) {
+				return rec, nil
+			}
+			return rec, err
+		}
+
+		data, err = json.Marshal(rec)
+		if err != nil {
+			return rec, err
+		}
+		err = s.storage.CompareAndSwapObject(ctx, s.bucket, key, etag, data)
+		if errors.Is(err, apperrors.ErrObjectModified) {
+			continue
+		}
+		return rec, err
+	}
+
+	var zero T
+	return zero, fmt.Errorf("meta record %s: %w", key, apperrors.ErrObjectModified)
+}
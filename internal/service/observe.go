@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/1abobik1/upload_file_service/internal/apperrors"
+	"github.com/1abobik1/upload_file_service/internal/models"
+)
+
+// StorageObserver получает длительность и результат каждого обращения к хранилищу.
+// err - ошибка самого хранилища: отсутствие объекта и отмена вызова клиентом не передаются.
+// Нулевая длительность означает, что время вызова не показательно и учитывать стоит только
+// результат: успешные вызовы нужны, чтобы доля ошибок считалась от всех обращений.
+type StorageObserver func(latency time.Duration, err error)
+
+// WithStorageObserver сообщает observer о задержках и ошибках хранилища, например для
+// адаптивного лимита одновременных операций с файлами.
+//
+// Время PutObject и PutObjectPart зависит от того, как быстро клиент передаёт данные,
+// а CopyObject - от размера объекта, поэтому от них observer получает только результат
+// с нулевой длительностью; presigned-ссылки и ListObjects не учитываются совсем.
+func WithStorageObserver(observer StorageObserver) Option {
+	return func(s *FileService) {
+		if observer != nil {
+			s.storage = &observedStorage{MinIOStorageI: s.storage, observe: observer}
+		}
+	}
+}
+
+type observedStorage struct {
+	MinIOStorageI
+	observe StorageObserver
+}
+
+// done передаёт observer результат вызова, начатого в start.
+func (o *observedStorage) done(start time.Time, err error) {
+	o.observe(time.Since(start), storageFailure(err))
+}
+
+// storageFailure отбрасывает ошибки, которые не говорят о проблемах хранилища: отсутствие
+// объекта и проигранная условная запись - ответы исправного хранилища, отмену вызвал клиент.
+func storageFailure(err error) error {
+	if errors.Is(err, apperrors.ErrObjectNotFound) || errors.Is(err, apperrors.ErrObjectModified) || errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+// doneUntimed передаёт observer результат вызова, время которого зависит от клиента, без длительности.
+// Ошибки чтения данных клиента (обрыв стрима, несовпадение суммы) хранилищу не приписываются,
+// и такой вызов не учитывается вовсе.
+func (o *observedStorage) doneUntimed(err error, body *bodyReader) {
+	if body.err != nil {
+		return
+	}
+	o.observe(0, storageFailure(err))
+}
+
+func (o *observedStorage) PutObject(ctx context.Context, bucket, objectName, contentType string, reader io.Reader, objectSize int64, metadata map[string]string) error {
+	body := &bodyReader{Reader: reader}
+	err := o.MinIOStorageI.PutObject(ctx, bucket, objectName, contentType, body, objectSize, metadata)
+	o.doneUntimed(err, body)
+	return err
+}
+
+func (o *observedStorage) PutObjectPart(ctx context.Context, bucket, objectName, uploadID string, partNumber int, reader io.Reader, size int64) (string, error) {
+	body := &bodyReader{Reader: reader}
+	etag, err := o.MinIOStorageI.PutObjectPart(ctx, bucket, objectName, uploadID, partNumber, body, size)
+	o.doneUntimed(err, body)
+	return etag, err
+}
+
+func (o *observedStorage) GetObject(ctx context.Context, bucket string, objectName string) (io.ReadCloser, error) {
+	start := time.Now()
+	r, err := o.MinIOStorageI.GetObject(ctx, bucket, objectName)
+	o.done(start, err)
+	return r, err
+}
+
+func (o *observedStorage) GetObjectRange(ctx context.Context, bucket, objectName string, offset, length int64) (io.ReadCloser, error) {
+	start := time.Now()
+	r, err := o.MinIOStorageI.GetObjectRange(ctx, bucket, objectName, offset, length)
+	o.done(start, err)
+	return r, err
+}
+
+func (o *observedStorage) StatObject(ctx context.Context, bucket string, objectName string) (models.ObjectInfo, error) {
+	start := time.Now()
+	info, err := o.MinIOStorageI.StatObject(ctx, bucket, objectName)
+	o.done(start, err)
+	return info, err
+}
+
+func (o *observedStorage) CopyObject(ctx context.Context, bucket, srcObject, dstObject string, metadata map[string]string) error {
+	err := o.MinIOStorageI.CopyObject(ctx, bucket, srcObject, dstObject, metadata)
+	o.observe(0, storageFailure(err))
+	return err
+}
+
+func (o *observedStorage) RemoveObject(ctx context.Context, bucket string, objectName string) error {
+	start := time.Now()
+	err := o.MinIOStorageI.RemoveObject(ctx, bucket, objectName)
+	o.done(start, err)
+	return err
+}
+
+func (o *observedStorage) ReadObject(ctx context.Context, bucket, objectName string) ([]byte, string, error) {
+	start := time.Now()
+	data, etag, err := o.MinIOStorageI.ReadObject(ctx, bucket, objectName)
+	o.done(start, err)
+	return data, etag, err
+}
+
+func (o *observedStorage) CompareAndSwapObject(ctx context.Context, bucket, objectName, etag string, data []byte) error {
+	start := time.Now()
+	err := o.MinIOStorageI.CompareAndSwapObject(ctx, bucket, objectName, etag, data)
+	o.done(start, err)
+	return err
+}
+
+func (o *observedStorage) NewMultipartUpload(ctx context.Context, bucket, objectName, contentType string, metadata map[string]string) (string, error) {
+	start := time.Now()
+	uploadID, err := o.MinIOStorageI.NewMultipartUpload(ctx, bucket, objectName, contentType, metadata)
+	o.done(start, err)
+	return uploadID, err
+}
+
+func (o *observedStorage) CompleteMultipartUpload(ctx context.Context, bucket, objectName, uploadID string, etags []string) error {
+	start := time.Now()
+	err := o.MinIOStorageI.CompleteMultipartUpload(ctx, bucket, objectName, uploadID, etags)
+	o.done(start, err)
+	return err
+}
+
+func (o *observedStorage) AbortMultipartUpload(ctx context.Context, bucket, objectName, uploadID string) error {
+	start := time.Now()
+	err := o.MinIOStorageI.AbortMultipartUpload(ctx, bucket, objectName, uploadID)
+	o.done(start, err)
+	return err
+}
+
+// bodyReader запоминает ошибку чтения загружаемых данных.
+type bodyReader struct {
+	io.Reader
+	err error
+}
+
+func (r *bodyReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+	return n, err
+}
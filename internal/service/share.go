@@ -0,0 +1,378 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/1abobik1/upload_file_service/internal/apperrors"
+	"github.com/1abobik1/upload_file_service/internal/models"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// метаданные ссылки: файл, хеш пароля, лимит и счётчик скачиваний, срок действия
+	MetaFileID       = "Fileid"
+	MetaPasswordHash = "Passwordhash"
+	MetaMaxDownloads = "Maxdownloads"
+	MetaDownloads    = "Downloads"
+	MetaExpiresAt    = "Expiresat"
+
+	// ссылки хранятся пустыми объектами под sharePrefix + токен, настройки - в метаданных
+	sharePrefix = ".shares/"
+	// путь ссылки на HTTP-сервере ссылок
+	SharePath = "/s/"
+
+	defaultSharePasswordMaxAttempts = 5
+	defaultSharePasswordLockout     = 15 * time.Minute
+)
+
+// WithShareLinks включает публичные ссылки и задаёт адрес HTTP-сервера, который отдаёт
+// по ним файлы. Без этой опции CreateShareLink возвращает ErrShareLinksDisabled.
+func WithShareLinks(publicURL string) Option {
+	return func(s *FileService) {
+		s.shareURL = strings.TrimSuffix(publicURL, "/")
+	}
+}
+
+// WithSharePasswordLimit задаёт, после скольких неверных паролей подряд ссылка перестаёт
+// проверять пароль и на какое время.
+func WithSharePasswordLimit(maxAttempts int, lockout time.Duration) Option {
+	return func(s *FileService) {
+		if maxAttempts > 0 {
+			s.shareAttempts.max = maxAttempts
+		}
+		if lockout > 0 {
+			s.shareAttempts.lockout = lockout
+		}
+	}
+}
+
+// passwordAttempts считает неверные пароли ссылок. После max неверных паролей подряд
+// пароль ссылки не проверяется до истечения lockout с последней попытки, так что
+// перебор пароля упирается в max попыток за lockout. Счётчики живут в памяти экземпляра.
+type passwordAttempts struct {
+	mu      sync.Mutex
+	byToken map[string]*failedPasswords
+	max     int
+	lockout time.Duration
+}
+
+type failedPasswords struct {
+	n    int
+	last time.Time
+}
+
+func newPasswordAttempts(maxAttempts int, lockout time.Duration) *passwordAttempts {
+	return &passwordAttempts{
+		byToken: make(map[string]*failedPasswords),
+		max:     maxAttempts,
+		lockout: lockout,
+	}
+}
+
+func (a *passwordAttempts) locked(token string, now time.Time) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	f, ok := a.byToken[token]
+	if !ok {
+		return false
+	}
+	if now.Sub(f.last) >= a.lockout {
+		delete(a.byToken, token)
+		return false
+	}
+	return f.n >= a.max
+}
+
+func (a *passwordAttempts) fail(token string, now time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// заодно забываем устаревшие счётчики других ссылок
+	for t, f := range a.byToken {
+		if now.Sub(f.last) >= a.lockout {
+			delete(a.byToken, t)
+		}
+	}
+
+	f, ok := a.byToken[token]
+	if !ok {
+		f = &failedPasswords{}
+		a.byToken[token] = f
+	}
+	f.n++
+	f.last = now
+}
+
+func (a *passwordAttempts) reset(token string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.byToken, token)
+}
+
+// ShareOptions - параметры новой ссылки; нулевые значения снимают ограничение.
+type ShareOptions struct {
+	Password     string
+	MaxDownloads uint64
+	Expiry       time.Duration
+}
+
+// ShareLink - публичная ссылка на файл, которую можно отозвать.
+type ShareLink struct {
+	Token        string
+	FileID       string
+	URL          string
+	HasPassword  bool
+	MaxDownloads uint64 // 0 - без ограничения
+	Downloads    uint64
+	CreatedAt    time.Time
+	ExpiresAt    time.Time // нулевое - бессрочная
+
+	passwordHash string
+}
+
+// CreateShareLink создаёт ссылку на файл. Пароль хранится только в виде bcrypt-хеша.
+func (s *FileService) CreateShareLink(ctx context.Context, fileID string, opts ShareOptions) (ShareLink, error) {
+	const op = "location internal/service/CreateShareLink()"
+
+	if s.shareURL == "" {
+		return ShareLink{}, apperrors.ErrShareLinksDisabled
+	}
+	if isReservedKey(fileID) {
+		return ShareLink{}, apperrors.ErrFileNotFound
+	}
+	if opts.Expiry < 0 {
+		return ShareLink{}, apperrors.ErrInvalidLinkExpiry
+	}
+	if len(opts.Password) > 72 {
+		// bcrypt учитывает только первые 72 байта
+		return ShareLink{}, apperrors.ErrSharePasswordTooLong
+	}
+
+	rec, err := s.fileRecord(ctx, fileID)
+	if err != nil {
+		logrus.WithError(err).Errorf("%s: failed to get file metadata", op)
+		return ShareLink{}, err
+	}
+	if err := authorize(ctx, rec, accessOwner); err != nil {
+		return ShareLink{}, err
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		return ShareLink{}, err
+	}
+
+	now := time.Now()
+	metadata := map[string]string{
+		MetaFileID:       fileID,
+		MetaCreatedAt:    now.Format(time.RFC3339),
+		MetaMaxDownloads: strconv.FormatUint(opts.MaxDownloads, 10),
+		MetaDownloads:    "0",
+	}
+	if opts.Expiry > 0 {
+		metadata[MetaExpiresAt] = now.Add(opts.Expiry).Format(time.RFC3339)
+	}
+	setOwner(ctx, metadata)
+	if opts.Password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(opts.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return ShareLink{}, err
+		}
+		metadata[MetaPasswordHash] = string(hash)
+	}
+
+	if err := s.storage.PutObject(ctx, s.bucket, sharePrefix+token, "application/octet-stream", bytes.NewReader(nil), 0, metadata); err != nil {
+		logrus.WithError(err).Errorf("%s: failed to save share link", op)
+		return ShareLink{}, fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+	}
+
+	return s.shareLink(token, metadata), nil
+}
+
+// ListShareLinks возвращает ссылки на файл fileID или все ссылки вызывающего, если fileID пустой.
+// Просроченные и исчерпанные ссылки тоже возвращаются, пока их не отозвали.
+func (s *FileService) ListShareLinks(ctx context.Context, fileID string) ([]ShareLink, error) {
+	if fileID != "" {
+		rec, err := s.fileRecord(ctx, fileID)
+		if err != nil {
+			return nil, err
+		}
+		if err := authorize(ctx, rec, accessOwner); err != nil {
+			return nil, err
+		}
+	}
+	return s.listShareLinks(ctx, fileID, callerFrom(ctx))
+}
+
+// listShareLinks возвращает ссылки на файл fileID (все, если fileID пустой),
+// созданные c; anyCaller - ссылки всех пользователей.
+func (s *FileService) listShareLinks(ctx context.Context, fileID string, c caller) ([]ShareLink, error) {
+	var links []ShareLink
+
+	for obj := range s.storage.ListObjects(ctx, s.bucket, sharePrefix, "") {
+		if obj.Err != nil {
+			return nil, fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, obj.Err))
+		}
+		if fileID != "" && obj.UserMetadata[MetaFileID] != fileID {
+			continue
+		}
+		if fileID == "" && !canAccess(c, models.FileRecord{Owner: obj.UserMetadata[MetaOwner]}, accessOwner) {
+			continue
+		}
+		links = append(links, s.shareLink(obj.Key[len(sharePrefix):], obj.UserMetadata))
+	}
+
+	return links, nil
+}
+
+// RevokeShareLink удаляет ссылку; скачивания, начатые до отзыва, не прерываются.
+func (s *FileService) RevokeShareLink(ctx context.Context, token string) error {
+	const op = "location internal/service/RevokeShareLink()"
+
+	unlock := s.shareLocks.lock(token)
+	defer unlock()
+
+	info, err := s.shareObject(ctx, token)
+	if err != nil {
+		return err
+	}
+	if err := authorizeMetadata(ctx, info.UserMetadata, accessOwner); err != nil {
+		return err
+	}
+	if err := s.storage.RemoveObject(ctx, s.bucket, sharePrefix+token); err != nil {
+		logrus.WithError(err).Errorf("%s: failed to remove share link", op)
+		return fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+	}
+	return nil
+}
+
+// OpenShareLink проверяет ссылку и пароль и открывает файл целиком. Скачивание засчитывается
+// при открытии, поэтому оборванная передача тоже расходует лимит. После нескольких неверных
+// паролей подряд ссылка на время возвращает ErrSharePasswordLocked, даже с верным паролем.
+func (s *FileService) OpenShareLink(ctx context.Context, token, password string) (FileContent, error) {
+	const op = "location internal/service/OpenShareLink()"
+
+	unlock := s.shareLocks.lock(token)
+	defer unlock()
+
+	info, err := s.shareObject(ctx, token)
+	if err != nil {
+		return FileContent{}, err
+	}
+	link := s.shareLink(token, info.UserMetadata)
+
+	switch {
+	case !link.ExpiresAt.IsZero() && time.Now().After(link.ExpiresAt):
+		return FileContent{}, apperrors.ErrShareLinkExpired
+	case link.MaxDownloads > 0 && link.Downloads >= link.MaxDownloads:
+		return FileContent{}, apperrors.ErrShareLinkExhausted
+	case link.HasPassword && password == "":
+		return FileContent{}, apperrors.ErrSharePasswordRequired
+	}
+	if link.HasPassword {
+		now := time.Now()
+		if s.shareAttempts.locked(token, now) {
+			return FileContent{}, apperrors.ErrSharePasswordLocked
+		}
+		if bcrypt.CompareHashAndPassword([]byte(link.passwordHash), []byte(password)) != nil {
+			s.shareAttempts.fail(token, now)
+			return FileContent{}, apperrors.ErrSharePasswordMismatch
+		}
+		s.shareAttempts.reset(token)
+	}
+
+	rec, err := s.fileRecord(ctx, link.FileID)
+	if err != nil {
+		logrus.WithError(err).Errorf("%s: failed to get file metadata", op)
+		return FileContent{}, err
+	}
+
+	rc, err := s.storage.GetObject(ctx, s.bucket, dataKey(rec))
+	if err != nil {
+		logrus.WithError(err).Errorf("%s: failed to get object", op)
+		if isNoSuchKey(err) {
+			return FileContent{}, apperrors.ErrFileNotFound
+		}
+		return FileContent{}, fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+	}
+
+	metadata := info.UserMetadata
+	metadata[MetaDownloads] = strconv.FormatUint(link.Downloads+1, 10)
+	if err := s.storage.CopyObject(ctx, s.bucket, sharePrefix+token, sharePrefix+token, metadata); err != nil {
+		rc.Close()
+		logrus.WithError(err).Errorf("%s: failed to count download", op)
+		return FileContent{}, fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+	}
+
+	return FileContent{
+		ReadCloser: rc,
+		File:       toFileInfo(rec),
+		Length:     rec.Size,
+	}, nil
+}
+
+// removeShareLinks удаляет ссылки на окончательно удалённый файл.
+func (s *FileService) removeShareLinks(ctx context.Context, fileID string) {
+	const op = "location internal/service/removeShareLinks()"
+
+	links, err := s.listShareLinks(ctx, fileID, anyCaller)
+	if err != nil {
+		logrus.WithError(err).Warnf("%s: failed to list share links of %s", op, fileID)
+		return
+	}
+	for _, link := range links {
+		if err := s.storage.RemoveObject(ctx, s.bucket, sharePrefix+link.Token); err != nil {
+			logrus.WithError(err).Warnf("%s: failed to remove share link of %s", op, fileID)
+		}
+	}
+}
+
+func (s *FileService) shareObject(ctx context.Context, token string) (models.ObjectInfo, error) {
+	if token == "" {
+		return models.ObjectInfo{}, apperrors.ErrShareLinkNotFound
+	}
+
+	info, err := s.storage.StatObject(ctx, s.bucket, sharePrefix+token)
+	if err != nil {
+		if isNoSuchKey(err) {
+			return models.ObjectInfo{}, apperrors.ErrShareLinkNotFound
+		}
+		return models.ObjectInfo{}, fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+	}
+	return info, nil
+}
+
+func (s *FileService) shareLink(token string, metadata map[string]string) ShareLink {
+	link := ShareLink{
+		Token:        token,
+		FileID:       metadata[MetaFileID],
+		URL:          s.shareURL + SharePath + token,
+		HasPassword:  metadata[MetaPasswordHash] != "",
+		passwordHash: metadata[MetaPasswordHash],
+	}
+	link.MaxDownloads, _ = strconv.ParseUint(metadata[MetaMaxDownloads], 10, 64)
+	link.Downloads, _ = strconv.ParseUint(metadata[MetaDownloads], 10, 64)
+	link.CreatedAt, _ = time.Parse(time.RFC3339, metadata[MetaCreatedAt])
+	link.ExpiresAt, _ = time.Parse(time.RFC3339, metadata[MetaExpiresAt])
+	return link
+}
+
+// newShareToken - 192 случайных бита, токен нельзя подобрать перебором.
+func newShareToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/1abobik1/upload_file_service/internal/apperrors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// statsKey - служебная запись со статистикой хранилища
+	statsKey = metaPrefix + "stats"
+	// statsFlushTimeout ограничивает запись статистики при остановке сервиса
+	statsFlushTimeout = 5 * time.Second
+)
+
+// Stats - статистика хранилища.
+type Stats struct {
+	Files        uint64 `json:"files"`         // файлы вне корзины
+	LogicalBytes uint64 `json:"logical_bytes"` // размер всех файлов, включая корзину и прежние версии, как если бы каждый хранился отдельно
+	StoredBytes  uint64 `json:"stored_bytes"`  // фактически занятое место
+	Blobs        uint64 `json:"blobs"`
+}
+
+// DedupRatio - во сколько раз дедупликация уменьшила занятое место.
+func (st Stats) DedupRatio() float64 {
+	if st.StoredBytes == 0 {
+		return 1
+	}
+	return float64(st.LogicalBytes) / float64(st.StoredBytes)
+}
+
+// statsDelta - изменение статистики, ещё не записанное в statsKey.
+type statsDelta struct {
+	Files        int64
+	LogicalBytes int64
+	StoredBytes  int64
+	Blobs        int64
+}
+
+func (d statsDelta) neg() statsDelta {
+	return statsDelta{Files: -d.Files, LogicalBytes: -d.LogicalBytes, StoredBytes: -d.StoredBytes, Blobs: -d.Blobs}
+}
+
+func (st *Stats) apply(d statsDelta) {
+	st.Files = addClamped(st.Files, d.Files)
+	st.LogicalBytes = addClamped(st.LogicalBytes, d.LogicalBytes)
+	st.StoredBytes = addClamped(st.StoredBytes, d.StoredBytes)
+	st.Blobs = addClamped(st.Blobs, d.Blobs)
+}
+
+// addClamped не даёт счётчику уйти ниже нуля, если изменение учтено и в начальном обходе бакета.
+func addClamped(v uint64, d int64) uint64 {
+	if d < 0 && uint64(-d) > v {
+		return 0
+	}
+	return uint64(int64(v) + d)
+}
+
+// statsCounter накапливает изменения статистики между записями в хранилище.
+type statsCounter struct {
+	mu      sync.Mutex
+	pending statsDelta
+}
+
+func (c *statsCounter) add(d statsDelta) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pending.Files += d.Files
+	c.pending.LogicalBytes += d.LogicalBytes
+	c.pending.StoredBytes += d.StoredBytes
+	c.pending.Blobs += d.Blobs
+}
+
+func (c *statsCounter) take() statsDelta {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	d := c.pending
+	c.pending = statsDelta{}
+	return d
+}
+
+// objectStats - вклад объекта файла под key (в том числе в корзине или прежней версии)
+// в статистику. size - размер самого объекта: у указателя на блоб он нулевой.
+func objectStats(key string, metadata map[string]string, size int64) statsDelta {
+	d := statsDelta{LogicalBytes: size, StoredBytes: size}
+	if metadata[MetaBlob] != "" {
+		d.LogicalBytes, _ = strconv.ParseInt(metadata[MetaSize], 10, 64)
+	}
+	if !strings.HasPrefix(key, trashPrefix) && !strings.HasPrefix(key, versionPrefix) {
+		d.Files = 1
+	}
+	return d
+}
+
+// countObject учитывает появление объекта файла в статистике.
+func (s *FileService) countObject(key string, metadata map[string]string, size int64) {
+	s.stats.add(objectStats(key, metadata, size))
+}
+
+// uncountObject учитывает удаление объекта файла из статистики.
+func (s *FileService) uncountObject(key string, metadata map[string]string, size int64) {
+	s.stats.add(objectStats(key, metadata, size).neg())
+}
+
+// Stats возвращает статистику хранилища. Счётчики хранятся в бакете и меняются вместе
+// с файлами; полный обход бакета нужен только при первом вызове, когда счётчиков ещё нет.
+func (s *FileService) Stats(ctx context.Context) (Stats, error) {
+	st, err := s.flushStats(ctx)
+	if err != nil {
+		return Stats{}, fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+	}
+	return st, nil
+}
+
+// flushStats добавляет накопленные изменения к счётчикам в бакете и возвращает результат.
+// При ошибке изменения остаются до следующей записи.
+func (s *FileService) flushStats(ctx context.Context) (Stats, error) {
+	pending := s.stats.take()
+
+	st, err := updateMeta(ctx, s, statsKey, func(st *Stats, found bool) error {
+		if found {
+			if pending == (statsDelta{}) {
+				return errMetaUnchanged
+			}
+			st.apply(pending)
+			return nil
+		}
+		// обход уже учитывает накопленные изменения
+		scanned, err := s.scanStats(ctx)
+		*st = scanned
+		return err
+	})
+	if err != nil {
+		s.stats.add(pending)
+		return Stats{}, err
+	}
+	return st, nil
+}
+
+// RunStatsFlusher периодически записывает изменения статистики в бакет, пока не отменён ctx,
+// и записывает оставшиеся при остановке.
+func (s *FileService) RunStatsFlusher(ctx context.Context, interval time.Duration) {
+	flush := func(ctx context.Context) {
+		if _, err := s.flushStats(ctx); err != nil {
+			logrus.WithError(err).Warn("failed to flush storage stats")
+		}
+	}
+
+	runEvery(ctx, interval, func() { flush(ctx) })
+
+	stopCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), statsFlushTimeout)
+	defer cancel()
+	flush(stopCtx)
+}
+
+// scanStats считает статистику полным обходом бакета.
+func (s *FileService) scanStats(ctx context.Context) (Stats, error) {
+	var st Stats
+
+	for obj := range s.storage.ListObjects(ctx, s.bucket, "", "") {
+		if obj.Err != nil {
+			return Stats{}, obj.Err
+		}
+
+		switch {
+		case strings.HasPrefix(obj.Key, blobPrefix):
+			st.Blobs++
+			st.StoredBytes += uint64(obj.Size)
+		case isReservedKey(obj.Key) && !strings.HasPrefix(obj.Key, trashPrefix) && !strings.HasPrefix(obj.Key, versionPrefix):
+			// незавершённые загрузки, публичные ссылки и служебные записи
+		default:
+			st.apply(objectStats(obj.Key, obj.UserMetadata, obj.Size))
+		}
+	}
+
+	return st, nil
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	pb "github.com/1abobik1/proto-upload-service/gen/go/upload_service/v1"
+	"github.com/1abobik1/upload_file_service/internal/apperrors"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	MetaDeletedAt = "Deletedat"
+
+	// удалённые файлы хранятся в том же бакете под ключом trashPrefix + fileID
+	trashPrefix           = ".trash/"
+	defaultTrashRetention = 30 * 24 * time.Hour
+)
+
+// WithTrashRetention задаёт, сколько файл хранится в корзине до окончательного удаления.
+func WithTrashRetention(retention time.Duration) Option {
+	return func(s *FileService) {
+		if retention > 0 {
+			s.trashRetention = retention
+		}
+	}
+}
+
+// DeleteFile перемещает файл в корзину и возвращает время его окончательного удаления.
+// При permanent файл удаляется сразу вместе с прежними версиями и публичными ссылками,
+// а возвращаемое время нулевое. Ссылки на файл в корзине сохраняются, но не работают до восстановления.
+func (s *FileService) DeleteFile(ctx context.Context, fileID string, permanent bool) (time.Time, error) {
+	const op = "location internal/service/DeleteFile()"
+
+	if isReservedKey(fileID) {
+		return time.Time{}, apperrors.ErrFileNotFound
+	}
+
+	// перенос в корзину не должен смешаться с обновлением или восстановлением того же файла
+	unlock := s.fileLocks.lock(fileID)
+	defer unlock()
+
+	info, err := s.storage.StatObject(ctx, s.bucket, fileID)
+	if err != nil {
+		logrus.WithError(err).Errorf("%s: failed to get file metadata", op)
+		if isNoSuchKey(err) {
+			return time.Time{}, apperrors.ErrFileNotFound
+		}
+		return time.Time{}, fmt.Errorf("failed to get file metadata: %w", err)
+	}
+	metadata := info.UserMetadata
+	if err := authorizeMetadata(ctx, metadata, accessOwner); err != nil {
+		return time.Time{}, err
+	}
+
+	if !permanent {
+		now := time.Now()
+		metadata[MetaDeletedAt] = now.Format(time.RFC3339)
+
+		if err := s.storage.CopyObject(ctx, s.bucket, fileID, trashPrefix+fileID, metadata); err != nil {
+			logrus.WithError(err).Errorf("%s: failed to move object to trash", op)
+			return time.Time{}, fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+		}
+		s.countObject(trashPrefix+fileID, metadata, info.Size)
+		if err := s.storage.RemoveObject(ctx, s.bucket, fileID); err != nil {
+			logrus.WithError(err).Errorf("%s: failed to remove object", op)
+			return time.Time{}, fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+		}
+		s.uncountObject(fileID, metadata, info.Size)
+		s.indexDelete(ctx, fileID)
+		return now.Add(s.trashRetention), nil
+	}
+
+	if err := s.storage.RemoveObject(ctx, s.bucket, fileID); err != nil {
+		logrus.WithError(err).Errorf("%s: failed to remove object", op)
+		return time.Time{}, fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+	}
+	s.uncountObject(fileID, metadata, info.Size)
+	s.releaseBlob(ctx, metadata)
+	s.removeVersions(ctx, fileID)
+	s.removeShareLinks(ctx, fileID)
+	s.indexDelete(ctx, fileID)
+	return time.Time{}, nil
+}
+
+// RestoreFile возвращает файл из корзины под прежним fileID.
+func (s *FileService) RestoreFile(ctx context.Context, fileID string) (*pb.FileInfo, error) {
+	const op = "location internal/service/RestoreFile()"
+
+	if isReservedKey(fileID) {
+		return nil, apperrors.ErrFileNotFound
+	}
+
+	// проверка свободного fileID и перенос из корзины должны быть атомарны относительно других изменений файла
+	unlock := s.fileLocks.lock(fileID)
+	defer unlock()
+
+	info, err := s.storage.StatObject(ctx, s.bucket, trashPrefix+fileID)
+	if err != nil {
+		logrus.WithError(err).Errorf("%s: failed to get trashed file metadata", op)
+		if isNoSuchKey(err) {
+			return nil, apperrors.ErrFileNotFound
+		}
+		return nil, fmt.Errorf("failed to get file metadata: %w", err)
+	}
+	metadata := info.UserMetadata
+	if err := authorizeMetadata(ctx, metadata, accessOwner); err != nil {
+		return nil, err
+	}
+
+	// файл с таким ID мог появиться снова, перезаписывать его нельзя
+	_, err = s.storage.StatObject(ctx, s.bucket, fileID)
+	if err == nil {
+		return nil, apperrors.ErrFileAlreadyExists
+	}
+	if !isNoSuchKey(err) {
+		logrus.WithError(err).Errorf("%s: failed to check original key", op)
+		return nil, fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+	}
+
+	delete(metadata, MetaDeletedAt)
+
+	if err := s.storage.CopyObject(ctx, s.bucket, trashPrefix+fileID, fileID, metadata); err != nil {
+		logrus.WithError(err).Errorf("%s: failed to restore object", op)
+		return nil, fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+	}
+	s.countObject(fileID, metadata, info.Size)
+	if err := s.storage.RemoveObject(ctx, s.bucket, trashPrefix+fileID); err != nil {
+		// файл уже восстановлен, копия в корзине будет удалена при очистке
+		logrus.WithError(err).Warnf("%s: failed to remove trashed copy of %s", op, fileID)
+	} else {
+		s.uncountObject(trashPrefix+fileID, metadata, info.Size)
+	}
+
+	rec := newRecord(fileID, metadata, info.ContentType, info.Size)
+	s.indexPut(ctx, rec)
+
+	return toFileInfo(rec), nil
+}
+
+// ListTrash возвращает файлы вызывающего, лежащие в корзине.
+func (s *FileService) ListTrash(ctx context.Context) ([]*pb.TrashedFile, error) {
+	const op = "location internal/service/ListTrash()"
+
+	var files []*pb.TrashedFile
+	c := callerFrom(ctx)
+
+	for obj := range s.storage.ListObjects(ctx, s.bucket, trashPrefix, "") {
+		if obj.Err != nil {
+			logrus.WithError(obj.Err).Warnf("%s: skipping object due to error", op)
+			continue
+		}
+
+		rec := newRecord(obj.Key[len(trashPrefix):], obj.UserMetadata, obj.ContentType, obj.Size)
+		if !canAccess(c, rec, accessOwner) {
+			continue
+		}
+		file := toFileInfo(rec)
+
+		deletedAt := trashedAt(obj.UserMetadata, obj.LastModified)
+		files = append(files, &pb.TrashedFile{
+			File:      file,
+			DeletedAt: timestamppb.New(deletedAt),
+			PurgeAt:   timestamppb.New(deletedAt.Add(s.trashRetention)),
+		})
+	}
+
+	return files, nil
+}
+
+// PurgeTrash окончательно удаляет файлы, пролежавшие в корзине дольше срока хранения.
+func (s *FileService) PurgeTrash(ctx context.Context) (int, error) {
+	const op = "location internal/service/PurgeTrash()"
+
+	purged := 0
+	now := time.Now()
+
+	for obj := range s.storage.ListObjects(ctx, s.bucket, trashPrefix, "") {
+		if obj.Err != nil {
+			return purged, fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, obj.Err))
+		}
+
+		if now.Before(trashedAt(obj.UserMetadata, obj.LastModified).Add(s.trashRetention)) {
+			continue
+		}
+
+		if err := s.storage.RemoveObject(ctx, s.bucket, obj.Key); err != nil {
+			logrus.WithError(err).Warnf("%s: failed to remove object %s", op, obj.Key)
+			continue
+		}
+		s.uncountObject(obj.Key, obj.UserMetadata, obj.Size)
+		s.releaseBlob(ctx, obj.UserMetadata)
+		s.removeVersions(ctx, obj.Key[len(trashPrefix):])
+		s.removeShareLinks(ctx, obj.Key[len(trashPrefix):])
+		purged++
+	}
+
+	return purged, nil
+}
+
+// RunTrashJanitor периодически очищает корзину, пока не отменён ctx.
+func (s *FileService) RunTrashJanitor(ctx context.Context, interval time.Duration) {
+	runEvery(ctx, interval, func() {
+		n, err := s.PurgeTrash(ctx)
+		if err != nil {
+			logrus.WithError(err).Warn("failed to purge trash")
+		}
+		if n > 0 {
+			logrus.Infof("purged %d files from trash", n)
+		}
+	})
+}
+
+// trashedAt - время удаления файла; для копий без метаданных берётся время перемещения в корзину.
+func trashedAt(metadata map[string]string, lastModified time.Time) time.Time {
+	if deletedAt, err := time.Parse(time.RFC3339, metadata[MetaDeletedAt]); err == nil {
+		return deletedAt
+	}
+	return lastModified
+}
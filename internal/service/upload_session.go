@@ -0,0 +1,525 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/1abobik1/upload_file_service/internal/apperrors"
+	"github.com/1abobik1/upload_file_service/internal/auth"
+	"github.com/1abobik1/upload_file_service/internal/models"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// сессии хранятся служебными записями под sessionPrefix + id
+	sessionPrefix = metaPrefix + "sessions/"
+	// uploadSessionWriterLease - сколько стрим держит сессию без сохранения новой части;
+	// сессию, чей писатель пропал вместе с экземпляром сервиса, можно продолжить по истечении этого времени
+	uploadSessionWriterLease = 5 * time.Minute
+
+	defaultUploadSessionTTL = 24 * time.Hour
+	defaultUploadPartSize   = 8 << 20
+	// минимальный размер части multipart-загрузки в S3 (кроме последней)
+	minUploadPartSize = 5 << 20
+)
+
+// WithUploadSessionTTL задаёт время жизни незавершённой сессии с момента последней записи.
+func WithUploadSessionTTL(ttl time.Duration) Option {
+	return func(s *FileService) {
+		if ttl > 0 {
+			s.sessions.ttl = ttl
+		}
+	}
+}
+
+// WithUploadPartSize задаёт размер части, которыми сессия сохраняет данные в хранилище.
+func WithUploadPartSize(size int) Option {
+	return func(s *FileService) {
+		s.sessions.partSize = max(size, minUploadPartSize)
+	}
+}
+
+// UploadSession - состояние сессии возобновляемой загрузки.
+type UploadSession struct {
+	ID            string
+	PartSize      uint64
+	CommittedSize uint64
+	Sealed        bool
+	ExpiresAt     time.Time
+}
+
+// uploadSession - запись сессии в бакете под sessionKey(id). Сессия не привязана к экземпляру
+// сервиса: её продолжает и завершает любой экземпляр, в том числе после перезапуска.
+type uploadSession struct {
+	Owner       string            `json:"owner,omitempty"` // subject создавшего; другим пользователям сессия не видна
+	Filename    string            `json:"filename"`
+	FileID      string            `json:"file_id,omitempty"`    // ключ объекта, появляется вместе с первой частью
+	ObjectKey   string            `json:"object_key,omitempty"` // куда пишутся части: fileID или, при дедупликации, новый блоб
+	UploadID    string            `json:"upload_id,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	ContentType string            `json:"content_type,omitempty"`
+	ETags       []string          `json:"etags,omitempty"`
+	// состояние сумм сохранённых частей; неполные части в них не попадают, пустой CRC32C - сумма не считается
+	SHA256    []byte    `json:"sha256"`
+	CRC32C    []byte    `json:"crc32c,omitempty"`
+	Committed uint64    `json:"committed"`
+	Sealed    bool      `json:"sealed,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// стрим, который пишет в сессию, держит её до WriterUntil; второй стрим получит ErrUploadSessionBusy
+	Writer      string    `json:"writer,omitempty"`
+	WriterUntil time.Time `json:"writer_until,omitempty"`
+}
+
+func sessionKey(id string) string {
+	return sessionPrefix + id
+}
+
+// visible сообщает, доступна ли сессия владельцу owner в момент now.
+func (sess *uploadSession) visible(owner string, now time.Time) bool {
+	return sess.Owner == owner && now.Before(sess.ExpiresAt)
+}
+
+// busy сообщает, пишет ли в сессию стрим, отличный от writer.
+func (sess *uploadSession) busy(writer string, now time.Time) bool {
+	return sess.Writer != "" && sess.Writer != writer && now.Before(sess.WriterUntil)
+}
+
+// hashes восстанавливает суммы сохранённых частей.
+func (sess *uploadSession) hashes() (hash.Hash, hash.Hash32, error) {
+	sha := sha256.New()
+	if err := sha.(encoding.BinaryUnmarshaler).UnmarshalBinary(sess.SHA256); err != nil {
+		return nil, nil, fmt.Errorf("corrupted sha256 state: %w", err)
+	}
+	if sess.CRC32C == nil {
+		return sha, nil, nil
+	}
+	crc := crc32.New(crc32cTable)
+	if err := crc.(encoding.BinaryUnmarshaler).UnmarshalBinary(sess.CRC32C); err != nil {
+		return nil, nil, fmt.Errorf("corrupted crc32c state: %w", err)
+	}
+	return sha, crc, nil
+}
+
+// saveHashes запоминает состояние сумм в записи сессии.
+func (sess *uploadSession) saveHashes(sha hash.Hash, crc hash.Hash32) error {
+	state, err := sha.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		return err
+	}
+	sess.SHA256 = state
+	if crc == nil {
+		return nil
+	}
+	sess.CRC32C, err = crc.(encoding.BinaryMarshaler).MarshalBinary()
+	return err
+}
+
+// uploadSessions - настройки сессий; сами сессии хранятся в бакете.
+type uploadSessions struct {
+	ttl      time.Duration
+	partSize int
+}
+
+func newUploadSessions(ttl time.Duration, partSize int) *uploadSessions {
+	return &uploadSessions{
+		ttl:      ttl,
+		partSize: partSize,
+	}
+}
+
+func (us *uploadSessions) snapshot(id string, sess uploadSession) UploadSession {
+	return UploadSession{
+		ID:            id,
+		PartSize:      uint64(us.partSize),
+		CommittedSize: sess.Committed,
+		Sealed:        sess.Sealed,
+		ExpiresAt:     sess.ExpiresAt,
+	}
+}
+
+// readSession читает сессию id, принадлежащую owner.
+func (s *FileService) readSession(ctx context.Context, id, owner string) (uploadSession, error) {
+	var sess uploadSession
+	if err := s.readMeta(ctx, sessionKey(id), &sess); err != nil {
+		if isNoSuchKey(err) {
+			return uploadSession{}, apperrors.ErrUploadSessionNotFound
+		}
+		return uploadSession{}, fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+	}
+	if !sess.visible(owner, time.Now()) {
+		return uploadSession{}, apperrors.ErrUploadSessionNotFound
+	}
+	return sess, nil
+}
+
+// lockSession захватывает сессию для записи и возвращает её вместе с токеном писателя,
+// который нужен для сохранения частей и освобождения сессии.
+func (s *FileService) lockSession(ctx context.Context, id, owner string) (uploadSession, string, error) {
+	writer := uuid.New().String()
+	sess, err := updateMeta(ctx, s, sessionKey(id), func(sess *uploadSession, found bool) error {
+		now := time.Now()
+		if !found || !sess.visible(owner, now) {
+			return apperrors.ErrUploadSessionNotFound
+		}
+		if sess.busy(writer, now) {
+			return apperrors.ErrUploadSessionBusy
+		}
+		sess.Writer = writer
+		sess.WriterUntil = now.Add(uploadSessionWriterLease)
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, apperrors.ErrUploadSessionNotFound) || errors.Is(err, apperrors.ErrUploadSessionBusy) {
+			return uploadSession{}, "", err
+		}
+		return uploadSession{}, "", fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+	}
+	return sess, writer, nil
+}
+
+// saveSession меняет захваченную writer сессию функцией update и продлевает захват.
+// Если сессию за это время перехватил другой стрим, возвращается ErrUploadSessionBusy.
+func (s *FileService) saveSession(ctx context.Context, id, writer string, update func(sess *uploadSession)) (uploadSession, error) {
+	return updateMeta(ctx, s, sessionKey(id), func(sess *uploadSession, found bool) error {
+		if !found {
+			return apperrors.ErrUploadSessionNotFound
+		}
+		if sess.Writer != writer {
+			return apperrors.ErrUploadSessionBusy
+		}
+		update(sess)
+		sess.WriterUntil = time.Now().Add(uploadSessionWriterLease)
+		return nil
+	})
+}
+
+// unlockSession освобождает сессию, захваченную writer.
+func (s *FileService) unlockSession(ctx context.Context, id, writer string) {
+	const op = "location internal/service/unlockSession()"
+
+	// клиент мог уже отключиться, а сессия не должна оставаться занятой до истечения захвата
+	_, err := updateMeta(context.WithoutCancel(ctx), s, sessionKey(id), func(sess *uploadSession, found bool) error {
+		if !found || sess.Writer != writer {
+			return errMetaUnchanged
+		}
+		sess.Writer = ""
+		sess.WriterUntil = time.Time{}
+		return nil
+	})
+	if err != nil {
+		logrus.WithError(err).Warnf("%s: failed to release session %s", op, id)
+	}
+}
+
+// removeSession удаляет запись закрытой сессии.
+func (s *FileService) removeSession(ctx context.Context, id string) {
+	const op = "location internal/service/removeSession()"
+
+	if err := s.storage.RemoveObject(context.WithoutCancel(ctx), s.bucket, sessionKey(id)); err != nil {
+		// запись удалит уборка, когда сессия истечёт
+		logrus.WithError(err).Warnf("%s: failed to remove session %s", op, id)
+	}
+}
+
+func (s *FileService) CreateUploadSession(ctx context.Context, filename string) (UploadSession, error) {
+	const op = "location internal/service/CreateUploadSession()"
+
+	id := uuid.New().String()
+	sess := uploadSession{
+		Owner:     auth.Subject(ctx),
+		Filename:  filename,
+		ExpiresAt: time.Now().Add(s.sessions.ttl),
+	}
+	var crc hash.Hash32
+	if s.crc32c {
+		crc = crc32.New(crc32cTable)
+	}
+	if err := sess.saveHashes(sha256.New(), crc); err != nil {
+		return UploadSession{}, err
+	}
+
+	sess, err := updateMeta(ctx, s, sessionKey(id), func(rec *uploadSession, found bool) error {
+		*rec = sess
+		return nil
+	})
+	if err != nil {
+		logrus.WithError(err).Errorf("%s: failed to save session %s", op, id)
+		return UploadSession{}, fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+	}
+
+	return s.sessions.snapshot(id, sess), nil
+}
+
+func (s *FileService) UploadSessionStatus(ctx context.Context, sessionID string) (UploadSession, error) {
+	sess, err := s.readSession(ctx, sessionID, auth.Subject(ctx))
+	if err != nil {
+		return UploadSession{}, err
+	}
+	return s.sessions.snapshot(sessionID, sess), nil
+}
+
+// AppendUploadSession дописывает данные в сессию начиная с offset, который должен совпадать
+// с уже сохранённым размером. Данные сохраняются целыми частями; если стрим оборвался,
+// неполная часть отбрасывается и клиент продолжает с CommittedSize.
+// Часть короче PartSize сохраняется только при штатном конце стрима и считается последней.
+func (s *FileService) AppendUploadSession(ctx context.Context, sessionID string, offset uint64, r io.Reader) (UploadSession, error) {
+	const op = "location internal/service/AppendUploadSession()"
+
+	sess, writer, err := s.lockSession(ctx, sessionID, auth.Subject(ctx))
+	if err != nil {
+		return UploadSession{}, err
+	}
+	defer s.unlockSession(ctx, sessionID, writer)
+
+	if sess.Sealed {
+		return s.sessions.snapshot(sessionID, sess), apperrors.ErrUploadSessionSealed
+	}
+	if offset != sess.Committed {
+		return s.sessions.snapshot(sessionID, sess), apperrors.ErrUploadOffsetMismatch
+	}
+
+	sha, crc, err := sess.hashes()
+	if err != nil {
+		logrus.WithError(err).Errorf("%s: failed to restore checksums of session %s", op, sessionID)
+		return s.sessions.snapshot(sessionID, sess), fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+	}
+
+	buf := make([]byte, s.sessions.partSize)
+	for {
+		n, readErr := io.ReadFull(r, buf)
+		if readErr != nil && readErr != io.EOF && readErr != io.ErrUnexpectedEOF {
+			return s.sessions.snapshot(sessionID, sess), readErr
+		}
+
+		if n > 0 {
+			saved, err := s.putSessionPart(ctx, sessionID, writer, sess, sha, crc, buf[:n], readErr == io.ErrUnexpectedEOF)
+			if err != nil {
+				if errors.Is(err, apperrors.ErrUploadSessionNotFound) || errors.Is(err, apperrors.ErrUploadSessionBusy) {
+					return s.sessions.snapshot(sessionID, sess), err
+				}
+				logrus.WithError(err).Errorf("%s: failed to put part of session %s", op, sessionID)
+				return s.sessions.snapshot(sessionID, sess), fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+			}
+			sess = saved
+		}
+
+		if readErr != nil {
+			return s.sessions.snapshot(sessionID, sess), nil
+		}
+	}
+}
+
+// putSessionPart сохраняет очередную часть сессии, захваченной writer, и возвращает обновлённую запись.
+// sha и crc - суммы уже сохранённых частей, last - часть последняя.
+func (s *FileService) putSessionPart(ctx context.Context, id, writer string, sess uploadSession, sha hash.Hash, crc hash.Hash32, part []byte, last bool) (uploadSession, error) {
+	if sess.UploadID == "" {
+		// тип файла и ключ объекта определяются по первой части
+		contentType := http.DetectContentType(part)
+		filename, fileID := prepareFilename(sess.Filename, contentType)
+
+		now := time.Now().Format(time.RFC3339)
+		metadata := map[string]string{
+			MetaFilename:  filename,
+			MetaCreatedAt: now,
+			MetaETag:      newETag(),
+			MetaUpdatedAt: now,
+		}
+		if sess.Owner != "" {
+			metadata[MetaOwner] = sess.Owner
+		}
+		objectKey, objectMetadata := fileID, metadata
+		if s.dedup {
+			// части пишутся сразу в блоб, указатель под fileID сохраняется при завершении
+			objectKey, objectMetadata = blobKey(uuid.New().String()), nil
+		}
+		uploadID, err := s.storage.NewMultipartUpload(ctx, s.bucket, objectKey, contentType, objectMetadata)
+		if err != nil {
+			return sess, err
+		}
+
+		sess.Filename = filename
+		sess.FileID = fileID
+		sess.ObjectKey = objectKey
+		sess.UploadID = uploadID
+		sess.Metadata = metadata
+		sess.ContentType = contentType
+	}
+
+	etag, err := s.storage.PutObjectPart(ctx, s.bucket, sess.ObjectKey, sess.UploadID, len(sess.ETags)+1, bytes.NewReader(part), int64(len(part)))
+	if err != nil {
+		return sess, err
+	}
+
+	sha.Write(part)
+	if crc != nil {
+		crc.Write(part)
+	}
+	next := sess
+	next.ETags = append(slices.Clone(sess.ETags), etag)
+	next.Committed += uint64(len(part))
+	next.Sealed = last
+	next.ExpiresAt = time.Now().Add(s.sessions.ttl)
+	if err := next.saveHashes(sha, crc); err != nil {
+		return sess, err
+	}
+
+	return s.saveSession(ctx, id, writer, func(rec *uploadSession) {
+		*rec = next
+	})
+}
+
+// FinalizeUploadSession собирает объект из сохранённых частей и закрывает сессию.
+func (s *FileService) FinalizeUploadSession(ctx context.Context, sessionID string) (models.FileRecord, error) {
+	const op = "location internal/service/FinalizeUploadSession()"
+
+	sess, writer, err := s.lockSession(ctx, sessionID, auth.Subject(ctx))
+	if err != nil {
+		return models.FileRecord{}, err
+	}
+
+	if sess.UploadID == "" {
+		// в сессию ничего не записали - сохраняем пустой файл обычной загрузкой
+		rec, err := s.Upload(ctx, sess.Filename, bytes.NewReader(nil), models.Checksums{})
+		if err != nil {
+			s.unlockSession(ctx, sessionID, writer)
+			return models.FileRecord{}, err
+		}
+		s.removeSession(ctx, sessionID)
+		return rec, nil
+	}
+
+	sha, crc, err := sess.hashes()
+	if err != nil {
+		s.unlockSession(ctx, sessionID, writer)
+		logrus.WithError(err).Errorf("%s: failed to restore checksums of session %s", op, sessionID)
+		return models.FileRecord{}, fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+	}
+
+	if err := s.storage.CompleteMultipartUpload(ctx, s.bucket, sess.ObjectKey, sess.UploadID, sess.ETags); err != nil {
+		s.unlockSession(ctx, sessionID, writer)
+		logrus.WithError(err).Errorf("%s: failed to complete multipart upload of session %s", op, sessionID)
+		return models.FileRecord{}, fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+	}
+	s.removeSession(ctx, sessionID)
+
+	sums := checksumsOf(sha, crc)
+	if s.dedup {
+		blob := strings.TrimPrefix(sess.ObjectKey, blobPrefix)
+		if err := s.adoptBlob(ctx, sess.FileID, blob, sess.ContentType, sums, int64(sess.Committed), sess.Metadata); err != nil {
+			logrus.WithError(err).Errorf("%s: failed to save pointer of session %s", op, sessionID)
+			return models.FileRecord{}, fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+		}
+		s.countObject(sess.FileID, sess.Metadata, 0)
+	} else {
+		s.countObject(sess.FileID, sess.Metadata, int64(sess.Committed))
+		s.storeChecksums(ctx, sess.FileID, sess.Metadata, sums)
+	}
+
+	rec := newRecord(sess.FileID, sess.Metadata, sess.ContentType, int64(sess.Committed))
+	s.indexPut(ctx, rec)
+
+	return rec, nil
+}
+
+// CleanupExpiredUploadSessions закрывает просроченные сессии и удаляет их части из хранилища.
+// Незавершённая загрузка без записи сессии (например, начатая до того, как сессии стали
+// храниться в бакете) отменяется, когда с её начала пройдёт TTL.
+func (s *FileService) CleanupExpiredUploadSessions(ctx context.Context) int {
+	const op = "location internal/service/CleanupExpiredUploadSessions()"
+
+	now := time.Now()
+	uploads, err := s.storage.ListMultipartUploads(ctx, s.bucket)
+	if err != nil {
+		logrus.WithError(err).Warnf("%s: failed to list multipart uploads", op)
+		return 0
+	}
+	// загрузки, которые ещё никому не принадлежат; живые и отменённые с сессией отсюда убираются
+	orphaned := make(map[string]bool, len(uploads))
+	for _, upload := range uploads {
+		orphaned[upload.UploadID] = true
+	}
+
+	cleaned := 0
+	// если какую-то запись прочитать не удалось, брошенные загрузки не ищутся
+	complete := true
+	for obj := range s.storage.ListObjects(ctx, s.bucket, sessionPrefix, "") {
+		if obj.Err != nil {
+			logrus.WithError(obj.Err).Warnf("%s: failed to list upload sessions", op)
+			complete = false
+			break
+		}
+		id := strings.TrimPrefix(obj.Key, sessionPrefix)
+
+		// сессия забирается, только если она всё ещё просрочена и в неё никто не пишет
+		janitor := uuid.New().String()
+		expired := false
+		sess, err := updateMeta(ctx, s, obj.Key, func(sess *uploadSession, found bool) error {
+			expired = found && !now.Before(sess.ExpiresAt) && !sess.busy(janitor, now)
+			if !expired {
+				return errMetaUnchanged
+			}
+			sess.Writer = janitor
+			sess.WriterUntil = now.Add(uploadSessionWriterLease)
+			return nil
+		})
+		if err != nil {
+			logrus.WithError(err).Warnf("%s: failed to read upload session %s", op, id)
+			complete = false
+			continue
+		}
+		if !expired {
+			delete(orphaned, sess.UploadID)
+			continue
+		}
+
+		// загрузки может уже не быть, если сессию завершили, но не успели удалить запись
+		if orphaned[sess.UploadID] {
+			if err := s.storage.AbortMultipartUpload(ctx, s.bucket, sess.ObjectKey, sess.UploadID); err != nil {
+				// запись остаётся, уборка повторит попытку, когда истечёт захват
+				logrus.WithError(err).Warnf("%s: failed to abort multipart upload of session %s", op, id)
+				continue
+			}
+			delete(orphaned, sess.UploadID)
+		}
+		if err := s.storage.RemoveObject(ctx, s.bucket, obj.Key); err != nil {
+			logrus.WithError(err).Warnf("%s: failed to remove upload session %s", op, id)
+		}
+		cleaned++
+	}
+	if !complete {
+		return cleaned
+	}
+
+	for _, upload := range uploads {
+		if now.Sub(upload.Initiated) < s.sessions.ttl || !orphaned[upload.UploadID] {
+			continue
+		}
+		if err := s.storage.AbortMultipartUpload(ctx, s.bucket, upload.Key, upload.UploadID); err != nil {
+			logrus.WithError(err).Warnf("%s: failed to abort orphaned multipart upload %s", op, upload.UploadID)
+			continue
+		}
+		cleaned++
+	}
+	return cleaned
+}
+
+// RunUploadSessionJanitor периодически чистит просроченные сессии, пока не отменён ctx.
+func (s *FileService) RunUploadSessionJanitor(ctx context.Context, interval time.Duration) {
+	runEvery(ctx, interval, func() {
+		if n := s.CleanupExpiredUploadSessions(ctx); n > 0 {
+			logrus.Infof("cleaned up %d expired upload sessions", n)
+		}
+	})
+}
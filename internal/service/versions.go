@@ -0,0 +1,385 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/1abobik1/proto-upload-service/gen/go/upload_service/v1"
+	"github.com/1abobik1/upload_file_service/internal/apperrors"
+	"github.com/1abobik1/upload_file_service/internal/models"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// номер версии текущего содержимого; у файлов без него версия 1
+	MetaVersion = "Version"
+	// когда версия была заменена новым содержимым
+	MetaArchivedAt = "Archivedat"
+
+	// прежние версии хранятся под versionPrefix + fileID + "/" + номер версии
+	versionPrefix          = ".versions/"
+	defaultVersionMaxCount = 10
+)
+
+// WithVersionRetention задаёт, сколько прежних версий файла хранится: не больше maxCount
+// и не дольше maxAge после замены. Нулевое значение снимает соответствующее ограничение.
+func WithVersionRetention(maxCount int, maxAge time.Duration) Option {
+	return func(s *FileService) {
+		s.versionMaxCount = maxCount
+		s.versionMaxAge = maxAge
+	}
+}
+
+// ListVersions возвращает версии файла от текущей к самой старой.
+func (s *FileService) ListVersions(ctx context.Context, fileID string) ([]*pb.FileVersion, error) {
+	const op = "location internal/service/ListVersions()"
+
+	if isReservedKey(fileID) {
+		return nil, apperrors.ErrFileNotFound
+	}
+
+	current, err := s.storage.StatObject(ctx, s.bucket, fileID)
+	if err != nil {
+		logrus.WithError(err).Errorf("%s: failed to get file metadata", op)
+		if isNoSuchKey(err) {
+			return nil, apperrors.ErrFileNotFound
+		}
+		return nil, fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+	}
+
+	rec := newRecord(fileID, current.UserMetadata, current.ContentType, current.Size)
+	if err := authorize(ctx, rec, accessRead); err != nil {
+		return nil, err
+	}
+	versions := []*pb.FileVersion{{
+		Version: rec.Version,
+		File:    toFileInfo(rec),
+		Current: true,
+	}}
+
+	archived, err := s.archivedVersions(ctx, fileID)
+	if err != nil {
+		logrus.WithError(err).Errorf("%s: failed to list versions", op)
+		return nil, err
+	}
+	for _, obj := range slices.Backward(archived) {
+		rec := newRecord(fileID, obj.UserMetadata, obj.ContentType, obj.Size)
+		versions = append(versions, &pb.FileVersion{
+			Version:    rec.Version,
+			File:       toFileInfo(rec),
+			ArchivedAt: timestamppb.New(archivedAt(obj)),
+		})
+	}
+
+	return versions, nil
+}
+
+// VersionDownloadLink возвращает ссылку на скачивание указанной версии файла
+// и время, до которого она действует.
+func (s *FileService) VersionDownloadLink(ctx context.Context, fileID string, version uint64, opts LinkOptions) (string, time.Time, error) {
+	const op = "location internal/service/VersionDownloadLink()"
+
+	if isReservedKey(fileID) {
+		return "", time.Time{}, apperrors.ErrFileNotFound
+	}
+
+	rec, err := s.fileRecord(ctx, fileID)
+	if err != nil {
+		logrus.WithError(err).Errorf("%s: failed to get file metadata", op)
+		return "", time.Time{}, err
+	}
+	if err := authorize(ctx, rec, accessRead); err != nil {
+		return "", time.Time{}, err
+	}
+	if rec.Version == version {
+		return s.DownloadLink(ctx, fileID, opts)
+	}
+
+	info, err := s.versionObject(ctx, fileID, version)
+	if err != nil {
+		logrus.WithError(err).Errorf("%s: failed to get version %d of %s", op, version, fileID)
+		return "", time.Time{}, err
+	}
+
+	key := info.Key
+	if blob := info.UserMetadata[MetaBlob]; blob != "" {
+		key = blobKey(blob)
+	}
+
+	link, expiresAt, err := s.presign(ctx, key, info.UserMetadata[MetaFilename], opts)
+	if err != nil {
+		logrus.WithError(err).Errorf("%s: failed to generate presigned URL", op)
+		return "", time.Time{}, err
+	}
+
+	return link, expiresAt, nil
+}
+
+// Rollback делает указанную версию текущей. Заменяемое содержимое сохраняется
+// как очередная прежняя версия, поэтому откат тоже можно отменить.
+func (s *FileService) Rollback(ctx context.Context, fileID string, version uint64) (*pb.FileInfo, error) {
+	const op = "location internal/service/Rollback()"
+
+	if isReservedKey(fileID) {
+		return nil, apperrors.ErrFileNotFound
+	}
+
+	unlock := s.fileLocks.lock(fileID)
+	defer unlock()
+
+	current, err := s.storage.StatObject(ctx, s.bucket, fileID)
+	if err != nil {
+		logrus.WithError(err).Errorf("%s: failed to get file metadata", op)
+		if isNoSuchKey(err) {
+			return nil, apperrors.ErrFileNotFound
+		}
+		return nil, fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+	}
+	if err := authorizeMetadata(ctx, current.UserMetadata, accessWrite); err != nil {
+		return nil, err
+	}
+	if version == fileVersion(current.UserMetadata) {
+		return toFileInfo(newRecord(fileID, current.UserMetadata, current.ContentType, current.Size)), nil
+	}
+
+	target, err := s.versionObject(ctx, fileID, version)
+	if err != nil {
+		logrus.WithError(err).Errorf("%s: failed to get version %d of %s", op, version, fileID)
+		return nil, err
+	}
+
+	archived, err := s.archiveVersion(ctx, fileID, current)
+	if err != nil {
+		logrus.WithError(err).Errorf("%s: failed to archive current version", op)
+		return nil, err
+	}
+
+	metadata := maps.Clone(target.UserMetadata)
+	delete(metadata, MetaArchivedAt)
+	metadata[MetaCreatedAt] = current.UserMetadata[MetaCreatedAt]
+	metadata[MetaUpdatedAt] = time.Now().Format(time.RFC3339)
+	metadata[MetaVersion] = strconv.FormatUint(fileVersion(current.UserMetadata)+1, 10)
+	metadata[MetaETag] = newETag()
+	// права доступа не откатываются вместе с содержимым
+	delete(metadata, MetaOwner)
+	if owner := current.UserMetadata[MetaOwner]; owner != "" {
+		metadata[MetaOwner] = owner
+	}
+	setGrantees(metadata, grantees(current.UserMetadata))
+
+	if err := s.addPointerRef(ctx, metadata); err != nil {
+		s.removeVersion(ctx, archived)
+		logrus.WithError(err).Errorf("%s: failed to reference blob", op)
+		return nil, fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+	}
+	if err := s.storage.CopyObject(ctx, s.bucket, target.Key, fileID, metadata); err != nil {
+		s.releaseBlob(ctx, metadata)
+		s.removeVersion(ctx, archived)
+		logrus.WithError(err).Errorf("%s: failed to restore version", op)
+		return nil, fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+	}
+	s.uncountObject(fileID, current.UserMetadata, current.Size)
+	s.countObject(fileID, metadata, target.Size)
+	s.releaseBlob(ctx, current.UserMetadata)
+	s.pruneVersions(ctx, fileID, time.Now())
+
+	rec := newRecord(fileID, metadata, target.ContentType, target.Size)
+	s.indexPut(ctx, rec)
+
+	return toFileInfo(rec), nil
+}
+
+// PruneVersions удаляет прежние версии всех файлов, вышедшие за пределы хранения.
+func (s *FileService) PruneVersions(ctx context.Context) (int, error) {
+	const op = "location internal/service/PruneVersions()"
+
+	if s.versionMaxCount <= 0 && s.versionMaxAge <= 0 {
+		return 0, nil
+	}
+
+	// версии одного файла идут подряд и по возрастанию номера
+	var (
+		pruned  int
+		fileID  string
+		archive []models.ObjectInfo
+	)
+	now := time.Now()
+	flush := func() {
+		for _, obj := range s.expiredVersions(archive, now) {
+			s.removeVersion(ctx, obj)
+			pruned++
+		}
+		archive = archive[:0]
+	}
+
+	for obj := range s.storage.ListObjects(ctx, s.bucket, versionPrefix, "") {
+		if obj.Err != nil {
+			flush()
+			return pruned, fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, obj.Err))
+		}
+
+		id, _, ok := parseVersionKey(obj.Key)
+		if !ok {
+			logrus.Warnf("%s: unexpected key %s", op, obj.Key)
+			continue
+		}
+		if id != fileID {
+			flush()
+			fileID = id
+		}
+		archive = append(archive, obj)
+	}
+	flush()
+
+	return pruned, nil
+}
+
+// RunVersionJanitor периодически удаляет устаревшие версии, пока не отменён ctx.
+func (s *FileService) RunVersionJanitor(ctx context.Context, interval time.Duration) {
+	runEvery(ctx, interval, func() {
+		n, err := s.PruneVersions(ctx)
+		if err != nil {
+			logrus.WithError(err).Warn("failed to prune versions")
+		}
+		if n > 0 {
+			logrus.Infof("pruned %d file versions", n)
+		}
+	})
+}
+
+// archiveVersion сохраняет текущее содержимое файла как прежнюю версию.
+func (s *FileService) archiveVersion(ctx context.Context, fileID string, current models.ObjectInfo) (models.ObjectInfo, error) {
+	metadata := maps.Clone(current.UserMetadata)
+	metadata[MetaArchivedAt] = time.Now().Format(time.RFC3339)
+
+	archived := current
+	archived.Key = versionKey(fileID, fileVersion(metadata))
+	archived.UserMetadata = metadata
+
+	// указатель на блоб в прежней версии - ещё одна ссылка на него
+	if err := s.addPointerRef(ctx, metadata); err != nil {
+		return models.ObjectInfo{}, fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+	}
+	if err := s.storage.CopyObject(ctx, s.bucket, fileID, archived.Key, metadata); err != nil {
+		s.releaseBlob(ctx, metadata)
+		return models.ObjectInfo{}, fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+	}
+	s.countObject(archived.Key, metadata, archived.Size)
+
+	return archived, nil
+}
+
+// pruneVersions применяет ограничения хранения к версиям одного файла.
+func (s *FileService) pruneVersions(ctx context.Context, fileID string, now time.Time) {
+	const op = "location internal/service/pruneVersions()"
+
+	if s.versionMaxCount <= 0 && s.versionMaxAge <= 0 {
+		return
+	}
+
+	archive, err := s.archivedVersions(ctx, fileID)
+	if err != nil {
+		logrus.WithError(err).Warnf("%s: failed to list versions of %s", op, fileID)
+		return
+	}
+	for _, obj := range s.expiredVersions(archive, now) {
+		s.removeVersion(ctx, obj)
+	}
+}
+
+// removeVersions удаляет все прежние версии файла.
+func (s *FileService) removeVersions(ctx context.Context, fileID string) {
+	const op = "location internal/service/removeVersions()"
+
+	archive, err := s.archivedVersions(ctx, fileID)
+	if err != nil {
+		logrus.WithError(err).Warnf("%s: failed to list versions of %s", op, fileID)
+		return
+	}
+	for _, obj := range archive {
+		s.removeVersion(ctx, obj)
+	}
+}
+
+func (s *FileService) removeVersion(ctx context.Context, obj models.ObjectInfo) {
+	const op = "location internal/service/removeVersion()"
+
+	if err := s.storage.RemoveObject(ctx, s.bucket, obj.Key); err != nil {
+		logrus.WithError(err).Warnf("%s: failed to remove version %s", op, obj.Key)
+		return
+	}
+	s.uncountObject(obj.Key, obj.UserMetadata, obj.Size)
+	s.releaseBlob(ctx, obj.UserMetadata)
+}
+
+// expiredVersions выбирает из отсортированных по возрастанию версий те, что не укладываются
+// в ограничения хранения.
+func (s *FileService) expiredVersions(archive []models.ObjectInfo, now time.Time) []models.ObjectInfo {
+	var expired []models.ObjectInfo
+	for i, obj := range archive {
+		tooMany := s.versionMaxCount > 0 && len(archive)-i > s.versionMaxCount
+		tooOld := s.versionMaxAge > 0 && now.Sub(archivedAt(obj)) > s.versionMaxAge
+		if tooMany || tooOld {
+			expired = append(expired, obj)
+		}
+	}
+	return expired
+}
+
+// archivedVersions возвращает прежние версии файла по возрастанию номера.
+func (s *FileService) archivedVersions(ctx context.Context, fileID string) ([]models.ObjectInfo, error) {
+	var archive []models.ObjectInfo
+	for obj := range s.storage.ListObjects(ctx, s.bucket, versionPrefix+fileID+"/", "") {
+		if obj.Err != nil {
+			return nil, fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, obj.Err))
+		}
+		archive = append(archive, obj)
+	}
+	return archive, nil
+}
+
+func (s *FileService) versionObject(ctx context.Context, fileID string, version uint64) (models.ObjectInfo, error) {
+	info, err := s.storage.StatObject(ctx, s.bucket, versionKey(fileID, version))
+	if err != nil {
+		if isNoSuchKey(err) {
+			return models.ObjectInfo{}, apperrors.ErrVersionNotFound
+		}
+		return models.ObjectInfo{}, fmt.Errorf("%w", errors.Join(apperrors.ErrStorageFailure, err))
+	}
+	return info, nil
+}
+
+// номер дополняется нулями, чтобы лексикографический порядок ключей совпадал с порядком версий
+func versionKey(fileID string, version uint64) string {
+	return fmt.Sprintf("%s%s/%020d", versionPrefix, fileID, version)
+}
+
+func parseVersionKey(key string) (fileID string, version uint64, ok bool) {
+	fileID, number, ok := strings.Cut(strings.TrimPrefix(key, versionPrefix), "/")
+	if !ok {
+		return "", 0, false
+	}
+	version, err := strconv.ParseUint(number, 10, 64)
+	return fileID, version, err == nil
+}
+
+func fileVersion(metadata map[string]string) uint64 {
+	if version, err := strconv.ParseUint(metadata[MetaVersion], 10, 64); err == nil && version > 0 {
+		return version
+	}
+	return 1
+}
+
+func archivedAt(obj models.ObjectInfo) time.Time {
+	if t, err := time.Parse(time.RFC3339, obj.UserMetadata[MetaArchivedAt]); err == nil {
+		return t
+	}
+	return obj.LastModified
+}
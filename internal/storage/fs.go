@@ -0,0 +1,1123 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/1abobik1/upload_file_service/internal/apperrors"
+	"github.com/1abobik1/upload_file_service/internal/models"
+	"github.com/google/uuid"
+)
+
+// Раскладка бакета на диске:
+//
+//	<root>/<bucket>/objects/<ключ>       - содержимое объекта
+//	<root>/<bucket>/meta/<ключ>.json     - метаданные объекта (sidecar)
+//	<root>/<bucket>/uploads/<uploadID>/  - незавершённые multipart-загрузки
+//	<root>/<bucket>/tmp/                 - временные файлы до атомарного переименования
+//
+// Ключ хранится одним экранированным именем файла (см. escapeKey), поэтому ключи
+// вида ".trash/x" или "../x" не создают каталогов и не выходят за пределы бакета.
+const (
+	fsObjectsDir = "objects"
+	fsMetaDir    = "meta"
+	fsUploadsDir = "uploads"
+	fsTmpDir     = "tmp"
+
+	fsUploadManifest = "upload.json"
+)
+
+// fsMeta - содержимое sidecar-файла объекта.
+type fsMeta struct {
+	ContentType  string            `json:"content_type"`
+	ETag         string            `json:"etag"`
+	UserMetadata map[string]string `json:"user_metadata"`
+}
+
+// fsUpload - описание незавершённой multipart-загрузки.
+type fsUpload struct {
+	Key          string            `json:"key"`
+	ContentType  string            `json:"content_type"`
+	UserMetadata map[string]string `json:"user_metadata"`
+}
+
+// FSStorage хранит объекты на локальном диске. Предназначено для окружений без MinIO
+// и рассчитано на один экземпляр сервиса. Presigned-ссылки ведут на HTTP-обработчик
+// Handler и подписываются HMAC-SHA256.
+type FSStorage struct {
+	root       string
+	publicURL  string
+	pathPrefix string // путь publicURL, например "/files", если ссылки проходят через шлюз
+	signingKey []byte
+
+	// mu согласует переименование файла объекта и его sidecar-файла
+	mu sync.RWMutex
+}
+
+// NewFSStorage создаёт хранилище в каталоге root. publicURL - адрес, по которому клиенты
+// обращаются к Handler. При пустом signingKey генерируется случайный ключ, и выданные
+// ссылки перестают действовать после перезапуска.
+func NewFSStorage(root, publicURL string, signingKey []byte) (*FSStorage, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(strings.TrimSuffix(publicURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid public URL: %w", err)
+	}
+
+	if len(signingKey) == 0 {
+		signingKey = make([]byte, 32)
+		if _, err := rand.Read(signingKey); err != nil {
+			return nil, err
+		}
+	}
+
+	return &FSStorage{
+		root:       root,
+		publicURL:  strings.TrimSuffix(publicURL, "/"),
+		pathPrefix: u.Path,
+		signingKey: signingKey,
+	}, nil
+}
+
+func (s *FSStorage) PutObject(ctx context.Context, bucket, objectName, contentType string, reader io.Reader, objectSize int64, metadata map[string]string) error {
+	if err := s.prepareBucket(bucket); err != nil {
+		return err
+	}
+
+	tmp, etag, n, err := s.writeTemp(bucket, &ctxReader{ctx: ctx, r: reader})
+	if err != nil {
+		return err
+	}
+	if objectSize >= 0 && n != objectSize {
+		os.Remove(tmp)
+		return fmt.Errorf("object size mismatch: expected %d bytes, got %d", objectSize, n)
+	}
+
+	return s.commit(bucket, objectName, tmp, fsMeta{
+		ContentType:  contentType,
+		ETag:         etag,
+		UserMetadata: metadata,
+	})
+}
+
+func (s *FSStorage) GetObject(ctx context.Context, bucket string, objectName string) (io.ReadCloser, error) {
+	path, err := s.objectPath(bucket, objectName)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, mapFSError(err)
+	}
+	return f, nil
+}
+
+// GetObjectRange читает length байт объекта начиная с offset; length <= 0 - до конца объекта.
+func (s *FSStorage) GetObjectRange(ctx context.Context, bucket, objectName string, offset, length int64) (io.ReadCloser, error) {
+	rc, err := s.GetObject(ctx, bucket, objectName)
+	if err != nil {
+		return nil, err
+	}
+	f := rc.(*os.File)
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if length <= 0 {
+		return f, nil
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(f, length), f}, nil
+}
+
+func (s *FSStorage) StatObject(ctx context.Context, bucket string, objectName string) (models.ObjectInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.stat(bucket, objectName)
+}
+
+// ListObjects возвращает объекты с ключами после startAfter в лексикографическом порядке,
+// как это делает S3.
+func (s *FSStorage) ListObjects(ctx context.Context, bucket string, prefix string, startAfter string) <-chan models.ObjectInfo {
+	out := make(chan models.ObjectInfo, 1)
+
+	go func() {
+		defer close(out)
+
+		keys, err := s.listKeys(bucket, prefix, startAfter)
+		if err != nil {
+			select {
+			case out <- models.ObjectInfo{Err: err}:
+			case <-ctx.Done():
+			}
+			return
+		}
+
+		for _, key := range keys {
+			s.mu.RLock()
+			info, err := s.stat(bucket, key)
+			s.mu.RUnlock()
+			if errors.Is(err, apperrors.ErrObjectNotFound) {
+				// объект удалили после чтения каталога
+				continue
+			}
+			if err != nil {
+				info = models.ObjectInfo{Err: err}
+			}
+
+			select {
+			case out <- info:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// PresignedGetObject возвращает ссылку на Handler, действующую expiry. reqParams - параметры
+// ответа в стиле S3 (response-content-type, response-content-disposition и т.д.);
+// они подписываются вместе со ссылкой, поэтому подменить их нельзя.
+func (s *FSStorage) PresignedGetObject(ctx context.Context, bucket string, objectName string, expiry time.Duration, reqParams url.Values) (*url.URL, error) {
+	if _, err := s.objectPath(bucket, objectName); err != nil {
+		return nil, err
+	}
+	for key := range reqParams {
+		if _, ok := responseHeaders[key]; !ok {
+			return nil, fmt.Errorf("unsupported presign parameter %q", key)
+		}
+	}
+
+	u, err := url.Parse(s.publicURL)
+	if err != nil {
+		return nil, err
+	}
+
+	expires := strconv.FormatInt(time.Now().Add(expiry).Unix(), 10)
+	u.Path += "/" + bucket + "/" + objectName
+	u.RawPath = ""
+	query := url.Values{
+		"expires":   {expires},
+		"signature": {s.sign(bucket, objectName, expires, reqParams)},
+	}
+	for key, values := range reqParams {
+		query[key] = values
+	}
+	u.RawQuery = query.Encode()
+	return u, nil
+}
+
+// fsPostPolicy - условия загрузки POST-запросом, подписываются целиком.
+type fsPostPolicy struct {
+	Expires     int64  `json:"expires"`
+	Bucket      string `json:"bucket"`
+	Key         string `json:"key"`
+	ContentType string `json:"content_type,omitempty"`
+	MinSize     int64  `json:"min_size"`
+	MaxSize     int64  `json:"max_size"`
+
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// PresignedPostPolicy возвращает адрес Handler и поля формы для загрузки объекта
+// multipart/form-data POST-запросом, как в S3: поля передаются до поля file с содержимым.
+func (s *FSStorage) PresignedPostPolicy(ctx context.Context, bucket, objectName string, expiry time.Duration, policy models.UploadPolicy) (*url.URL, map[string]string, error) {
+	if _, err := s.objectPath(bucket, objectName); err != nil {
+		return nil, nil, err
+	}
+	if policy.MinSize < 0 || policy.MinSize > policy.MaxSize {
+		return nil, nil, fmt.Errorf("invalid content length range %d-%d", policy.MinSize, policy.MaxSize)
+	}
+
+	u, err := url.Parse(s.publicURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	u.Path += "/" + bucket + "/"
+	u.RawPath = ""
+
+	data, err := json.Marshal(fsPostPolicy{
+		Expires:     time.Now().Add(expiry).Unix(),
+		Bucket:      bucket,
+		Key:         objectName,
+		ContentType: policy.ContentType,
+		MinSize:     policy.MinSize,
+		MaxSize:     policy.MaxSize,
+		Metadata:    policy.Metadata,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	encoded := base64.StdEncoding.EncodeToString(data)
+
+	fields := map[string]string{
+		"key":       objectName,
+		"policy":    encoded,
+		"signature": hex.EncodeToString(s.postSignature(encoded)),
+	}
+	if policy.ContentType != "" {
+		fields["Content-Type"] = policy.ContentType
+	}
+	for key, value := range policy.Metadata {
+		fields[metadataField(key)] = value
+	}
+	return u, fields, nil
+}
+
+// CopyObject копирует объект, заменяя пользовательские метаданные на metadata.
+// Content-Type исходного объекта сохраняется. Копирование объекта в самого себя
+// переписывает только sidecar-файл.
+func (s *FSStorage) CopyObject(ctx context.Context, bucket, srcObject, dstObject string, metadata map[string]string) error {
+	if err := s.prepareBucket(bucket); err != nil {
+		return err
+	}
+	if srcObject == dstObject {
+		return s.replaceMetadata(bucket, srcObject, metadata)
+	}
+
+	src, err := s.objectPath(bucket, srcObject)
+	if err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	info, err := s.stat(bucket, srcObject)
+	if err != nil {
+		s.mu.RUnlock()
+		return err
+	}
+	f, err := os.Open(src)
+	s.mu.RUnlock()
+	if err != nil {
+		return mapFSError(err)
+	}
+	defer f.Close()
+
+	tmp, etag, _, err := s.writeTemp(bucket, &ctxReader{ctx: ctx, r: f})
+	if err != nil {
+		return err
+	}
+
+	return s.commit(bucket, dstObject, tmp, fsMeta{
+		ContentType:  info.ContentType,
+		ETag:         etag,
+		UserMetadata: metadata,
+	})
+}
+
+func (s *FSStorage) RemoveObject(ctx context.Context, bucket string, objectName string) error {
+	path, err := s.objectPath(bucket, objectName)
+	if err != nil {
+		return err
+	}
+	metaPath, _ := s.metaPath(bucket, objectName)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// как и в S3, удаление отсутствующего объекта не считается ошибкой
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err := os.Remove(metaPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// ReadObject читает объект целиком вместе с его ETag.
+func (s *FSStorage) ReadObject(ctx context.Context, bucket, objectName string) ([]byte, string, error) {
+	path, err := s.objectPath(bucket, objectName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	info, err := s.stat(bucket, objectName)
+	if err != nil {
+		return nil, "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", mapFSError(err)
+	}
+	return data, info.ETag, nil
+}
+
+// CompareAndSwapObject записывает объект, если его ETag не изменился. ETag сверяется
+// под s.mu вместе с заменой файлов, поэтому условие соблюдается в пределах процесса.
+func (s *FSStorage) CompareAndSwapObject(ctx context.Context, bucket, objectName, etag string, data []byte) error {
+	if err := s.prepareBucket(bucket); err != nil {
+		return err
+	}
+
+	tmp, newETag, _, err := s.writeTemp(bucket, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	return s.commitIf(bucket, objectName, tmp, fsMeta{
+		ContentType: "application/octet-stream",
+		ETag:        newETag,
+	}, func() error {
+		info, err := s.stat(bucket, objectName)
+		switch {
+		case errors.Is(err, apperrors.ErrObjectNotFound):
+			if etag != "" {
+				return fmt.Errorf("%w: %s", apperrors.ErrObjectModified, objectName)
+			}
+			return nil
+		case err != nil:
+			return err
+		case info.ETag != etag:
+			return fmt.Errorf("%w: %s", apperrors.ErrObjectModified, objectName)
+		}
+		return nil
+	})
+}
+
+// NewMultipartUpload начинает multipart-загрузку и возвращает её uploadID.
+func (s *FSStorage) NewMultipartUpload(ctx context.Context, bucket, objectName, contentType string, metadata map[string]string) (string, error) {
+	if err := s.prepareBucket(bucket); err != nil {
+		return "", err
+	}
+	if _, err := s.objectPath(bucket, objectName); err != nil {
+		return "", err
+	}
+
+	uploadID := uuid.New().String()
+	dir := filepath.Join(s.root, bucket, fsUploadsDir, uploadID)
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(fsUpload{
+		Key:          objectName,
+		ContentType:  contentType,
+		UserMetadata: canonicalMetadata(metadata),
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(dir, fsUploadManifest), data, 0o644); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+
+	return uploadID, nil
+}
+
+// PutObjectPart сохраняет часть с номером partNumber (нумерация с 1) и возвращает её ETag.
+// Повторная загрузка части с тем же номером заменяет предыдущую.
+func (s *FSStorage) PutObjectPart(ctx context.Context, bucket, objectName, uploadID string, partNumber int, reader io.Reader, size int64) (string, error) {
+	dir, _, err := s.upload(bucket, objectName, uploadID)
+	if err != nil {
+		return "", err
+	}
+	if partNumber < 1 {
+		return "", fmt.Errorf("invalid part number %d", partNumber)
+	}
+
+	tmp, etag, n, err := s.writeTemp(bucket, &ctxReader{ctx: ctx, r: reader})
+	if err != nil {
+		return "", err
+	}
+	if size >= 0 && n != size {
+		os.Remove(tmp)
+		return "", fmt.Errorf("part size mismatch: expected %d bytes, got %d", size, n)
+	}
+
+	if err := os.Rename(tmp, filepath.Join(dir, partName(partNumber))); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	return etag, nil
+}
+
+// CompleteMultipartUpload собирает объект из частей; etags[i] соответствует части с номером i+1.
+func (s *FSStorage) CompleteMultipartUpload(ctx context.Context, bucket, objectName, uploadID string, etags []string) error {
+	dir, upload, err := s.upload(bucket, objectName, uploadID)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Join(s.root, bucket, fsTmpDir), "complete-*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+
+	objectHash := md5.New()
+	err = func() error {
+		defer f.Close()
+		for i, etag := range etags {
+			if err := appendPart(ctx, f, objectHash, filepath.Join(dir, partName(i+1)), etag); err != nil {
+				return err
+			}
+		}
+		return f.Sync()
+	}()
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	err = s.commit(bucket, objectName, tmp, fsMeta{
+		ContentType:  upload.ContentType,
+		ETag:         hex.EncodeToString(objectHash.Sum(nil)),
+		UserMetadata: upload.UserMetadata,
+	})
+	if err != nil {
+		return err
+	}
+
+	return os.RemoveAll(dir)
+}
+
+// AbortMultipartUpload отменяет загрузку и удаляет уже загруженные части.
+func (s *FSStorage) AbortMultipartUpload(ctx context.Context, bucket, objectName, uploadID string) error {
+	dir, _, err := s.upload(bucket, objectName, uploadID)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+// ListMultipartUploads возвращает незавершённые multipart-загрузки бакета. Время начала
+// загрузки - время записи её описания.
+func (s *FSStorage) ListMultipartUploads(ctx context.Context, bucket string) ([]models.MultipartUpload, error) {
+	if err := validateBucket(bucket); err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(filepath.Join(s.root, bucket, fsUploadsDir))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var uploads []models.MultipartUpload
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		manifest := filepath.Join(s.root, bucket, fsUploadsDir, entry.Name(), fsUploadManifest)
+		info, err := os.Stat(manifest)
+		if err != nil {
+			continue // загрузку только что завершили или отменили
+		}
+		data, err := os.ReadFile(manifest)
+		if err != nil {
+			continue
+		}
+		var upload fsUpload
+		if err := json.Unmarshal(data, &upload); err != nil {
+			continue
+		}
+		uploads = append(uploads, models.MultipartUpload{Key: upload.Key, UploadID: entry.Name(), Initiated: info.ModTime()})
+	}
+	return uploads, nil
+}
+
+// Handler отдаёт объекты по ссылкам из PresignedGetObject (поддерживает Range-запросы)
+// и принимает загрузки по формам из PresignedPostPolicy.
+func (s *FSStorage) Handler() http.Handler {
+	return http.HandlerFunc(s.serveObject)
+}
+
+// requestPath - путь запроса без пути publicURL. Шлюз может передать запрос как с префиксом,
+// так и уже без него.
+func (s *FSStorage) requestPath(r *http.Request) string {
+	if rest, ok := strings.CutPrefix(r.URL.Path, s.pathPrefix); ok && s.pathPrefix != "" && strings.HasPrefix(rest, "/") {
+		return rest
+	}
+	return r.URL.Path
+}
+
+func (s *FSStorage) serveObject(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		s.receiveObject(w, r)
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	bucket, objectName, ok := strings.Cut(strings.TrimPrefix(s.requestPath(r), "/"), "/")
+	if !ok || bucket == "" || objectName == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	query := r.URL.Query()
+	expires := query.Get("expires")
+	params := url.Values{}
+	for key := range responseHeaders {
+		if values, ok := query[key]; ok {
+			params[key] = values
+		}
+	}
+	signature, err := hex.DecodeString(query.Get("signature"))
+	if err != nil || !hmac.Equal(signature, s.signature(bucket, objectName, expires, params)) {
+		http.Error(w, "invalid signature", http.StatusForbidden)
+		return
+	}
+	deadline, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > deadline {
+		http.Error(w, "link expired", http.StatusForbidden)
+		return
+	}
+
+	path, err := s.objectPath(bucket, objectName)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.RLock()
+	info, err := s.stat(bucket, objectName)
+	var f *os.File
+	if err == nil {
+		f, err = os.Open(path)
+	}
+	s.mu.RUnlock()
+	if err != nil {
+		if errors.Is(err, apperrors.ErrObjectNotFound) || errors.Is(err, fs.ErrNotExist) {
+			http.NotFound(w, r)
+			return
+		}
+		http.Error(w, "storage failure", http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+
+	if info.ContentType != "" {
+		w.Header().Set("Content-Type", info.ContentType)
+	}
+	if info.ETag != "" {
+		w.Header().Set("ETag", `"`+info.ETag+`"`)
+	}
+	for key, header := range responseHeaders {
+		if value := params.Get(key); value != "" {
+			w.Header().Set(header, value)
+		}
+	}
+	http.ServeContent(w, r, "", info.LastModified, f)
+}
+
+// fsMaxFormField ограничивает размер текстовых полей формы загрузки.
+const fsMaxFormField = 8 << 10
+
+// receiveObject сохраняет объект из формы PresignedPostPolicy. Объект, не прошедший
+// проверку размера, не сохраняется; при успехе, как и S3, отвечает 204.
+func (s *FSStorage) receiveObject(w http.ResponseWriter, r *http.Request) {
+	bucket := strings.Trim(s.requestPath(r), "/")
+	if validateBucket(bucket) != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	mr, err := r.MultipartReader()
+	if err != nil {
+		http.Error(w, "multipart/form-data expected", http.StatusBadRequest)
+		return
+	}
+
+	fields := make(map[string]string)
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			http.Error(w, "file field is missing", http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			http.Error(w, "malformed form", http.StatusBadRequest)
+			return
+		}
+
+		if part.FormName() != "file" {
+			value, err := io.ReadAll(io.LimitReader(part, fsMaxFormField+1))
+			if err != nil || len(value) > fsMaxFormField {
+				http.Error(w, "malformed form field", http.StatusBadRequest)
+				return
+			}
+			fields[part.FormName()] = string(value)
+			continue
+		}
+
+		// поля после file не учитываются, как и в S3
+		policy, err := s.checkPostPolicy(bucket, fields)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		status, err := s.putPosted(r.Context(), policy, fields["Content-Type"], part)
+		if err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+}
+
+// checkPostPolicy проверяет подпись и срок политики и то, что поля формы ей соответствуют.
+func (s *FSStorage) checkPostPolicy(bucket string, fields map[string]string) (fsPostPolicy, error) {
+	signature, err := hex.DecodeString(fields["signature"])
+	if err != nil || strings.Contains(fields["policy"], "\n") || !hmac.Equal(signature, s.postSignature(fields["policy"])) {
+		return fsPostPolicy{}, errors.New("invalid signature")
+	}
+
+	var policy fsPostPolicy
+	data, err := base64.StdEncoding.DecodeString(fields["policy"])
+	if err == nil {
+		err = json.Unmarshal(data, &policy)
+	}
+	switch {
+	case err != nil:
+		return fsPostPolicy{}, errors.New("invalid policy")
+	case time.Now().Unix() > policy.Expires:
+		return fsPostPolicy{}, errors.New("policy expired")
+	case policy.Bucket != bucket || policy.Key != fields["key"]:
+		return fsPostPolicy{}, errors.New("key does not match policy")
+	case policy.ContentType != "" && policy.ContentType != fields["Content-Type"]:
+		return fsPostPolicy{}, errors.New("content type does not match policy")
+	}
+	for key, value := range policy.Metadata {
+		if fields[metadataField(key)] != value {
+			return fsPostPolicy{}, errors.New("metadata does not match policy")
+		}
+	}
+	return policy, nil
+}
+
+// putPosted сохраняет содержимое формы, если его размер укладывается в политику.
+// Возвращает HTTP-статус ошибки.
+func (s *FSStorage) putPosted(ctx context.Context, policy fsPostPolicy, contentType string, body io.Reader) (int, error) {
+	if err := s.prepareBucket(policy.Bucket); err != nil {
+		return http.StatusInternalServerError, errors.New("storage failure")
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	// на байт больше лимита, чтобы отличить слишком большой объект от объекта ровно в лимит
+	tmp, etag, n, err := s.writeTemp(policy.Bucket, &ctxReader{ctx: ctx, r: io.LimitReader(body, policy.MaxSize+1)})
+	if err != nil {
+		return http.StatusBadRequest, errors.New("failed to read file")
+	}
+	if n < policy.MinSize || n > policy.MaxSize {
+		os.Remove(tmp)
+		return http.StatusBadRequest, fmt.Errorf("file size must be between %d and %d bytes", policy.MinSize, policy.MaxSize)
+	}
+
+	err = s.commit(policy.Bucket, policy.Key, tmp, fsMeta{
+		ContentType:  contentType,
+		ETag:         etag,
+		UserMetadata: canonicalMetadata(policy.Metadata),
+	})
+	if err != nil {
+		return http.StatusInternalServerError, errors.New("storage failure")
+	}
+	return 0, nil
+}
+
+// responseHeaders - параметры ссылки, переопределяющие заголовки ответа, как в S3.
+var responseHeaders = map[string]string{
+	"response-content-type":        "Content-Type",
+	"response-content-disposition": "Content-Disposition",
+	"response-content-language":    "Content-Language",
+	"response-content-encoding":    "Content-Encoding",
+	"response-cache-control":       "Cache-Control",
+	"response-expires":             "Expires",
+}
+
+func (s *FSStorage) sign(bucket, objectName, expires string, params url.Values) string {
+	return hex.EncodeToString(s.signature(bucket, objectName, expires, params))
+}
+
+// signature покрывает параметры ответа; у ссылок без них подпись такая же, как раньше.
+func (s *FSStorage) signature(bucket, objectName, expires string, params url.Values) []byte {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(bucket + "\n" + objectName + "\n" + expires))
+	if len(params) > 0 {
+		mac.Write([]byte("\n" + params.Encode()))
+	}
+	return mac.Sum(nil)
+}
+
+// postSignature подписывает политику загрузки. Политика не содержит переводов строки,
+// а в подписи ссылок на скачивание их не меньше двух, поэтому одна подпись не подходит вместо другой.
+func (s *FSStorage) postSignature(policy string) []byte {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte("POST\n" + policy))
+	return mac.Sum(nil)
+}
+
+// stat читает сведения об объекте. Вызывается под s.mu.
+func (s *FSStorage) stat(bucket, objectName string) (models.ObjectInfo, error) {
+	path, err := s.objectPath(bucket, objectName)
+	if err != nil {
+		return models.ObjectInfo{}, err
+	}
+	metaPath, _ := s.metaPath(bucket, objectName)
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		return models.ObjectInfo{}, mapFSError(err)
+	}
+
+	var meta fsMeta
+	data, err := os.ReadFile(metaPath)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		// файл положили в каталог вручную - отдаём его без метаданных
+	case err != nil:
+		return models.ObjectInfo{}, err
+	default:
+		if err := json.Unmarshal(data, &meta); err != nil {
+			return models.ObjectInfo{}, fmt.Errorf("corrupted metadata of %s: %w", objectName, err)
+		}
+	}
+	if meta.UserMetadata == nil {
+		meta.UserMetadata = map[string]string{}
+	}
+
+	return models.ObjectInfo{
+		Key:          objectName,
+		Size:         fi.Size(),
+		LastModified: fi.ModTime(),
+		ContentType:  meta.ContentType,
+		ETag:         meta.ETag,
+		UserMetadata: meta.UserMetadata,
+	}, nil
+}
+
+func (s *FSStorage) listKeys(bucket, prefix, startAfter string) ([]string, error) {
+	if err := validateBucket(bucket); err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(filepath.Join(s.root, bucket, fsObjectsDir))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		key, err := unescapeKey(entry.Name())
+		if err != nil || !strings.HasPrefix(key, prefix) || key <= startAfter {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	// порядок экранированных имён не совпадает с порядком ключей
+	slices.Sort(keys)
+	return keys, nil
+}
+
+// writeTemp записывает поток во временный файл бакета и возвращает путь, MD5 и размер.
+func (s *FSStorage) writeTemp(bucket string, r io.Reader) (string, string, int64, error) {
+	f, err := os.CreateTemp(filepath.Join(s.root, bucket, fsTmpDir), "put-*")
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	objectHash := md5.New()
+	n, err := io.Copy(io.MultiWriter(f, objectHash), r)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", "", 0, err
+	}
+
+	return f.Name(), hex.EncodeToString(objectHash.Sum(nil)), n, nil
+}
+
+// commit атомарно заменяет объект содержимым tmp и записывает его метаданные.
+func (s *FSStorage) commit(bucket, objectName, tmp string, meta fsMeta) error {
+	return s.commitIf(bucket, objectName, tmp, meta, nil)
+}
+
+// commitIf - commit, который заменяет объект, только если check, вызванная под s.mu, не вернула ошибку.
+func (s *FSStorage) commitIf(bucket, objectName, tmp string, meta fsMeta, check func() error) error {
+	path, err := s.objectPath(bucket, objectName)
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	metaPath, _ := s.metaPath(bucket, objectName)
+
+	metaTmp, err := s.writeMetaTemp(bucket, meta)
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if check != nil {
+		if err := check(); err != nil {
+			os.Remove(tmp)
+			os.Remove(metaTmp)
+			return err
+		}
+	}
+	if err := os.Rename(metaTmp, metaPath); err != nil {
+		os.Remove(tmp)
+		os.Remove(metaTmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// replaceMetadata заменяет пользовательские метаданные объекта, не трогая содержимое.
+func (s *FSStorage) replaceMetadata(bucket, objectName string, metadata map[string]string) error {
+	metaPath, err := s.metaPath(bucket, objectName)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info, err := s.stat(bucket, objectName)
+	if err != nil {
+		return err
+	}
+
+	metaTmp, err := s.writeMetaTemp(bucket, fsMeta{
+		ContentType:  info.ContentType,
+		ETag:         info.ETag,
+		UserMetadata: metadata,
+	})
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(metaTmp, metaPath); err != nil {
+		os.Remove(metaTmp)
+		return err
+	}
+	return nil
+}
+
+func (s *FSStorage) writeMetaTemp(bucket string, meta fsMeta) (string, error) {
+	meta.UserMetadata = canonicalMetadata(meta.UserMetadata)
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.CreateTemp(filepath.Join(s.root, bucket, fsTmpDir), "meta-*")
+	if err != nil {
+		return "", err
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// upload проверяет, что загрузка uploadID существует и относится к objectName.
+func (s *FSStorage) upload(bucket, objectName, uploadID string) (string, fsUpload, error) {
+	if err := validateBucket(bucket); err != nil {
+		return "", fsUpload{}, err
+	}
+	if _, err := uuid.Parse(uploadID); err != nil {
+		return "", fsUpload{}, fmt.Errorf("upload %s not found", uploadID)
+	}
+
+	dir := filepath.Join(s.root, bucket, fsUploadsDir, uploadID)
+	data, err := os.ReadFile(filepath.Join(dir, fsUploadManifest))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", fsUpload{}, fmt.Errorf("upload %s not found", uploadID)
+		}
+		return "", fsUpload{}, err
+	}
+
+	var upload fsUpload
+	if err := json.Unmarshal(data, &upload); err != nil {
+		return "", fsUpload{}, fmt.Errorf("corrupted upload %s: %w", uploadID, err)
+	}
+	if upload.Key != objectName {
+		return "", fsUpload{}, fmt.Errorf("upload %s belongs to another object", uploadID)
+	}
+	return dir, upload, nil
+}
+
+func (s *FSStorage) prepareBucket(bucket string) error {
+	if err := validateBucket(bucket); err != nil {
+		return err
+	}
+	for _, dir := range []string{fsObjectsDir, fsMetaDir, fsUploadsDir, fsTmpDir} {
+		if err := os.MkdirAll(filepath.Join(s.root, bucket, dir), 0o755); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *FSStorage) objectPath(bucket, objectName string) (string, error) {
+	if err := validateBucket(bucket); err != nil {
+		return "", err
+	}
+	if objectName == "" {
+		return "", fmt.Errorf("%w: empty object name", apperrors.ErrObjectNotFound)
+	}
+	return filepath.Join(s.root, bucket, fsObjectsDir, escapeKey(objectName)), nil
+}
+
+func (s *FSStorage) metaPath(bucket, objectName string) (string, error) {
+	if err := validateBucket(bucket); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, bucket, fsMetaDir, escapeKey(objectName)+".json"), nil
+}
+
+func validateBucket(bucket string) error {
+	if bucket == "" || bucket != filepath.Base(bucket) || !filepath.IsLocal(bucket) {
+		return fmt.Errorf("invalid bucket name %q", bucket)
+	}
+	return nil
+}
+
+// escapeKey превращает ключ объекта в безопасное имя файла: всё, кроме букв, цифр и "-_.",
+// кодируется как %XX, ведущая точка тоже кодируется.
+func escapeKey(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if isSafeKeyByte(c) && !(i == 0 && c == '.') {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func unescapeKey(name string) (string, error) {
+	return url.PathUnescape(name)
+}
+
+func isSafeKeyByte(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.'
+}
+
+// metadataField - имя поля формы с пользовательскими метаданными, как в S3.
+func metadataField(key string) string {
+	return "x-amz-meta-" + strings.ToLower(key)
+}
+
+// canonicalMetadata приводит ключи метаданных к виду, в котором их возвращает MinIO.
+func canonicalMetadata(metadata map[string]string) map[string]string {
+	out := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		out[textproto.CanonicalMIMEHeaderKey(k)] = v
+	}
+	return out
+}
+
+func partName(partNumber int) string {
+	return fmt.Sprintf("%05d", partNumber)
+}
+
+// appendPart дописывает часть в w и проверяет её ETag.
+func appendPart(ctx context.Context, w io.Writer, objectHash hash.Hash, path, etag string) error {
+	part, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("part %s is missing: %w", filepath.Base(path), err)
+	}
+	defer part.Close()
+
+	partHash := md5.New()
+	if _, err := io.Copy(io.MultiWriter(w, objectHash, partHash), &ctxReader{ctx: ctx, r: part}); err != nil {
+		return err
+	}
+	if hex.EncodeToString(partHash.Sum(nil)) != etag {
+		return fmt.Errorf("part %s etag mismatch", filepath.Base(path))
+	}
+	return nil
+}
+
+func mapFSError(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %s", apperrors.ErrObjectNotFound, err.Error())
+	}
+	return err
+}
+
+// ctxReader прерывает чтение после отмены ctx.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *ctxReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
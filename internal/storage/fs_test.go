@@ -0,0 +1,282 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"maps"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/1abobik1/upload_file_service/internal/apperrors"
+	"github.com/1abobik1/upload_file_service/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFSStoragePutStatList(t *testing.T) {
+	s, err := NewFSStorage(t.TempDir(), "http://localhost", []byte("key"))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for _, key := range []string{"b.txt", "a-b.txt", "a/b.txt", ".trash/a.txt"} {
+		err := s.PutObject(ctx, "uploads", key, "text/plain", strings.NewReader(key), -1, map[string]string{"filename": key})
+		require.NoError(t, err)
+	}
+
+	info, err := s.StatObject(ctx, "uploads", "a/b.txt")
+	require.NoError(t, err)
+	require.Equal(t, "text/plain", info.ContentType)
+	require.Equal(t, int64(len("a/b.txt")), info.Size)
+	require.Equal(t, "a/b.txt", info.UserMetadata["Filename"])
+
+	_, err = s.StatObject(ctx, "uploads", "missing")
+	require.ErrorIs(t, err, apperrors.ErrObjectNotFound)
+
+	rc, err := s.GetObjectRange(ctx, "uploads", "a/b.txt", 2, 3)
+	require.NoError(t, err)
+	part, err := io.ReadAll(rc)
+	rc.Close()
+	require.NoError(t, err)
+	require.Equal(t, "b.t", string(part))
+
+	list := func(prefix, startAfter string) []string {
+		var keys []string
+		for obj := range s.ListObjects(ctx, "uploads", prefix, startAfter) {
+			require.NoError(t, obj.Err)
+			keys = append(keys, obj.Key)
+		}
+		return keys
+	}
+	require.Equal(t, []string{".trash/a.txt", "a-b.txt", "a/b.txt", "b.txt"}, list("", ""))
+	require.Equal(t, []string{"a/b.txt", "b.txt"}, list("", "a-b.txt"))
+	require.Equal(t, []string{".trash/a.txt"}, list(".trash/", ""))
+
+	require.NoError(t, s.RemoveObject(ctx, "uploads", "b.txt"))
+	require.NoError(t, s.RemoveObject(ctx, "uploads", "b.txt"))
+	_, err = s.GetObject(ctx, "uploads", "b.txt")
+	require.ErrorIs(t, err, apperrors.ErrObjectNotFound)
+}
+
+func TestFSStorageMultipartAndCopy(t *testing.T) {
+	s, err := NewFSStorage(t.TempDir(), "http://localhost", []byte("key"))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	uploadID, err := s.NewMultipartUpload(ctx, "uploads", "big.bin", "application/octet-stream", map[string]string{"Filename": "big.bin"})
+	require.NoError(t, err)
+
+	var etags []string
+	for _, part := range []string{"hello ", "world"} {
+		etag, err := s.PutObjectPart(ctx, "uploads", "big.bin", uploadID, len(etags)+1, strings.NewReader(part), int64(len(part)))
+		require.NoError(t, err)
+		etags = append(etags, etag)
+	}
+	require.NoError(t, s.CompleteMultipartUpload(ctx, "uploads", "big.bin", uploadID, etags))
+
+	require.NoError(t, s.CopyObject(ctx, "uploads", "big.bin", "copy.bin", map[string]string{"Filename": "copy.bin"}))
+
+	info, err := s.StatObject(ctx, "uploads", "copy.bin")
+	require.NoError(t, err)
+	require.Equal(t, "application/octet-stream", info.ContentType)
+	require.Equal(t, map[string]string{"Filename": "copy.bin"}, info.UserMetadata)
+
+	obj, err := s.GetObject(ctx, "uploads", "copy.bin")
+	require.NoError(t, err)
+	defer obj.Close()
+	data, err := io.ReadAll(obj)
+	require.NoError(t, err)
+	require.Equal(t, "hello world", string(data))
+
+	// после завершения загрузка больше не существует
+	require.Error(t, s.AbortMultipartUpload(ctx, "uploads", "big.bin", uploadID))
+}
+
+func TestFSStorageCompareAndSwap(t *testing.T) {
+	s, err := NewFSStorage(t.TempDir(), "http://localhost", []byte("key"))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	// пустой etag - объекта ещё не должно быть
+	require.NoError(t, s.CompareAndSwapObject(ctx, "uploads", "counter", "", []byte("1")))
+	err = s.CompareAndSwapObject(ctx, "uploads", "counter", "", []byte("1"))
+	require.ErrorIs(t, err, apperrors.ErrObjectModified)
+
+	data, etag, err := s.ReadObject(ctx, "uploads", "counter")
+	require.NoError(t, err)
+	require.Equal(t, "1", string(data))
+
+	require.NoError(t, s.CompareAndSwapObject(ctx, "uploads", "counter", etag, []byte("2")))
+	// запись по устаревшему ETag отклоняется и не меняет объект
+	err = s.CompareAndSwapObject(ctx, "uploads", "counter", etag, []byte("3"))
+	require.ErrorIs(t, err, apperrors.ErrObjectModified)
+
+	data, _, err = s.ReadObject(ctx, "uploads", "counter")
+	require.NoError(t, err)
+	require.Equal(t, "2", string(data))
+
+	_, _, err = s.ReadObject(ctx, "uploads", "missing")
+	require.ErrorIs(t, err, apperrors.ErrObjectNotFound)
+}
+
+func TestFSStoragePresignedLink(t *testing.T) {
+	srv := httptest.NewServer(nil)
+	defer srv.Close()
+
+	s, err := NewFSStorage(t.TempDir(), srv.URL, []byte("key"))
+	require.NoError(t, err)
+	srv.Config.Handler = s.Handler()
+
+	ctx := context.Background()
+	require.NoError(t, s.PutObject(ctx, "uploads", "photo 1.png", "image/png", strings.NewReader("png"), 3, nil))
+
+	get := func(link string) (int, string) {
+		resp, err := http.Get(link)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(body)
+	}
+
+	link, err := s.PresignedGetObject(ctx, "uploads", "photo 1.png", time.Minute, nil)
+	require.NoError(t, err)
+	status, body := get(link.String())
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "png", body)
+
+	tampered := *link
+	tampered.Path = "/uploads/other.png"
+	status, _ = get(tampered.String())
+	require.Equal(t, http.StatusForbidden, status)
+
+	params := url.Values{"response-content-disposition": {`attachment; filename="photo.png"`}}
+	withParams, err := s.PresignedGetObject(ctx, "uploads", "photo 1.png", time.Minute, params)
+	require.NoError(t, err)
+	resp, err := http.Get(withParams.String())
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, `attachment; filename="photo.png"`, resp.Header.Get("Content-Disposition"))
+
+	// параметры ответа подписаны вместе со ссылкой
+	query := withParams.Query()
+	query.Set("response-content-type", "text/html")
+	tampered.Path = withParams.Path
+	tampered.RawQuery = query.Encode()
+	status, _ = get(tampered.String())
+	require.Equal(t, http.StatusForbidden, status)
+
+	expired, err := s.PresignedGetObject(ctx, "uploads", "photo 1.png", -time.Minute, nil)
+	require.NoError(t, err)
+	status, _ = get(expired.String())
+	require.Equal(t, http.StatusForbidden, status)
+}
+
+func TestFSStoragePresignedPost(t *testing.T) {
+	srv := httptest.NewServer(nil)
+	defer srv.Close()
+
+	s, err := NewFSStorage(t.TempDir(), srv.URL, []byte("key"))
+	require.NoError(t, err)
+	srv.Config.Handler = s.Handler()
+
+	ctx := context.Background()
+	post := func(link *url.URL, fields map[string]string, content string) int {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		for name, value := range fields {
+			require.NoError(t, form.WriteField(name, value))
+		}
+		file, err := form.CreateFormFile("file", "upload.txt")
+		require.NoError(t, err)
+		file.Write([]byte(content))
+		require.NoError(t, form.Close())
+
+		resp, err := http.Post(link.String(), form.FormDataContentType(), &body)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	policy := models.UploadPolicy{ContentType: "text/plain", MinSize: 1, MaxSize: 5, Metadata: map[string]string{"Filename": "a.txt"}}
+	link, fields, err := s.PresignedPostPolicy(ctx, "uploads", ".pending/a.txt", time.Minute, policy)
+	require.NoError(t, err)
+
+	require.Equal(t, http.StatusBadRequest, post(link, fields, "too long"))
+	_, err = s.StatObject(ctx, "uploads", ".pending/a.txt")
+	require.ErrorIs(t, err, apperrors.ErrObjectNotFound)
+
+	tampered := maps.Clone(fields)
+	tampered["Content-Type"] = "text/html"
+	require.Equal(t, http.StatusForbidden, post(link, tampered, "hello"))
+	tampered = maps.Clone(fields)
+	tampered["key"] = "other.txt"
+	require.Equal(t, http.StatusForbidden, post(link, tampered, "hello"))
+	tampered = maps.Clone(fields)
+	tampered["x-amz-meta-filename"] = "b.txt"
+	require.Equal(t, http.StatusForbidden, post(link, tampered, "hello"))
+
+	require.Equal(t, http.StatusNoContent, post(link, fields, "hello"))
+	info, err := s.StatObject(ctx, "uploads", ".pending/a.txt")
+	require.NoError(t, err)
+	require.Equal(t, "text/plain", info.ContentType)
+	require.EqualValues(t, 5, info.Size)
+	require.Equal(t, "a.txt", info.UserMetadata["Filename"])
+
+	link, fields, err = s.PresignedPostPolicy(ctx, "uploads", ".pending/b.txt", -time.Minute, policy)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusForbidden, post(link, fields, "hello"))
+}
+
+func TestFSStoragePublicURLPrefix(t *testing.T) {
+	srv := httptest.NewServer(nil)
+	defer srv.Close()
+
+	s, err := NewFSStorage(t.TempDir(), srv.URL+"/files/", []byte("key"))
+	require.NoError(t, err)
+	srv.Config.Handler = s.Handler()
+
+	ctx := context.Background()
+	require.NoError(t, s.PutObject(ctx, "uploads", "a.txt", "text/plain", strings.NewReader("abc"), 3, nil))
+
+	link, err := s.PresignedGetObject(ctx, "uploads", "a.txt", time.Minute, nil)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(link.Path, "/files/uploads/"), link.Path)
+
+	resp, err := http.Get(link.String())
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "abc", string(body))
+
+	// шлюз, который сам убирает префикс, тоже поддерживается
+	stripped := *link
+	stripped.Path = strings.TrimPrefix(link.Path, "/files")
+	resp, err = http.Get(stripped.String())
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	postURL, fields, err := s.PresignedPostPolicy(ctx, "uploads", "b.txt", time.Minute, models.UploadPolicy{MaxSize: 10})
+	require.NoError(t, err)
+	var form bytes.Buffer
+	w := multipart.NewWriter(&form)
+	for name, value := range fields {
+		require.NoError(t, w.WriteField(name, value))
+	}
+	file, err := w.CreateFormFile("file", "b.txt")
+	require.NoError(t, err)
+	file.Write([]byte("b"))
+	require.NoError(t, w.Close())
+	resp, err = http.Post(postURL.String(), w.FormDataContentType(), &form)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+}
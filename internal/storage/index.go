@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/1abobik1/upload_file_service/internal/models"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	filesBucket = []byte("files")
+	metaBucket  = []byte("meta")
+	builtKey    = []byte("built_at")
+)
+
+// BoltIndex - локальный индекс метаданных файлов в bbolt. Ключи упорядочены так же,
+// как ключи объектов в MinIO, поэтому постраничный обход идёт в том же порядке.
+// Файл базы блокируется одним процессом, поэтому индекс рассчитан на один экземпляр сервиса.
+type BoltIndex struct {
+	db *bolt.DB
+}
+
+func NewBoltIndex(path string) (*BoltIndex, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(filesBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(metaBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &BoltIndex{db: db}, nil
+}
+
+func (i *BoltIndex) Close() error {
+	return i.db.Close()
+}
+
+func (i *BoltIndex) Put(_ context.Context, rec models.FileRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return i.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(filesBucket).Put([]byte(rec.FileID), data)
+	})
+}
+
+// Get возвращает запись и false, если файла в индексе нет.
+func (i *BoltIndex) Get(_ context.Context, fileID string) (models.FileRecord, bool, error) {
+	var rec models.FileRecord
+	var found bool
+
+	err := i.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(filesBucket).Get([]byte(fileID))
+		if data == nil {
+			return nil
+		}
+		found = true
+		return json.Unmarshal(data, &rec)
+	})
+	return rec, found, err
+}
+
+func (i *BoltIndex) Delete(_ context.Context, fileID string) error {
+	return i.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(filesBucket).Delete([]byte(fileID))
+	})
+}
+
+// List обходит записи в порядке ключей, начиная после startAfter, пока fn возвращает true.
+func (i *BoltIndex) List(ctx context.Context, startAfter string, fn func(models.FileRecord) bool) error {
+	return i.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(filesBucket).Cursor()
+
+		k, v := c.First()
+		if startAfter != "" {
+			k, v = c.Seek([]byte(startAfter))
+			if k != nil && string(k) == startAfter {
+				k, v = c.Next()
+			}
+		}
+
+		for ; k != nil; k, v = c.Next() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			var rec models.FileRecord
+			if err := json.Unmarshal(v, &rec); err != nil {
+				return err
+			}
+			if !fn(rec) {
+				return nil
+			}
+		}
+		return nil
+	})
+}
+
+// Rebuild атомарно заменяет содержимое индекса записями, которые fill передаёт в put.
+// Пока идёт перестройка, читатели видят прежнее содержимое.
+func (i *BoltIndex) Rebuild(_ context.Context, fill func(put func(models.FileRecord) error) error) error {
+	return i.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(filesBucket); err != nil {
+			return err
+		}
+		b, err := tx.CreateBucket(filesBucket)
+		if err != nil {
+			return err
+		}
+
+		err = fill(func(rec models.FileRecord) error {
+			data, err := json.Marshal(rec)
+			if err != nil {
+				return err
+			}
+			return b.Put([]byte(rec.FileID), data)
+		})
+		if err != nil {
+			return err
+		}
+
+		builtAt, err := time.Now().MarshalText()
+		if err != nil {
+			return err
+		}
+		return tx.Bucket(metaBucket).Put(builtKey, builtAt)
+	})
+}
+
+// Built сообщает, строился ли индекс хотя бы раз. Пустой новый индекс нужно сначала перестроить,
+// иначе файлы, загруженные до его появления, не попадут в листинг.
+func (i *BoltIndex) Built(_ context.Context) (bool, error) {
+	var built bool
+	err := i.db.View(func(tx *bolt.Tx) error {
+		built = tx.Bucket(metaBucket).Get(builtKey) != nil
+		return nil
+	})
+	return built, err
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/1abobik1/upload_file_service/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoltIndexListStartAfter(t *testing.T) {
+	index, err := NewBoltIndex(filepath.Join(t.TempDir(), "index.db"))
+	require.NoError(t, err)
+	defer index.Close()
+
+	ctx := context.Background()
+	for _, id := range []string{"c", "a", "b", "d"} {
+		require.NoError(t, index.Put(ctx, models.FileRecord{FileID: id, Filename: id + ".txt"}))
+	}
+
+	list := func(startAfter string, limit int) []string {
+		var ids []string
+		err := index.List(ctx, startAfter, func(rec models.FileRecord) bool {
+			ids = append(ids, rec.FileID)
+			return len(ids) < limit
+		})
+		require.NoError(t, err)
+		return ids
+	}
+
+	require.Equal(t, []string{"a", "b", "c", "d"}, list("", 10))
+	require.Equal(t, []string{"c", "d"}, list("b", 10))
+	// ключа "bb" нет - обход начинается со следующего за ним
+	require.Equal(t, []string{"c"}, list("bb", 1))
+	require.Empty(t, list("d", 10))
+}
+
+func TestBoltIndexRebuild(t *testing.T) {
+	index, err := NewBoltIndex(filepath.Join(t.TempDir(), "index.db"))
+	require.NoError(t, err)
+	defer index.Close()
+
+	ctx := context.Background()
+	require.NoError(t, index.Put(ctx, models.FileRecord{FileID: "stale"}))
+
+	built, err := index.Built(ctx)
+	require.NoError(t, err)
+	require.False(t, built)
+
+	err = index.Rebuild(ctx, func(put func(models.FileRecord) error) error {
+		return put(models.FileRecord{FileID: "fresh", Size: 42})
+	})
+	require.NoError(t, err)
+
+	built, err = index.Built(ctx)
+	require.NoError(t, err)
+	require.True(t, built)
+
+	_, found, err := index.Get(ctx, "stale")
+	require.NoError(t, err)
+	require.False(t, found)
+
+	rec, found, err := index.Get(ctx, "fresh")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, int64(42), rec.Size)
+}
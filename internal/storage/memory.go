@@ -0,0 +1,348 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"maps"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/1abobik1/upload_file_service/internal/apperrors"
+	"github.com/1abobik1/upload_file_service/internal/models"
+	"github.com/google/uuid"
+)
+
+// MemoryStorage хранит объекты в памяти процесса и повторяет поведение MinIOStorage:
+// метаданные, время изменения, ошибку отсутствия объекта, листинг в порядке ключей.
+// Presigned-ссылки ненастоящие и никуда не ведут. Предназначено для тестов.
+type MemoryStorage struct {
+	mu      sync.RWMutex
+	buckets map[string]map[string]*memObject
+	uploads map[string]*memUpload
+}
+
+// memObject не меняется после сохранения: перезапись заменяет объект целиком.
+type memObject struct {
+	data         []byte
+	contentType  string
+	etag         string
+	metadata     map[string]string
+	lastModified time.Time
+}
+
+type memUpload struct {
+	bucket      string
+	key         string
+	contentType string
+	metadata    map[string]string
+	parts       map[int][]byte
+	initiated   time.Time
+}
+
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		buckets: make(map[string]map[string]*memObject),
+		uploads: make(map[string]*memUpload),
+	}
+}
+
+func (s *MemoryStorage) PutObject(ctx context.Context, bucket, objectName, contentType string, reader io.Reader, objectSize int64, metadata map[string]string) error {
+	data, err := io.ReadAll(&ctxReader{ctx: ctx, r: reader})
+	if err != nil {
+		return err
+	}
+	if objectSize >= 0 && int64(len(data)) != objectSize {
+		return fmt.Errorf("object size mismatch: expected %d bytes, got %d", objectSize, len(data))
+	}
+
+	s.put(bucket, objectName, data, contentType, metadata)
+	return nil
+}
+
+func (s *MemoryStorage) GetObject(ctx context.Context, bucket string, objectName string) (io.ReadCloser, error) {
+	obj, err := s.get(bucket, objectName)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(obj.data)), nil
+}
+
+// GetObjectRange читает length байт объекта начиная с offset; length <= 0 - до конца объекта.
+func (s *MemoryStorage) GetObjectRange(ctx context.Context, bucket, objectName string, offset, length int64) (io.ReadCloser, error) {
+	obj, err := s.get(bucket, objectName)
+	if err != nil {
+		return nil, err
+	}
+
+	data := obj.data[min(offset, int64(len(obj.data))):]
+	if length > 0 && length < int64(len(data)) {
+		data = data[:length]
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *MemoryStorage) StatObject(ctx context.Context, bucket string, objectName string) (models.ObjectInfo, error) {
+	obj, err := s.get(bucket, objectName)
+	if err != nil {
+		return models.ObjectInfo{}, err
+	}
+	return obj.info(objectName), nil
+}
+
+// ListObjects возвращает снимок объектов с ключами после startAfter в порядке ключей.
+func (s *MemoryStorage) ListObjects(ctx context.Context, bucket string, prefix string, startAfter string) <-chan models.ObjectInfo {
+	s.mu.RLock()
+	var infos []models.ObjectInfo
+	for key, obj := range s.buckets[bucket] {
+		if strings.HasPrefix(key, prefix) && key > startAfter {
+			infos = append(infos, obj.info(key))
+		}
+	}
+	s.mu.RUnlock()
+
+	slices.SortFunc(infos, func(a, b models.ObjectInfo) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	out := make(chan models.ObjectInfo, 1)
+	go func() {
+		defer close(out)
+		for _, info := range infos {
+			select {
+			case out <- info:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// PresignedGetObject возвращает ссылку вида memory://<bucket>/<key>?expires=<unix>&<reqParams>.
+func (s *MemoryStorage) PresignedGetObject(ctx context.Context, bucket string, objectName string, expiry time.Duration, reqParams url.Values) (*url.URL, error) {
+	query := url.Values{"expires": {strconv.FormatInt(time.Now().Add(expiry).Unix(), 10)}}
+	for key, values := range reqParams {
+		query[key] = values
+	}
+	return &url.URL{
+		Scheme:   "memory",
+		Host:     bucket,
+		Path:     "/" + objectName,
+		RawQuery: query.Encode(),
+	}, nil
+}
+
+// PresignedPostPolicy возвращает адрес вида memory://<bucket>/ и поля формы с ключом,
+// ограничениями и метаданными; загрузку в тестах имитирует PutObject.
+func (s *MemoryStorage) PresignedPostPolicy(ctx context.Context, bucket, objectName string, expiry time.Duration, policy models.UploadPolicy) (*url.URL, map[string]string, error) {
+	fields := map[string]string{
+		"key":      objectName,
+		"expires":  strconv.FormatInt(time.Now().Add(expiry).Unix(), 10),
+		"min-size": strconv.FormatInt(policy.MinSize, 10),
+		"max-size": strconv.FormatInt(policy.MaxSize, 10),
+	}
+	if policy.ContentType != "" {
+		fields["Content-Type"] = policy.ContentType
+	}
+	for key, value := range policy.Metadata {
+		fields[metadataField(key)] = value
+	}
+	return &url.URL{Scheme: "memory", Host: bucket, Path: "/"}, fields, nil
+}
+
+// CopyObject копирует объект, заменяя пользовательские метаданные на metadata.
+// Content-Type исходного объекта сохраняется.
+func (s *MemoryStorage) CopyObject(ctx context.Context, bucket, srcObject, dstObject string, metadata map[string]string) error {
+	src, err := s.get(bucket, srcObject)
+	if err != nil {
+		return err
+	}
+	s.put(bucket, dstObject, src.data, src.contentType, metadata)
+	return nil
+}
+
+func (s *MemoryStorage) RemoveObject(ctx context.Context, bucket string, objectName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.buckets[bucket], objectName)
+	return nil
+}
+
+func (s *MemoryStorage) ReadObject(ctx context.Context, bucket, objectName string) ([]byte, string, error) {
+	obj, err := s.get(bucket, objectName)
+	if err != nil {
+		return nil, "", err
+	}
+	return obj.data, obj.etag, nil
+}
+
+func (s *MemoryStorage) CompareAndSwapObject(ctx context.Context, bucket, objectName, etag string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.buckets[bucket][objectName]
+	if (ok && current.etag != etag) || (!ok && etag != "") {
+		return fmt.Errorf("%w: %s/%s", apperrors.ErrObjectModified, bucket, objectName)
+	}
+
+	if s.buckets[bucket] == nil {
+		s.buckets[bucket] = make(map[string]*memObject)
+	}
+	s.buckets[bucket][objectName] = &memObject{
+		data:         bytes.Clone(data),
+		contentType:  "application/octet-stream",
+		etag:         md5Hex(data),
+		metadata:     map[string]string{},
+		lastModified: time.Now(),
+	}
+	return nil
+}
+
+func (s *MemoryStorage) NewMultipartUpload(ctx context.Context, bucket, objectName, contentType string, metadata map[string]string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	uploadID := uuid.New().String()
+	s.uploads[uploadID] = &memUpload{
+		bucket:      bucket,
+		key:         objectName,
+		contentType: contentType,
+		metadata:    canonicalMetadata(metadata),
+		parts:       make(map[int][]byte),
+		initiated:   time.Now(),
+	}
+	return uploadID, nil
+}
+
+func (s *MemoryStorage) PutObjectPart(ctx context.Context, bucket, objectName, uploadID string, partNumber int, reader io.Reader, size int64) (string, error) {
+	data, err := io.ReadAll(&ctxReader{ctx: ctx, r: reader})
+	if err != nil {
+		return "", err
+	}
+	if size >= 0 && int64(len(data)) != size {
+		return "", fmt.Errorf("part size mismatch: expected %d bytes, got %d", size, len(data))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	upload, err := s.upload(bucket, objectName, uploadID)
+	if err != nil {
+		return "", err
+	}
+	upload.parts[partNumber] = data
+	return md5Hex(data), nil
+}
+
+// CompleteMultipartUpload собирает объект из частей; etags[i] соответствует части с номером i+1.
+func (s *MemoryStorage) CompleteMultipartUpload(ctx context.Context, bucket, objectName, uploadID string, etags []string) error {
+	s.mu.Lock()
+	upload, err := s.upload(bucket, objectName, uploadID)
+	if err != nil {
+		s.mu.Unlock()
+		return err
+	}
+
+	var data []byte
+	for i, etag := range etags {
+		part, ok := upload.parts[i+1]
+		if !ok || md5Hex(part) != etag {
+			s.mu.Unlock()
+			return fmt.Errorf("invalid part %d of upload %s", i+1, uploadID)
+		}
+		data = append(data, part...)
+	}
+	delete(s.uploads, uploadID)
+	s.mu.Unlock()
+
+	s.put(bucket, objectName, data, upload.contentType, upload.metadata)
+	return nil
+}
+
+func (s *MemoryStorage) AbortMultipartUpload(ctx context.Context, bucket, objectName, uploadID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.upload(bucket, objectName, uploadID); err != nil {
+		return err
+	}
+	delete(s.uploads, uploadID)
+	return nil
+}
+
+func (s *MemoryStorage) ListMultipartUploads(ctx context.Context, bucket string) ([]models.MultipartUpload, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var uploads []models.MultipartUpload
+	for id, upload := range s.uploads {
+		if upload.bucket == bucket {
+			uploads = append(uploads, models.MultipartUpload{Key: upload.key, UploadID: id, Initiated: upload.initiated})
+		}
+	}
+	return uploads, nil
+}
+
+func (s *MemoryStorage) put(bucket, objectName string, data []byte, contentType string, metadata map[string]string) {
+	obj := &memObject{
+		data:         data,
+		contentType:  contentType,
+		etag:         md5Hex(data),
+		metadata:     canonicalMetadata(metadata),
+		lastModified: time.Now(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.buckets[bucket] == nil {
+		s.buckets[bucket] = make(map[string]*memObject)
+	}
+	s.buckets[bucket][objectName] = obj
+}
+
+func (s *MemoryStorage) get(bucket, objectName string) (*memObject, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	obj, ok := s.buckets[bucket][objectName]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s/%s", apperrors.ErrObjectNotFound, bucket, objectName)
+	}
+	return obj, nil
+}
+
+// upload вызывается под s.mu.
+func (s *MemoryStorage) upload(bucket, objectName, uploadID string) (*memUpload, error) {
+	upload, ok := s.uploads[uploadID]
+	if !ok || upload.bucket != bucket || upload.key != objectName {
+		return nil, fmt.Errorf("upload %s not found", uploadID)
+	}
+	return upload, nil
+}
+
+func (o *memObject) info(key string) models.ObjectInfo {
+	return models.ObjectInfo{
+		Key:          key,
+		Size:         int64(len(o.data)),
+		LastModified: o.lastModified,
+		ContentType:  o.contentType,
+		ETag:         o.etag,
+		// копия: вызывающий код может менять метаданные перед перезаписью
+		UserMetadata: maps.Clone(o.metadata),
+	}
+}
+
+func md5Hex(data []byte) string {
+	sum := md5.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
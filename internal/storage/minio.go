@@ -1,15 +1,28 @@
 package storage
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 	"io"
+	"net/textproto"
 	"net/url"
+	"strings"
 	"time"
 
+	"github.com/1abobik1/upload_file_service/internal/apperrors"
+	"github.com/1abobik1/upload_file_service/internal/models"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
 )
 
+// putPartSize - размер части multipart-загрузки, когда размер объекта заранее неизвестен.
+// Столько памяти minio-клиент буферизует на одну загрузку; 10000 частей дают лимит ~160GB на объект.
+const putPartSize = 16 << 20
+
+// userMetaPrefix - префикс пользовательских метаданных в заголовках S3
+const userMetaPrefix = "X-Amz-Meta-"
+
 type MinIOStorage struct {
 	Client *minio.Client
 	Bucket string
@@ -54,17 +67,24 @@ func NewMinIOStorage(endpoint, accessKey, secretKey, bucket string, useSSL bool)
 	}, nil
 }
 
+// PutObject загружает объект. При objectSize < 0 данные читаются потоком и отправляются
+// multipart-загрузкой частями по putPartSize.
 func (s *MinIOStorage) PutObject(ctx context.Context, bucket, objectName, contentType string, reader io.Reader, objectSize int64, metadata map[string]string) error {
+	opts := minio.PutObjectOptions{
+		ContentType:  contentType,
+		UserMetadata: metadata,
+	}
+	if objectSize < 0 {
+		opts.PartSize = putPartSize
+	}
+
 	_, err := s.Client.PutObject(
 		ctx,
 		bucket,
 		objectName,
 		reader,
 		objectSize,
-		minio.PutObjectOptions{
-			ContentType:  contentType,
-			UserMetadata: metadata,
-		},
+		opts,
 	)
 	return err
 }
@@ -77,19 +97,44 @@ func (s *MinIOStorage) GetObject(ctx context.Context, bucket string, objectName
 		minio.GetObjectOptions{},
 	)
 	if err != nil {
-		return nil, err
+		return nil, mapError(err)
 	}
 
 	// Проверка существования объекта
 	if _, err := obj.Stat(); err != nil {
 		obj.Close()
-		return nil, err
+		return nil, mapError(err)
+	}
+
+	return obj, nil
+}
+
+// GetObjectRange читает length байт объекта начиная с offset; length <= 0 - до конца объекта.
+func (s *MinIOStorage) GetObjectRange(ctx context.Context, bucket, objectName string, offset, length int64) (io.ReadCloser, error) {
+	opts := minio.GetObjectOptions{}
+	end := int64(0)
+	if length > 0 {
+		end = offset + length - 1
+	}
+	if offset > 0 || end > 0 {
+		if err := opts.SetRange(offset, end); err != nil {
+			return nil, err
+		}
+	}
+
+	obj, err := s.Client.GetObject(ctx, bucket, objectName, opts)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	if _, err := obj.Stat(); err != nil {
+		obj.Close()
+		return nil, mapError(err)
 	}
 
 	return obj, nil
 }
 
-func (s *MinIOStorage) StatObject(ctx context.Context, bucket string, objectName string) (map[string]string, time.Time, int64, error) {
+func (s *MinIOStorage) StatObject(ctx context.Context, bucket string, objectName string) (models.ObjectInfo, error) {
 	info, err := s.Client.StatObject(
 		ctx,
 		bucket,
@@ -97,27 +142,277 @@ func (s *MinIOStorage) StatObject(ctx context.Context, bucket string, objectName
 		minio.StatObjectOptions{},
 	)
 	if err != nil {
-		return nil, time.Time{}, 0, err
+		return models.ObjectInfo{}, mapError(err)
 	}
-	return info.UserMetadata, info.LastModified, info.Size, nil
+	return toObjectInfo(info), nil
 }
 
-func (s *MinIOStorage) ListObjects(ctx context.Context, bucket string) <-chan minio.ObjectInfo {
-	return s.Client.ListObjects(
+// ListObjects возвращает объекты в порядке ключей, начиная после startAfter, вместе с
+// пользовательскими метаданными и Content-Type. MinIO отдаёт их прямо в листинге,
+// StatObject вызывается только если хранилище не поддерживает листинг с метаданными.
+func (s *MinIOStorage) ListObjects(ctx context.Context, bucket string, prefix string, startAfter string) <-chan models.ObjectInfo {
+	objects := s.Client.ListObjects(
 		ctx,
 		bucket,
 		minio.ListObjectsOptions{
-			Recursive: true,
+			Prefix:       prefix,
+			StartAfter:   startAfter,
+			Recursive:    true,
+			WithMetadata: true,
 		},
 	)
+
+	out := make(chan models.ObjectInfo, 1)
+	go func() {
+		defer close(out)
+		for obj := range objects {
+			if obj.Err == nil {
+				obj = s.withUserMetadata(ctx, bucket, obj)
+			}
+			select {
+			case out <- toObjectInfo(obj):
+			case <-ctx.Done():
+				// дочитываем канал, чтобы горутина minio-клиента завершилась
+				for range objects {
+				}
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// withUserMetadata приводит метаданные из листинга к виду, который возвращает StatObject.
+func (s *MinIOStorage) withUserMetadata(ctx context.Context, bucket string, obj minio.ObjectInfo) minio.ObjectInfo {
+	if obj.UserMetadata == nil {
+		info, err := s.Client.StatObject(ctx, bucket, obj.Key, minio.StatObjectOptions{})
+		if err != nil {
+			obj.Err = mapError(err)
+			return obj
+		}
+		obj.UserMetadata = info.UserMetadata
+		obj.ContentType = info.ContentType
+		return obj
+	}
+
+	metadata := make(minio.StringMap, len(obj.UserMetadata))
+	for k, v := range obj.UserMetadata {
+		switch {
+		case strings.EqualFold(k, "Content-Type"):
+			if obj.ContentType == "" {
+				obj.ContentType = v
+			}
+		case len(k) > len(userMetaPrefix) && strings.EqualFold(k[:len(userMetaPrefix)], userMetaPrefix):
+			metadata[textproto.CanonicalMIMEHeaderKey(k[len(userMetaPrefix):])] = v
+		default:
+			metadata[textproto.CanonicalMIMEHeaderKey(k)] = v
+		}
+	}
+	obj.UserMetadata = metadata
+	return obj
 }
 
-func (s *MinIOStorage) PresignedGetObject(ctx context.Context, bucket string, objectName string, expiry time.Duration) (*url.URL, error) {
+// PresignedGetObject возвращает ссылку на скачивание. reqParams - параметры ответа S3
+// (response-content-type, response-content-disposition и т.д.), подписываются вместе со ссылкой.
+func (s *MinIOStorage) PresignedGetObject(ctx context.Context, bucket string, objectName string, expiry time.Duration, reqParams url.Values) (*url.URL, error) {
 	return s.Client.PresignedGetObject(
 		ctx,
 		bucket,
 		objectName,
 		expiry,
-		nil,
+		reqParams,
 	)
 }
+
+// PresignedPostPolicy возвращает адрес и поля формы для загрузки объекта POST-запросом
+// напрямую в MinIO. Размер, Content-Type и метаданные проверяет MinIO по подписанной политике.
+func (s *MinIOStorage) PresignedPostPolicy(ctx context.Context, bucket, objectName string, expiry time.Duration, policy models.UploadPolicy) (*url.URL, map[string]string, error) {
+	p := minio.NewPostPolicy()
+	if err := p.SetBucket(bucket); err != nil {
+		return nil, nil, err
+	}
+	if err := p.SetKey(objectName); err != nil {
+		return nil, nil, err
+	}
+	if err := p.SetExpires(time.Now().UTC().Add(expiry)); err != nil {
+		return nil, nil, err
+	}
+	if err := p.SetContentLengthRange(policy.MinSize, policy.MaxSize); err != nil {
+		return nil, nil, err
+	}
+	if policy.ContentType != "" {
+		if err := p.SetContentType(policy.ContentType); err != nil {
+			return nil, nil, err
+		}
+	}
+	for key, value := range policy.Metadata {
+		if err := p.SetUserMetadata(key, value); err != nil {
+			return nil, nil, err
+		}
+	}
+	return s.Client.PresignedPostPolicy(ctx, p)
+}
+
+// CopyObject копирует объект на стороне хранилища, заменяя пользовательские метаданные
+// на metadata. Content-Type исходного объекта сохраняется, объекты больше 5GB копируются по частям.
+// Копия объекта в самого себя - одиночный CopyObject с заменой метаданных, который MinIO
+// выполняет без перезаписи содержимого, какого бы размера ни был объект.
+func (s *MinIOStorage) CopyObject(ctx context.Context, bucket, srcObject, dstObject string, metadata map[string]string) error {
+	src, err := s.Client.StatObject(ctx, bucket, srcObject, minio.StatObjectOptions{})
+	if err != nil {
+		return mapError(err)
+	}
+
+	userMetadata := make(map[string]string, len(metadata)+1)
+	for k, v := range metadata {
+		userMetadata[k] = v
+	}
+	userMetadata["Content-Type"] = src.ContentType
+
+	dst := minio.CopyDestOptions{
+		Bucket:          bucket,
+		Object:          dstObject,
+		UserMetadata:    userMetadata,
+		ReplaceMetadata: true,
+	}
+	source := minio.CopySrcOptions{
+		Bucket:    bucket,
+		Object:    srcObject,
+		MatchETag: src.ETag,
+	}
+	if srcObject == dstObject {
+		// ComposeObject копировал бы большой объект по частям, то есть целиком
+		_, err = s.Client.CopyObject(ctx, dst, source)
+	} else {
+		_, err = s.Client.ComposeObject(ctx, dst, source)
+	}
+	return mapError(err)
+}
+
+func (s *MinIOStorage) RemoveObject(ctx context.Context, bucket string, objectName string) error {
+	return s.Client.RemoveObject(ctx, bucket, objectName, minio.RemoveObjectOptions{})
+}
+
+// ReadObject читает объект целиком; ETag берётся из того же ответа, что и содержимое.
+func (s *MinIOStorage) ReadObject(ctx context.Context, bucket, objectName string) ([]byte, string, error) {
+	obj, err := s.Client.GetObject(ctx, bucket, objectName, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, "", mapError(err)
+	}
+	defer obj.Close()
+
+	info, err := obj.Stat()
+	if err != nil {
+		return nil, "", mapError(err)
+	}
+	data, err := io.ReadAll(obj)
+	if err != nil {
+		return nil, "", mapError(err)
+	}
+	return data, info.ETag, nil
+}
+
+// CompareAndSwapObject записывает объект условным PUT с If-Match или If-None-Match: *.
+func (s *MinIOStorage) CompareAndSwapObject(ctx context.Context, bucket, objectName, etag string, data []byte) error {
+	opts := minio.PutObjectOptions{ContentType: "application/octet-stream"}
+	if etag == "" {
+		opts.SetMatchETagExcept("*")
+	} else {
+		opts.SetMatchETag(etag)
+	}
+
+	_, err := s.Client.PutObject(ctx, bucket, objectName, bytes.NewReader(data), int64(len(data)), opts)
+	switch minio.ToErrorResponse(err).Code {
+	case "PreconditionFailed", "ConditionalRequestConflict":
+		return fmt.Errorf("%w: %s", apperrors.ErrObjectModified, err.Error())
+	}
+	return err
+}
+
+// NewMultipartUpload начинает multipart-загрузку и возвращает её uploadID.
+func (s *MinIOStorage) NewMultipartUpload(ctx context.Context, bucket, objectName, contentType string, metadata map[string]string) (string, error) {
+	core := minio.Core{Client: s.Client}
+	return core.NewMultipartUpload(
+		ctx,
+		bucket,
+		objectName,
+		minio.PutObjectOptions{
+			ContentType:  contentType,
+			UserMetadata: metadata,
+		},
+	)
+}
+
+// PutObjectPart загружает часть с номером partNumber (нумерация с 1) и возвращает её ETag.
+func (s *MinIOStorage) PutObjectPart(ctx context.Context, bucket, objectName, uploadID string, partNumber int, reader io.Reader, size int64) (string, error) {
+	core := minio.Core{Client: s.Client}
+	part, err := core.PutObjectPart(
+		ctx,
+		bucket,
+		objectName,
+		uploadID,
+		partNumber,
+		reader,
+		size,
+		minio.PutObjectPartOptions{},
+	)
+	if err != nil {
+		return "", err
+	}
+	return part.ETag, nil
+}
+
+// CompleteMultipartUpload собирает объект из частей; etags[i] соответствует части с номером i+1.
+func (s *MinIOStorage) CompleteMultipartUpload(ctx context.Context, bucket, objectName, uploadID string, etags []string) error {
+	parts := make([]minio.CompletePart, len(etags))
+	for i, etag := range etags {
+		parts[i] = minio.CompletePart{PartNumber: i + 1, ETag: etag}
+	}
+
+	core := minio.Core{Client: s.Client}
+	_, err := core.CompleteMultipartUpload(ctx, bucket, objectName, uploadID, parts, minio.PutObjectOptions{})
+	return err
+}
+
+// AbortMultipartUpload отменяет загрузку и удаляет уже загруженные части.
+func (s *MinIOStorage) AbortMultipartUpload(ctx context.Context, bucket, objectName, uploadID string) error {
+	core := minio.Core{Client: s.Client}
+	return core.AbortMultipartUpload(ctx, bucket, objectName, uploadID)
+}
+
+// ListMultipartUploads возвращает незавершённые multipart-загрузки бакета.
+func (s *MinIOStorage) ListMultipartUploads(ctx context.Context, bucket string) ([]models.MultipartUpload, error) {
+	var uploads []models.MultipartUpload
+	for u := range s.Client.ListIncompleteUploads(ctx, bucket, "", true) {
+		if u.Err != nil {
+			return nil, u.Err
+		}
+		uploads = append(uploads, models.MultipartUpload{Key: u.Key, UploadID: u.UploadID, Initiated: u.Initiated})
+	}
+	return uploads, nil
+}
+
+func toObjectInfo(info minio.ObjectInfo) models.ObjectInfo {
+	if info.Err != nil {
+		return models.ObjectInfo{Err: info.Err}
+	}
+	return models.ObjectInfo{
+		Key:          info.Key,
+		Size:         info.Size,
+		LastModified: info.LastModified,
+		ContentType:  info.ContentType,
+		ETag:         info.ETag,
+		UserMetadata: info.UserMetadata,
+	}
+}
+
+// mapError заменяет ответ S3 об отсутствии объекта на apperrors.ErrObjectNotFound.
+func mapError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return fmt.Errorf("%w: %s", apperrors.ErrObjectNotFound, err.Error())
+	}
+	return err
+}
@@ -0,0 +1,3616 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.28.3
+// source: proto/upload_service/v1/upload_service.proto
+
+package __
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ListSortField int32
+
+const (
+	ListSortField_LIST_SORT_FIELD_UNSPECIFIED ListSortField = 0 // по file_id, самый дешёвый вариант
+	ListSortField_LIST_SORT_FIELD_FILENAME    ListSortField = 1
+	ListSortField_LIST_SORT_FIELD_CREATED_AT  ListSortField = 2
+	ListSortField_LIST_SORT_FIELD_UPDATED_AT  ListSortField = 3
+	ListSortField_LIST_SORT_FIELD_SIZE        ListSortField = 4
+)
+
+// Enum value maps for ListSortField.
+var (
+	ListSortField_name = map[int32]string{
+		0: "LIST_SORT_FIELD_UNSPECIFIED",
+		1: "LIST_SORT_FIELD_FILENAME",
+		2: "LIST_SORT_FIELD_CREATED_AT",
+		3: "LIST_SORT_FIELD_UPDATED_AT",
+		4: "LIST_SORT_FIELD_SIZE",
+	}
+	ListSortField_value = map[string]int32{
+		"LIST_SORT_FIELD_UNSPECIFIED": 0,
+		"LIST_SORT_FIELD_FILENAME":    1,
+		"LIST_SORT_FIELD_CREATED_AT":  2,
+		"LIST_SORT_FIELD_UPDATED_AT":  3,
+		"LIST_SORT_FIELD_SIZE":        4,
+	}
+)
+
+func (x ListSortField) Enum() *ListSortField {
+	p := new(ListSortField)
+	*p = x
+	return p
+}
+
+func (x ListSortField) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ListSortField) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_upload_service_v1_upload_service_proto_enumTypes[0].Descriptor()
+}
+
+func (ListSortField) Type() protoreflect.EnumType {
+	return &file_proto_upload_service_v1_upload_service_proto_enumTypes[0]
+}
+
+func (x ListSortField) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ListSortField.Descriptor instead.
+func (ListSortField) EnumDescriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{0}
+}
+
+type ArchiveFormat int32
+
+const (
+	ArchiveFormat_ARCHIVE_FORMAT_UNSPECIFIED ArchiveFormat = 0 // zip
+	ArchiveFormat_ARCHIVE_FORMAT_ZIP         ArchiveFormat = 1
+	ArchiveFormat_ARCHIVE_FORMAT_TAR         ArchiveFormat = 2
+	ArchiveFormat_ARCHIVE_FORMAT_TAR_GZ      ArchiveFormat = 3
+	ArchiveFormat_ARCHIVE_FORMAT_TAR_ZST     ArchiveFormat = 4
+)
+
+// Enum value maps for ArchiveFormat.
+var (
+	ArchiveFormat_name = map[int32]string{
+		0: "ARCHIVE_FORMAT_UNSPECIFIED",
+		1: "ARCHIVE_FORMAT_ZIP",
+		2: "ARCHIVE_FORMAT_TAR",
+		3: "ARCHIVE_FORMAT_TAR_GZ",
+		4: "ARCHIVE_FORMAT_TAR_ZST",
+	}
+	ArchiveFormat_value = map[string]int32{
+		"ARCHIVE_FORMAT_UNSPECIFIED": 0,
+		"ARCHIVE_FORMAT_ZIP":         1,
+		"ARCHIVE_FORMAT_TAR":         2,
+		"ARCHIVE_FORMAT_TAR_GZ":      3,
+		"ARCHIVE_FORMAT_TAR_ZST":     4,
+	}
+)
+
+func (x ArchiveFormat) Enum() *ArchiveFormat {
+	p := new(ArchiveFormat)
+	*p = x
+	return p
+}
+
+func (x ArchiveFormat) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ArchiveFormat) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_upload_service_v1_upload_service_proto_enumTypes[1].Descriptor()
+}
+
+func (ArchiveFormat) Type() protoreflect.EnumType {
+	return &file_proto_upload_service_v1_upload_service_proto_enumTypes[1]
+}
+
+func (x ArchiveFormat) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ArchiveFormat.Descriptor instead.
+func (ArchiveFormat) EnumDescriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{1}
+}
+
+type FileInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	Filename      string                 `protobuf:"bytes,2,opt,name=filename,proto3" json:"filename,omitempty"`                    // оригинальное имя файла
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // дата создания
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"` // дата обновления файла
+	Size          uint64                 `protobuf:"varint,5,opt,name=size,proto3" json:"size,omitempty"`
+	ContentType   string                 `protobuf:"bytes,6,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"` // MIME-тип, определённый при загрузке
+	Checksums     *Checksums             `protobuf:"bytes,7,opt,name=checksums,proto3" json:"checksums,omitempty"`
+	Version       uint64                 `protobuf:"varint,8,opt,name=version,proto3" json:"version,omitempty"`                         // номер версии содержимого, растёт с каждым обновлением
+	Etag          string                 `protobuf:"bytes,9,opt,name=etag,proto3" json:"etag,omitempty"`                                // меняется при каждой записи содержимого
+	Owner         string                 `protobuf:"bytes,10,opt,name=owner,proto3" json:"owner,omitempty"`                             // subject загрузившего; пусто - файл загружен без аутентификации
+	SharedWith    []string               `protobuf:"bytes,11,rep,name=shared_with,json=sharedWith,proto3" json:"shared_with,omitempty"` // кому владелец выдал доступ
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileInfo) Reset() {
+	*x = FileInfo{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileInfo) ProtoMessage() {}
+
+func (x *FileInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileInfo.ProtoReflect.Descriptor instead.
+func (*FileInfo) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *FileInfo) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *FileInfo) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *FileInfo) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *FileInfo) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *FileInfo) GetSize() uint64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *FileInfo) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *FileInfo) GetChecksums() *Checksums {
+	if x != nil {
+		return x.Checksums
+	}
+	return nil
+}
+
+func (x *FileInfo) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *FileInfo) GetEtag() string {
+	if x != nil {
+		return x.Etag
+	}
+	return ""
+}
+
+func (x *FileInfo) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *FileInfo) GetSharedWith() []string {
+	if x != nil {
+		return x.SharedWith
+	}
+	return nil
+}
+
+// контрольные суммы содержимого файла в hex
+type Checksums struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sha256        string                 `protobuf:"bytes,1,opt,name=sha256,proto3" json:"sha256,omitempty"`
+	Crc32C        string                 `protobuf:"bytes,2,opt,name=crc32c,proto3" json:"crc32c,omitempty"` // 4 байта big-endian; пусто, если сервер не считает CRC32C
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Checksums) Reset() {
+	*x = Checksums{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Checksums) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Checksums) ProtoMessage() {}
+
+func (x *Checksums) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Checksums.ProtoReflect.Descriptor instead.
+func (*Checksums) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Checksums) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+func (x *Checksums) GetCrc32C() string {
+	if x != nil {
+		return x.Crc32C
+	}
+	return ""
+}
+
+type UploadRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Data:
+	//
+	//	*UploadRequest_Filename
+	//	*UploadRequest_Chunk
+	Data          isUploadRequest_Data `protobuf_oneof:"data"`
+	Checksums     *Checksums           `protobuf:"bytes,3,opt,name=checksums,proto3" json:"checksums,omitempty"` // ожидаемые контрольные суммы файла, только в первом chunk; при несовпадении файл не сохраняется
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadRequest) Reset() {
+	*x = UploadRequest{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadRequest) ProtoMessage() {}
+
+func (x *UploadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadRequest.ProtoReflect.Descriptor instead.
+func (*UploadRequest) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *UploadRequest) GetData() isUploadRequest_Data {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *UploadRequest) GetFilename() string {
+	if x != nil {
+		if x, ok := x.Data.(*UploadRequest_Filename); ok {
+			return x.Filename
+		}
+	}
+	return ""
+}
+
+func (x *UploadRequest) GetChunk() []byte {
+	if x != nil {
+		if x, ok := x.Data.(*UploadRequest_Chunk); ok {
+			return x.Chunk
+		}
+	}
+	return nil
+}
+
+func (x *UploadRequest) GetChecksums() *Checksums {
+	if x != nil {
+		return x.Checksums
+	}
+	return nil
+}
+
+type isUploadRequest_Data interface {
+	isUploadRequest_Data()
+}
+
+type UploadRequest_Filename struct {
+	Filename string `protobuf:"bytes,1,opt,name=filename,proto3,oneof"` // Только в первом chunk имя файла
+}
+
+type UploadRequest_Chunk struct {
+	Chunk []byte `protobuf:"bytes,2,opt,name=chunk,proto3,oneof"`
+}
+
+func (*UploadRequest_Filename) isUploadRequest_Data() {}
+
+func (*UploadRequest_Chunk) isUploadRequest_Data() {}
+
+type UploadResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	Size          uint64                 `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	Checksums     *Checksums             `protobuf:"bytes,3,opt,name=checksums,proto3" json:"checksums,omitempty"` // посчитанные сервером
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadResponse) Reset() {
+	*x = UploadResponse{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadResponse) ProtoMessage() {}
+
+func (x *UploadResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadResponse.ProtoReflect.Descriptor instead.
+func (*UploadResponse) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *UploadResponse) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *UploadResponse) GetSize() uint64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *UploadResponse) GetChecksums() *Checksums {
+	if x != nil {
+		return x.Checksums
+	}
+	return nil
+}
+
+type UpdateFileRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Data:
+	//
+	//	*UpdateFileRequest_FileId
+	//	*UpdateFileRequest_Chunk
+	Data      isUpdateFileRequest_Data `protobuf_oneof:"data"`
+	Checksums *Checksums               `protobuf:"bytes,3,opt,name=checksums,proto3" json:"checksums,omitempty"` // ожидаемые контрольные суммы новых данных, только в первом chunk
+	// условное обновление, только в первом chunk: если файл изменился с тех пор,
+	// как клиент получил этот etag или эту версию, вернётся FAILED_PRECONDITION
+	IfMatchEtag    string `protobuf:"bytes,4,opt,name=if_match_etag,json=ifMatchEtag,proto3" json:"if_match_etag,omitempty"`
+	IfMatchVersion uint64 `protobuf:"varint,5,opt,name=if_match_version,json=ifMatchVersion,proto3" json:"if_match_version,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *UpdateFileRequest) Reset() {
+	*x = UpdateFileRequest{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateFileRequest) ProtoMessage() {}
+
+func (x *UpdateFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateFileRequest.ProtoReflect.Descriptor instead.
+func (*UpdateFileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *UpdateFileRequest) GetData() isUpdateFileRequest_Data {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *UpdateFileRequest) GetFileId() string {
+	if x != nil {
+		if x, ok := x.Data.(*UpdateFileRequest_FileId); ok {
+			return x.FileId
+		}
+	}
+	return ""
+}
+
+func (x *UpdateFileRequest) GetChunk() []byte {
+	if x != nil {
+		if x, ok := x.Data.(*UpdateFileRequest_Chunk); ok {
+			return x.Chunk
+		}
+	}
+	return nil
+}
+
+func (x *UpdateFileRequest) GetChecksums() *Checksums {
+	if x != nil {
+		return x.Checksums
+	}
+	return nil
+}
+
+func (x *UpdateFileRequest) GetIfMatchEtag() string {
+	if x != nil {
+		return x.IfMatchEtag
+	}
+	return ""
+}
+
+func (x *UpdateFileRequest) GetIfMatchVersion() uint64 {
+	if x != nil {
+		return x.IfMatchVersion
+	}
+	return 0
+}
+
+type isUpdateFileRequest_Data interface {
+	isUpdateFileRequest_Data()
+}
+
+type UpdateFileRequest_FileId struct {
+	FileId string `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3,oneof"` // ID файла для обновления (только в первом chunk)
+}
+
+type UpdateFileRequest_Chunk struct {
+	Chunk []byte `protobuf:"bytes,2,opt,name=chunk,proto3,oneof"`
+}
+
+func (*UpdateFileRequest_FileId) isUpdateFileRequest_Data() {}
+
+func (*UpdateFileRequest_Chunk) isUpdateFileRequest_Data() {}
+
+type UpdateFileResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	NewSize       uint64                 `protobuf:"varint,2,opt,name=new_size,json=newSize,proto3" json:"new_size,omitempty"`
+	Checksums     *Checksums             `protobuf:"bytes,3,opt,name=checksums,proto3" json:"checksums,omitempty"`
+	Etag          string                 `protobuf:"bytes,4,opt,name=etag,proto3" json:"etag,omitempty"` // etag нового содержимого для следующего условного обновления
+	Version       uint64                 `protobuf:"varint,5,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateFileResponse) Reset() {
+	*x = UpdateFileResponse{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateFileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateFileResponse) ProtoMessage() {}
+
+func (x *UpdateFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateFileResponse.ProtoReflect.Descriptor instead.
+func (*UpdateFileResponse) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *UpdateFileResponse) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *UpdateFileResponse) GetNewSize() uint64 {
+	if x != nil {
+		return x.NewSize
+	}
+	return 0
+}
+
+func (x *UpdateFileResponse) GetChecksums() *Checksums {
+	if x != nil {
+		return x.Checksums
+	}
+	return nil
+}
+
+func (x *UpdateFileResponse) GetEtag() string {
+	if x != nil {
+		return x.Etag
+	}
+	return ""
+}
+
+func (x *UpdateFileResponse) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type ListRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PageSize      uint32                 `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`   // 0 - все файлы одной страницей
+	PageToken     string                 `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"` // next_page_token из предыдущего ответа, параметры сортировки должны совпадать
+	Filter        *ListFilter            `protobuf:"bytes,3,opt,name=filter,proto3" json:"filter,omitempty"`
+	SortBy        ListSortField          `protobuf:"varint,4,opt,name=sort_by,json=sortBy,proto3,enum=upload_service.v1.ListSortField" json:"sort_by,omitempty"`
+	Descending    bool                   `protobuf:"varint,5,opt,name=descending,proto3" json:"descending,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRequest) Reset() {
+	*x = ListRequest{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRequest) ProtoMessage() {}
+
+func (x *ListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRequest.ProtoReflect.Descriptor instead.
+func (*ListRequest) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListRequest) GetPageSize() uint32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *ListRequest) GetFilter() *ListFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *ListRequest) GetSortBy() ListSortField {
+	if x != nil {
+		return x.SortBy
+	}
+	return ListSortField_LIST_SORT_FIELD_UNSPECIFIED
+}
+
+func (x *ListRequest) GetDescending() bool {
+	if x != nil {
+		return x.Descending
+	}
+	return false
+}
+
+// все условия фильтра объединяются через AND, пустые поля не учитываются
+type ListFilter struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	FilenamePrefix string                 `protobuf:"bytes,1,opt,name=filename_prefix,json=filenamePrefix,proto3" json:"filename_prefix,omitempty"`
+	ContentType    string                 `protobuf:"bytes,2,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"` // точный MIME-тип или группа вида "image/*"
+	CreatedAfter   *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_after,json=createdAfter,proto3" json:"created_after,omitempty"`
+	CreatedBefore  *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_before,json=createdBefore,proto3" json:"created_before,omitempty"`
+	UpdatedAfter   *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=updated_after,json=updatedAfter,proto3" json:"updated_after,omitempty"`
+	UpdatedBefore  *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=updated_before,json=updatedBefore,proto3" json:"updated_before,omitempty"`
+	MinSize        uint64                 `protobuf:"varint,7,opt,name=min_size,json=minSize,proto3" json:"min_size,omitempty"`
+	MaxSize        uint64                 `protobuf:"varint,8,opt,name=max_size,json=maxSize,proto3" json:"max_size,omitempty"` // 0 - без ограничения
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ListFilter) Reset() {
+	*x = ListFilter{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFilter) ProtoMessage() {}
+
+func (x *ListFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFilter.ProtoReflect.Descriptor instead.
+func (*ListFilter) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListFilter) GetFilenamePrefix() string {
+	if x != nil {
+		return x.FilenamePrefix
+	}
+	return ""
+}
+
+func (x *ListFilter) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *ListFilter) GetCreatedAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAfter
+	}
+	return nil
+}
+
+func (x *ListFilter) GetCreatedBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedBefore
+	}
+	return nil
+}
+
+func (x *ListFilter) GetUpdatedAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAfter
+	}
+	return nil
+}
+
+func (x *ListFilter) GetUpdatedBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedBefore
+	}
+	return nil
+}
+
+func (x *ListFilter) GetMinSize() uint64 {
+	if x != nil {
+		return x.MinSize
+	}
+	return 0
+}
+
+func (x *ListFilter) GetMaxSize() uint64 {
+	if x != nil {
+		return x.MaxSize
+	}
+	return 0
+}
+
+type ListResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Files         []*FileInfo            `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"` // пусто на последней странице
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListResponse) Reset() {
+	*x = ListResponse{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListResponse) ProtoMessage() {}
+
+func (x *ListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListResponse.ProtoReflect.Descriptor instead.
+func (*ListResponse) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListResponse) GetFiles() []*FileInfo {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+func (x *ListResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type DownloadLinkRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	ExpirySeconds uint32                 `protobuf:"varint,2,opt,name=expiry_seconds,json=expirySeconds,proto3" json:"expiry_seconds,omitempty"` // срок действия ссылки; 0 - по умолчанию, больше максимума сервера - обрезается
+	ContentType   string                 `protobuf:"bytes,3,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`        // Content-Type ответа вместо типа файла
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadLinkRequest) Reset() {
+	*x = DownloadLinkRequest{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadLinkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadLinkRequest) ProtoMessage() {}
+
+func (x *DownloadLinkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadLinkRequest.ProtoReflect.Descriptor instead.
+func (*DownloadLinkRequest) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DownloadLinkRequest) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *DownloadLinkRequest) GetExpirySeconds() uint32 {
+	if x != nil {
+		return x.ExpirySeconds
+	}
+	return 0
+}
+
+func (x *DownloadLinkRequest) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+type DownloadLinkResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"` // Presigned URL от MinIO
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadLinkResponse) Reset() {
+	*x = DownloadLinkResponse{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadLinkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadLinkResponse) ProtoMessage() {}
+
+func (x *DownloadLinkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadLinkResponse.ProtoReflect.Descriptor instead.
+func (*DownloadLinkResponse) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *DownloadLinkResponse) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *DownloadLinkResponse) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type DownloadFileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	Offset        uint64                 `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"` // с какого байта отдавать файл, для докачки и перемотки
+	Length        uint64                 `protobuf:"varint,3,opt,name=length,proto3" json:"length,omitempty"` // сколько байт отдать; 0 - до конца файла
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadFileRequest) Reset() {
+	*x = DownloadFileRequest{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadFileRequest) ProtoMessage() {}
+
+func (x *DownloadFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadFileRequest.ProtoReflect.Descriptor instead.
+func (*DownloadFileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *DownloadFileRequest) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *DownloadFileRequest) GetOffset() uint64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *DownloadFileRequest) GetLength() uint64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+// первое сообщение стрима DownloadFile
+type DownloadFileHeader struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	File          *FileInfo              `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`      // тип, полный размер, контрольные суммы и etag всего файла
+	Offset        uint64                 `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"` // фактический диапазон, который будет передан
+	Length        uint64                 `protobuf:"varint,3,opt,name=length,proto3" json:"length,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadFileHeader) Reset() {
+	*x = DownloadFileHeader{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadFileHeader) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadFileHeader) ProtoMessage() {}
+
+func (x *DownloadFileHeader) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadFileHeader.ProtoReflect.Descriptor instead.
+func (*DownloadFileHeader) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *DownloadFileHeader) GetFile() *FileInfo {
+	if x != nil {
+		return x.File
+	}
+	return nil
+}
+
+func (x *DownloadFileHeader) GetOffset() uint64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *DownloadFileHeader) GetLength() uint64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+type DownloadFileResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Data:
+	//
+	//	*DownloadFileResponse_Header
+	//	*DownloadFileResponse_Chunk
+	Data          isDownloadFileResponse_Data `protobuf_oneof:"data"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadFileResponse) Reset() {
+	*x = DownloadFileResponse{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadFileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadFileResponse) ProtoMessage() {}
+
+func (x *DownloadFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadFileResponse.ProtoReflect.Descriptor instead.
+func (*DownloadFileResponse) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *DownloadFileResponse) GetData() isDownloadFileResponse_Data {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *DownloadFileResponse) GetHeader() *DownloadFileHeader {
+	if x != nil {
+		if x, ok := x.Data.(*DownloadFileResponse_Header); ok {
+			return x.Header
+		}
+	}
+	return nil
+}
+
+func (x *DownloadFileResponse) GetChunk() []byte {
+	if x != nil {
+		if x, ok := x.Data.(*DownloadFileResponse_Chunk); ok {
+			return x.Chunk
+		}
+	}
+	return nil
+}
+
+type isDownloadFileResponse_Data interface {
+	isDownloadFileResponse_Data()
+}
+
+type DownloadFileResponse_Header struct {
+	Header *DownloadFileHeader `protobuf:"bytes,1,opt,name=header,proto3,oneof"` // только в первом сообщении
+}
+
+type DownloadFileResponse_Chunk struct {
+	Chunk []byte `protobuf:"bytes,2,opt,name=chunk,proto3,oneof"`
+}
+
+func (*DownloadFileResponse_Header) isDownloadFileResponse_Data() {}
+
+func (*DownloadFileResponse_Chunk) isDownloadFileResponse_Data() {}
+
+type DownloadZipRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	FileIds []string               `protobuf:"bytes,1,rep,name=file_ids,json=fileIds,proto3" json:"file_ids,omitempty"` // ID файлов для архивации
+	// strict - отказ с NOT_FOUND, если хотя бы один файл не найден; без него такие файлы
+	// пропускаются и перечисляются в MANIFEST.json внутри архива
+	Strict        bool          `protobuf:"varint,2,opt,name=strict,proto3" json:"strict,omitempty"`
+	Format        ArchiveFormat `protobuf:"varint,3,opt,name=format,proto3,enum=upload_service.v1.ArchiveFormat" json:"format,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadZipRequest) Reset() {
+	*x = DownloadZipRequest{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadZipRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadZipRequest) ProtoMessage() {}
+
+func (x *DownloadZipRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadZipRequest.ProtoReflect.Descriptor instead.
+func (*DownloadZipRequest) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *DownloadZipRequest) GetFileIds() []string {
+	if x != nil {
+		return x.FileIds
+	}
+	return nil
+}
+
+func (x *DownloadZipRequest) GetStrict() bool {
+	if x != nil {
+		return x.Strict
+	}
+	return false
+}
+
+func (x *DownloadZipRequest) GetFormat() ArchiveFormat {
+	if x != nil {
+		return x.Format
+	}
+	return ArchiveFormat_ARCHIVE_FORMAT_UNSPECIFIED
+}
+
+type DownloadZipResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Chunk         []byte                 `protobuf:"bytes,1,opt,name=chunk,proto3" json:"chunk,omitempty"` // Порция архива
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadZipResponse) Reset() {
+	*x = DownloadZipResponse{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadZipResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadZipResponse) ProtoMessage() {}
+
+func (x *DownloadZipResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadZipResponse.ProtoReflect.Descriptor instead.
+func (*DownloadZipResponse) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *DownloadZipResponse) GetChunk() []byte {
+	if x != nil {
+		return x.Chunk
+	}
+	return nil
+}
+
+type CreateUploadSessionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateUploadSessionRequest) Reset() {
+	*x = CreateUploadSessionRequest{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateUploadSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateUploadSessionRequest) ProtoMessage() {}
+
+func (x *CreateUploadSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateUploadSessionRequest.ProtoReflect.Descriptor instead.
+func (*CreateUploadSessionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *CreateUploadSessionRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+type CreateUploadSessionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	PartSize      uint64                 `protobuf:"varint,2,opt,name=part_size,json=partSize,proto3" json:"part_size,omitempty"` // данные сохраняются частями такого размера, кроме последней
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateUploadSessionResponse) Reset() {
+	*x = CreateUploadSessionResponse{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateUploadSessionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateUploadSessionResponse) ProtoMessage() {}
+
+func (x *CreateUploadSessionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateUploadSessionResponse.ProtoReflect.Descriptor instead.
+func (*CreateUploadSessionResponse) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *CreateUploadSessionResponse) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *CreateUploadSessionResponse) GetPartSize() uint64 {
+	if x != nil {
+		return x.PartSize
+	}
+	return 0
+}
+
+func (x *CreateUploadSessionResponse) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type UploadSessionOffset struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Offset        uint64                 `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"` // должно совпадать с committed_size сессии
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadSessionOffset) Reset() {
+	*x = UploadSessionOffset{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadSessionOffset) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadSessionOffset) ProtoMessage() {}
+
+func (x *UploadSessionOffset) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadSessionOffset.ProtoReflect.Descriptor instead.
+func (*UploadSessionOffset) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *UploadSessionOffset) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *UploadSessionOffset) GetOffset() uint64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type AppendUploadSessionRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Data:
+	//
+	//	*AppendUploadSessionRequest_Header
+	//	*AppendUploadSessionRequest_Chunk
+	Data          isAppendUploadSessionRequest_Data `protobuf_oneof:"data"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AppendUploadSessionRequest) Reset() {
+	*x = AppendUploadSessionRequest{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AppendUploadSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AppendUploadSessionRequest) ProtoMessage() {}
+
+func (x *AppendUploadSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AppendUploadSessionRequest.ProtoReflect.Descriptor instead.
+func (*AppendUploadSessionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *AppendUploadSessionRequest) GetData() isAppendUploadSessionRequest_Data {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *AppendUploadSessionRequest) GetHeader() *UploadSessionOffset {
+	if x != nil {
+		if x, ok := x.Data.(*AppendUploadSessionRequest_Header); ok {
+			return x.Header
+		}
+	}
+	return nil
+}
+
+func (x *AppendUploadSessionRequest) GetChunk() []byte {
+	if x != nil {
+		if x, ok := x.Data.(*AppendUploadSessionRequest_Chunk); ok {
+			return x.Chunk
+		}
+	}
+	return nil
+}
+
+type isAppendUploadSessionRequest_Data interface {
+	isAppendUploadSessionRequest_Data()
+}
+
+type AppendUploadSessionRequest_Header struct {
+	Header *UploadSessionOffset `protobuf:"bytes,1,opt,name=header,proto3,oneof"` // Только в первом chunk
+}
+
+type AppendUploadSessionRequest_Chunk struct {
+	Chunk []byte `protobuf:"bytes,2,opt,name=chunk,proto3,oneof"`
+}
+
+func (*AppendUploadSessionRequest_Header) isAppendUploadSessionRequest_Data() {}
+
+func (*AppendUploadSessionRequest_Chunk) isAppendUploadSessionRequest_Data() {}
+
+type UploadSessionStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadSessionStatusRequest) Reset() {
+	*x = UploadSessionStatusRequest{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadSessionStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadSessionStatusRequest) ProtoMessage() {}
+
+func (x *UploadSessionStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadSessionStatusRequest.ProtoReflect.Descriptor instead.
+func (*UploadSessionStatusRequest) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *UploadSessionStatusRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+type UploadSessionStatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	CommittedSize uint64                 `protobuf:"varint,2,opt,name=committed_size,json=committedSize,proto3" json:"committed_size,omitempty"` // с этого смещения нужно продолжать загрузку
+	Sealed        bool                   `protobuf:"varint,3,opt,name=sealed,proto3" json:"sealed,omitempty"`                                    // последняя (неполная) часть получена, осталось вызвать FinalizeUploadSession
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadSessionStatusResponse) Reset() {
+	*x = UploadSessionStatusResponse{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadSessionStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadSessionStatusResponse) ProtoMessage() {}
+
+func (x *UploadSessionStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadSessionStatusResponse.ProtoReflect.Descriptor instead.
+func (*UploadSessionStatusResponse) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *UploadSessionStatusResponse) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *UploadSessionStatusResponse) GetCommittedSize() uint64 {
+	if x != nil {
+		return x.CommittedSize
+	}
+	return 0
+}
+
+func (x *UploadSessionStatusResponse) GetSealed() bool {
+	if x != nil {
+		return x.Sealed
+	}
+	return false
+}
+
+func (x *UploadSessionStatusResponse) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type FinalizeUploadSessionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FinalizeUploadSessionRequest) Reset() {
+	*x = FinalizeUploadSessionRequest{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FinalizeUploadSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FinalizeUploadSessionRequest) ProtoMessage() {}
+
+func (x *FinalizeUploadSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FinalizeUploadSessionRequest.ProtoReflect.Descriptor instead.
+func (*FinalizeUploadSessionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *FinalizeUploadSessionRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+type CreateDirectUploadRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filename      string                 `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	Size          uint64                 `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`                                 // точный размер файла; 0 - неизвестен, ограничен максимальным размером
+	ContentType   string                 `protobuf:"bytes,3,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"` // тип, с которым нужно загрузить файл; пусто - любой
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateDirectUploadRequest) Reset() {
+	*x = CreateDirectUploadRequest{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateDirectUploadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateDirectUploadRequest) ProtoMessage() {}
+
+func (x *CreateDirectUploadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateDirectUploadRequest.ProtoReflect.Descriptor instead.
+func (*CreateDirectUploadRequest) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *CreateDirectUploadRequest) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *CreateDirectUploadRequest) GetSize() uint64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *CreateDirectUploadRequest) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+type CreateDirectUploadResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	Url           string                 `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`                                                                                                           // адрес для multipart/form-data POST-запроса
+	FormFields    map[string]string      `protobuf:"bytes,3,rep,name=form_fields,json=formFields,proto3" json:"form_fields,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // поля формы, передаются до поля file с содержимым
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateDirectUploadResponse) Reset() {
+	*x = CreateDirectUploadResponse{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateDirectUploadResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateDirectUploadResponse) ProtoMessage() {}
+
+func (x *CreateDirectUploadResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateDirectUploadResponse.ProtoReflect.Descriptor instead.
+func (*CreateDirectUploadResponse) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *CreateDirectUploadResponse) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *CreateDirectUploadResponse) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *CreateDirectUploadResponse) GetFormFields() map[string]string {
+	if x != nil {
+		return x.FormFields
+	}
+	return nil
+}
+
+func (x *CreateDirectUploadResponse) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type CompleteDirectUploadRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompleteDirectUploadRequest) Reset() {
+	*x = CompleteDirectUploadRequest{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompleteDirectUploadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompleteDirectUploadRequest) ProtoMessage() {}
+
+func (x *CompleteDirectUploadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompleteDirectUploadRequest.ProtoReflect.Descriptor instead.
+func (*CompleteDirectUploadRequest) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *CompleteDirectUploadRequest) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+type DeleteFileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	Permanent     bool                   `protobuf:"varint,2,opt,name=permanent,proto3" json:"permanent,omitempty"` // удалить сразу, минуя корзину
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteFileRequest) Reset() {
+	*x = DeleteFileRequest{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteFileRequest) ProtoMessage() {}
+
+func (x *DeleteFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteFileRequest.ProtoReflect.Descriptor instead.
+func (*DeleteFileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *DeleteFileRequest) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *DeleteFileRequest) GetPermanent() bool {
+	if x != nil {
+		return x.Permanent
+	}
+	return false
+}
+
+type DeleteFileResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	PurgeAt       *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=purge_at,json=purgeAt,proto3" json:"purge_at,omitempty"` // когда файл будет удалён из корзины (не задано при permanent)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteFileResponse) Reset() {
+	*x = DeleteFileResponse{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteFileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteFileResponse) ProtoMessage() {}
+
+func (x *DeleteFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteFileResponse.ProtoReflect.Descriptor instead.
+func (*DeleteFileResponse) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *DeleteFileResponse) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *DeleteFileResponse) GetPurgeAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PurgeAt
+	}
+	return nil
+}
+
+type RestoreFileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreFileRequest) Reset() {
+	*x = RestoreFileRequest{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreFileRequest) ProtoMessage() {}
+
+func (x *RestoreFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreFileRequest.ProtoReflect.Descriptor instead.
+func (*RestoreFileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *RestoreFileRequest) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+type RestoreFileResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	File          *FileInfo              `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreFileResponse) Reset() {
+	*x = RestoreFileResponse{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreFileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreFileResponse) ProtoMessage() {}
+
+func (x *RestoreFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreFileResponse.ProtoReflect.Descriptor instead.
+func (*RestoreFileResponse) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *RestoreFileResponse) GetFile() *FileInfo {
+	if x != nil {
+		return x.File
+	}
+	return nil
+}
+
+type ListTrashRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTrashRequest) Reset() {
+	*x = ListTrashRequest{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTrashRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTrashRequest) ProtoMessage() {}
+
+func (x *ListTrashRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTrashRequest.ProtoReflect.Descriptor instead.
+func (*ListTrashRequest) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{30}
+}
+
+type TrashedFile struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	File          *FileInfo              `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`
+	DeletedAt     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	PurgeAt       *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=purge_at,json=purgeAt,proto3" json:"purge_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TrashedFile) Reset() {
+	*x = TrashedFile{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrashedFile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrashedFile) ProtoMessage() {}
+
+func (x *TrashedFile) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrashedFile.ProtoReflect.Descriptor instead.
+func (*TrashedFile) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *TrashedFile) GetFile() *FileInfo {
+	if x != nil {
+		return x.File
+	}
+	return nil
+}
+
+func (x *TrashedFile) GetDeletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeletedAt
+	}
+	return nil
+}
+
+func (x *TrashedFile) GetPurgeAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PurgeAt
+	}
+	return nil
+}
+
+type ListTrashResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Files         []*TrashedFile         `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTrashResponse) Reset() {
+	*x = ListTrashResponse{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTrashResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTrashResponse) ProtoMessage() {}
+
+func (x *ListTrashResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTrashResponse.ProtoReflect.Descriptor instead.
+func (*ListTrashResponse) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *ListTrashResponse) GetFiles() []*TrashedFile {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+type GetStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatsRequest) Reset() {
+	*x = GetStatsRequest{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsRequest) ProtoMessage() {}
+
+func (x *GetStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetStatsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{33}
+}
+
+type GetStatsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Files         uint64                 `protobuf:"varint,1,opt,name=files,proto3" json:"files,omitempty"`                                   // файлы вне корзины
+	LogicalBytes  uint64                 `protobuf:"varint,2,opt,name=logical_bytes,json=logicalBytes,proto3" json:"logical_bytes,omitempty"` // размер всех файлов (включая корзину) без учёта дедупликации
+	StoredBytes   uint64                 `protobuf:"varint,3,opt,name=stored_bytes,json=storedBytes,proto3" json:"stored_bytes,omitempty"`    // фактически занятое место в хранилище
+	Blobs         uint64                 `protobuf:"varint,4,opt,name=blobs,proto3" json:"blobs,omitempty"`                                   // количество уникальных блобов
+	DedupRatio    float64                `protobuf:"fixed64,5,opt,name=dedup_ratio,json=dedupRatio,proto3" json:"dedup_ratio,omitempty"`      // logical_bytes / stored_bytes
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatsResponse) Reset() {
+	*x = GetStatsResponse{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsResponse) ProtoMessage() {}
+
+func (x *GetStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetStatsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *GetStatsResponse) GetFiles() uint64 {
+	if x != nil {
+		return x.Files
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetLogicalBytes() uint64 {
+	if x != nil {
+		return x.LogicalBytes
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetStoredBytes() uint64 {
+	if x != nil {
+		return x.StoredBytes
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetBlobs() uint64 {
+	if x != nil {
+		return x.Blobs
+	}
+	return 0
+}
+
+func (x *GetStatsResponse) GetDedupRatio() float64 {
+	if x != nil {
+		return x.DedupRatio
+	}
+	return 0
+}
+
+type ListVersionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListVersionsRequest) Reset() {
+	*x = ListVersionsRequest{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListVersionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListVersionsRequest) ProtoMessage() {}
+
+func (x *ListVersionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListVersionsRequest.ProtoReflect.Descriptor instead.
+func (*ListVersionsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *ListVersionsRequest) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+type FileVersion struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       uint64                 `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	File          *FileInfo              `protobuf:"bytes,2,opt,name=file,proto3" json:"file,omitempty"` // метаданные файла в этой версии
+	Current       bool                   `protobuf:"varint,3,opt,name=current,proto3" json:"current,omitempty"`
+	ArchivedAt    *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=archived_at,json=archivedAt,proto3" json:"archived_at,omitempty"` // когда версия была заменена; пусто у текущей
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileVersion) Reset() {
+	*x = FileVersion{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileVersion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileVersion) ProtoMessage() {}
+
+func (x *FileVersion) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileVersion.ProtoReflect.Descriptor instead.
+func (*FileVersion) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *FileVersion) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *FileVersion) GetFile() *FileInfo {
+	if x != nil {
+		return x.File
+	}
+	return nil
+}
+
+func (x *FileVersion) GetCurrent() bool {
+	if x != nil {
+		return x.Current
+	}
+	return false
+}
+
+func (x *FileVersion) GetArchivedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ArchivedAt
+	}
+	return nil
+}
+
+type ListVersionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Versions      []*FileVersion         `protobuf:"bytes,1,rep,name=versions,proto3" json:"versions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListVersionsResponse) Reset() {
+	*x = ListVersionsResponse{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListVersionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListVersionsResponse) ProtoMessage() {}
+
+func (x *ListVersionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListVersionsResponse.ProtoReflect.Descriptor instead.
+func (*ListVersionsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *ListVersionsResponse) GetVersions() []*FileVersion {
+	if x != nil {
+		return x.Versions
+	}
+	return nil
+}
+
+type VersionDownloadLinkRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	Version       uint64                 `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	ExpirySeconds uint32                 `protobuf:"varint,3,opt,name=expiry_seconds,json=expirySeconds,proto3" json:"expiry_seconds,omitempty"` // как в DownloadLinkRequest
+	ContentType   string                 `protobuf:"bytes,4,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VersionDownloadLinkRequest) Reset() {
+	*x = VersionDownloadLinkRequest{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VersionDownloadLinkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VersionDownloadLinkRequest) ProtoMessage() {}
+
+func (x *VersionDownloadLinkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VersionDownloadLinkRequest.ProtoReflect.Descriptor instead.
+func (*VersionDownloadLinkRequest) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *VersionDownloadLinkRequest) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *VersionDownloadLinkRequest) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *VersionDownloadLinkRequest) GetExpirySeconds() uint32 {
+	if x != nil {
+		return x.ExpirySeconds
+	}
+	return 0
+}
+
+func (x *VersionDownloadLinkRequest) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+type RollbackFileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	Version       uint64                 `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RollbackFileRequest) Reset() {
+	*x = RollbackFileRequest{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RollbackFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RollbackFileRequest) ProtoMessage() {}
+
+func (x *RollbackFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RollbackFileRequest.ProtoReflect.Descriptor instead.
+func (*RollbackFileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *RollbackFileRequest) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *RollbackFileRequest) GetVersion() uint64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type RollbackFileResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	File          *FileInfo              `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RollbackFileResponse) Reset() {
+	*x = RollbackFileResponse{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RollbackFileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RollbackFileResponse) ProtoMessage() {}
+
+func (x *RollbackFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RollbackFileResponse.ProtoReflect.Descriptor instead.
+func (*RollbackFileResponse) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *RollbackFileResponse) GetFile() *FileInfo {
+	if x != nil {
+		return x.File
+	}
+	return nil
+}
+
+type CreateShareLinkRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	Password      string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`                                 // пусто - без пароля
+	MaxDownloads  uint64                 `protobuf:"varint,3,opt,name=max_downloads,json=maxDownloads,proto3" json:"max_downloads,omitempty"`    // 0 - без ограничения
+	ExpirySeconds int64                  `protobuf:"varint,4,opt,name=expiry_seconds,json=expirySeconds,proto3" json:"expiry_seconds,omitempty"` // 0 - бессрочная
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateShareLinkRequest) Reset() {
+	*x = CreateShareLinkRequest{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateShareLinkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateShareLinkRequest) ProtoMessage() {}
+
+func (x *CreateShareLinkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateShareLinkRequest.ProtoReflect.Descriptor instead.
+func (*CreateShareLinkRequest) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *CreateShareLinkRequest) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *CreateShareLinkRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *CreateShareLinkRequest) GetMaxDownloads() uint64 {
+	if x != nil {
+		return x.MaxDownloads
+	}
+	return 0
+}
+
+func (x *CreateShareLinkRequest) GetExpirySeconds() int64 {
+	if x != nil {
+		return x.ExpirySeconds
+	}
+	return 0
+}
+
+type ShareLink struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	FileId        string                 `protobuf:"bytes,2,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	Url           string                 `protobuf:"bytes,3,opt,name=url,proto3" json:"url,omitempty"`
+	HasPassword   bool                   `protobuf:"varint,4,opt,name=has_password,json=hasPassword,proto3" json:"has_password,omitempty"`
+	MaxDownloads  uint64                 `protobuf:"varint,5,opt,name=max_downloads,json=maxDownloads,proto3" json:"max_downloads,omitempty"`
+	Downloads     uint64                 `protobuf:"varint,6,opt,name=downloads,proto3" json:"downloads,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"` // не задано - бессрочная
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShareLink) Reset() {
+	*x = ShareLink{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShareLink) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShareLink) ProtoMessage() {}
+
+func (x *ShareLink) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShareLink.ProtoReflect.Descriptor instead.
+func (*ShareLink) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *ShareLink) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *ShareLink) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *ShareLink) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *ShareLink) GetHasPassword() bool {
+	if x != nil {
+		return x.HasPassword
+	}
+	return false
+}
+
+func (x *ShareLink) GetMaxDownloads() uint64 {
+	if x != nil {
+		return x.MaxDownloads
+	}
+	return 0
+}
+
+func (x *ShareLink) GetDownloads() uint64 {
+	if x != nil {
+		return x.Downloads
+	}
+	return 0
+}
+
+func (x *ShareLink) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *ShareLink) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type ListShareLinksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"` // пусто - ссылки на все файлы
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListShareLinksRequest) Reset() {
+	*x = ListShareLinksRequest{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListShareLinksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListShareLinksRequest) ProtoMessage() {}
+
+func (x *ListShareLinksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListShareLinksRequest.ProtoReflect.Descriptor instead.
+func (*ListShareLinksRequest) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *ListShareLinksRequest) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+type ListShareLinksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Links         []*ShareLink           `protobuf:"bytes,1,rep,name=links,proto3" json:"links,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListShareLinksResponse) Reset() {
+	*x = ListShareLinksResponse{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListShareLinksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListShareLinksResponse) ProtoMessage() {}
+
+func (x *ListShareLinksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListShareLinksResponse.ProtoReflect.Descriptor instead.
+func (*ListShareLinksResponse) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *ListShareLinksResponse) GetLinks() []*ShareLink {
+	if x != nil {
+		return x.Links
+	}
+	return nil
+}
+
+type RevokeShareLinkRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeShareLinkRequest) Reset() {
+	*x = RevokeShareLinkRequest{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeShareLinkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeShareLinkRequest) ProtoMessage() {}
+
+func (x *RevokeShareLinkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeShareLinkRequest.ProtoReflect.Descriptor instead.
+func (*RevokeShareLinkRequest) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *RevokeShareLinkRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+type RevokeShareLinkResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeShareLinkResponse) Reset() {
+	*x = RevokeShareLinkResponse{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeShareLinkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeShareLinkResponse) ProtoMessage() {}
+
+func (x *RevokeShareLinkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeShareLinkResponse.ProtoReflect.Descriptor instead.
+func (*RevokeShareLinkResponse) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{46}
+}
+
+type GrantAccessRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	Subject       string                 `protobuf:"bytes,2,opt,name=subject,proto3" json:"subject,omitempty"` // subject токена пользователя
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GrantAccessRequest) Reset() {
+	*x = GrantAccessRequest{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GrantAccessRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GrantAccessRequest) ProtoMessage() {}
+
+func (x *GrantAccessRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GrantAccessRequest.ProtoReflect.Descriptor instead.
+func (*GrantAccessRequest) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *GrantAccessRequest) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *GrantAccessRequest) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+type GrantAccessResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	File          *FileInfo              `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GrantAccessResponse) Reset() {
+	*x = GrantAccessResponse{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GrantAccessResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GrantAccessResponse) ProtoMessage() {}
+
+func (x *GrantAccessResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GrantAccessResponse.ProtoReflect.Descriptor instead.
+func (*GrantAccessResponse) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *GrantAccessResponse) GetFile() *FileInfo {
+	if x != nil {
+		return x.File
+	}
+	return nil
+}
+
+type RevokeAccessRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileId        string                 `protobuf:"bytes,1,opt,name=file_id,json=fileId,proto3" json:"file_id,omitempty"`
+	Subject       string                 `protobuf:"bytes,2,opt,name=subject,proto3" json:"subject,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeAccessRequest) Reset() {
+	*x = RevokeAccessRequest{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeAccessRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeAccessRequest) ProtoMessage() {}
+
+func (x *RevokeAccessRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeAccessRequest.ProtoReflect.Descriptor instead.
+func (*RevokeAccessRequest) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *RevokeAccessRequest) GetFileId() string {
+	if x != nil {
+		return x.FileId
+	}
+	return ""
+}
+
+func (x *RevokeAccessRequest) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+type RevokeAccessResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	File          *FileInfo              `protobuf:"bytes,1,opt,name=file,proto3" json:"file,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeAccessResponse) Reset() {
+	*x = RevokeAccessResponse{}
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeAccessResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeAccessResponse) ProtoMessage() {}
+
+func (x *RevokeAccessResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_upload_service_v1_upload_service_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeAccessResponse.ProtoReflect.Descriptor instead.
+func (*RevokeAccessResponse) Descriptor() ([]byte, []int) {
+	return file_proto_upload_service_v1_upload_service_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *RevokeAccessResponse) GetFile() *FileInfo {
+	if x != nil {
+		return x.File
+	}
+	return nil
+}
+
+var File_proto_upload_service_v1_upload_service_proto protoreflect.FileDescriptor
+
+const file_proto_upload_service_v1_upload_service_proto_rawDesc = "" +
+	"\n" +
+	",proto/upload_service/v1/upload_service.proto\x12\x11upload_service.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x8d\x03\n" +
+	"\bFileInfo\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x1a\n" +
+	"\bfilename\x18\x02 \x01(\tR\bfilename\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x12\n" +
+	"\x04size\x18\x05 \x01(\x04R\x04size\x12!\n" +
+	"\fcontent_type\x18\x06 \x01(\tR\vcontentType\x12:\n" +
+	"\tchecksums\x18\a \x01(\v2\x1c.upload_service.v1.ChecksumsR\tchecksums\x12\x18\n" +
+	"\aversion\x18\b \x01(\x04R\aversion\x12\x12\n" +
+	"\x04etag\x18\t \x01(\tR\x04etag\x12\x14\n" +
+	"\x05owner\x18\n" +
+	" \x01(\tR\x05owner\x12\x1f\n" +
+	"\vshared_with\x18\v \x03(\tR\n" +
+	"sharedWith\";\n" +
+	"\tChecksums\x12\x16\n" +
+	"\x06sha256\x18\x01 \x01(\tR\x06sha256\x12\x16\n" +
+	"\x06crc32c\x18\x02 \x01(\tR\x06crc32c\"\x89\x01\n" +
+	"\rUploadRequest\x12\x1c\n" +
+	"\bfilename\x18\x01 \x01(\tH\x00R\bfilename\x12\x16\n" +
+	"\x05chunk\x18\x02 \x01(\fH\x00R\x05chunk\x12:\n" +
+	"\tchecksums\x18\x03 \x01(\v2\x1c.upload_service.v1.ChecksumsR\tchecksumsB\x06\n" +
+	"\x04data\"y\n" +
+	"\x0eUploadResponse\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x04R\x04size\x12:\n" +
+	"\tchecksums\x18\x03 \x01(\v2\x1c.upload_service.v1.ChecksumsR\tchecksums\"\xd8\x01\n" +
+	"\x11UpdateFileRequest\x12\x19\n" +
+	"\afile_id\x18\x01 \x01(\tH\x00R\x06fileId\x12\x16\n" +
+	"\x05chunk\x18\x02 \x01(\fH\x00R\x05chunk\x12:\n" +
+	"\tchecksums\x18\x03 \x01(\v2\x1c.upload_service.v1.ChecksumsR\tchecksums\x12\"\n" +
+	"\rif_match_etag\x18\x04 \x01(\tR\vifMatchEtag\x12(\n" +
+	"\x10if_match_version\x18\x05 \x01(\x04R\x0eifMatchVersionB\x06\n" +
+	"\x04data\"\xb2\x01\n" +
+	"\x12UpdateFileResponse\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x19\n" +
+	"\bnew_size\x18\x02 \x01(\x04R\anewSize\x12:\n" +
+	"\tchecksums\x18\x03 \x01(\v2\x1c.upload_service.v1.ChecksumsR\tchecksums\x12\x12\n" +
+	"\x04etag\x18\x04 \x01(\tR\x04etag\x12\x18\n" +
+	"\aversion\x18\x05 \x01(\x04R\aversion\"\xdb\x01\n" +
+	"\vListRequest\x12\x1b\n" +
+	"\tpage_size\x18\x01 \x01(\rR\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x02 \x01(\tR\tpageToken\x125\n" +
+	"\x06filter\x18\x03 \x01(\v2\x1d.upload_service.v1.ListFilterR\x06filter\x129\n" +
+	"\asort_by\x18\x04 \x01(\x0e2 .upload_service.v1.ListSortFieldR\x06sortBy\x12\x1e\n" +
+	"\n" +
+	"descending\x18\x05 \x01(\bR\n" +
+	"descending\"\x96\x03\n" +
+	"\n" +
+	"ListFilter\x12'\n" +
+	"\x0ffilename_prefix\x18\x01 \x01(\tR\x0efilenamePrefix\x12!\n" +
+	"\fcontent_type\x18\x02 \x01(\tR\vcontentType\x12?\n" +
+	"\rcreated_after\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\fcreatedAfter\x12A\n" +
+	"\x0ecreated_before\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\rcreatedBefore\x12?\n" +
+	"\rupdated_after\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\fupdatedAfter\x12A\n" +
+	"\x0eupdated_before\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\rupdatedBefore\x12\x19\n" +
+	"\bmin_size\x18\a \x01(\x04R\aminSize\x12\x19\n" +
+	"\bmax_size\x18\b \x01(\x04R\amaxSize\"i\n" +
+	"\fListResponse\x121\n" +
+	"\x05files\x18\x01 \x03(\v2\x1b.upload_service.v1.FileInfoR\x05files\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"x\n" +
+	"\x13DownloadLinkRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12%\n" +
+	"\x0eexpiry_seconds\x18\x02 \x01(\rR\rexpirySeconds\x12!\n" +
+	"\fcontent_type\x18\x03 \x01(\tR\vcontentType\"c\n" +
+	"\x14DownloadLinkResponse\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x129\n" +
+	"\n" +
+	"expires_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"^\n" +
+	"\x13DownloadFileRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x16\n" +
+	"\x06offset\x18\x02 \x01(\x04R\x06offset\x12\x16\n" +
+	"\x06length\x18\x03 \x01(\x04R\x06length\"u\n" +
+	"\x12DownloadFileHeader\x12/\n" +
+	"\x04file\x18\x01 \x01(\v2\x1b.upload_service.v1.FileInfoR\x04file\x12\x16\n" +
+	"\x06offset\x18\x02 \x01(\x04R\x06offset\x12\x16\n" +
+	"\x06length\x18\x03 \x01(\x04R\x06length\"w\n" +
+	"\x14DownloadFileResponse\x12?\n" +
+	"\x06header\x18\x01 \x01(\v2%.upload_service.v1.DownloadFileHeaderH\x00R\x06header\x12\x16\n" +
+	"\x05chunk\x18\x02 \x01(\fH\x00R\x05chunkB\x06\n" +
+	"\x04data\"\x81\x01\n" +
+	"\x12DownloadZipRequest\x12\x19\n" +
+	"\bfile_ids\x18\x01 \x03(\tR\afileIds\x12\x16\n" +
+	"\x06strict\x18\x02 \x01(\bR\x06strict\x128\n" +
+	"\x06format\x18\x03 \x01(\x0e2 .upload_service.v1.ArchiveFormatR\x06format\"+\n" +
+	"\x13DownloadZipResponse\x12\x14\n" +
+	"\x05chunk\x18\x01 \x01(\fR\x05chunk\"8\n" +
+	"\x1aCreateUploadSessionRequest\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\"\x94\x01\n" +
+	"\x1bCreateUploadSessionResponse\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x1b\n" +
+	"\tpart_size\x18\x02 \x01(\x04R\bpartSize\x129\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"L\n" +
+	"\x13UploadSessionOffset\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x16\n" +
+	"\x06offset\x18\x02 \x01(\x04R\x06offset\"~\n" +
+	"\x1aAppendUploadSessionRequest\x12@\n" +
+	"\x06header\x18\x01 \x01(\v2&.upload_service.v1.UploadSessionOffsetH\x00R\x06header\x12\x16\n" +
+	"\x05chunk\x18\x02 \x01(\fH\x00R\x05chunkB\x06\n" +
+	"\x04data\";\n" +
+	"\x1aUploadSessionStatusRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\"\xb6\x01\n" +
+	"\x1bUploadSessionStatusResponse\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12%\n" +
+	"\x0ecommitted_size\x18\x02 \x01(\x04R\rcommittedSize\x12\x16\n" +
+	"\x06sealed\x18\x03 \x01(\bR\x06sealed\x129\n" +
+	"\n" +
+	"expires_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"=\n" +
+	"\x1cFinalizeUploadSessionRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\"n\n" +
+	"\x19CreateDirectUploadRequest\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x04R\x04size\x12!\n" +
+	"\fcontent_type\x18\x03 \x01(\tR\vcontentType\"\xa1\x02\n" +
+	"\x1aCreateDirectUploadResponse\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\x12^\n" +
+	"\vform_fields\x18\x03 \x03(\v2=.upload_service.v1.CreateDirectUploadResponse.FormFieldsEntryR\n" +
+	"formFields\x129\n" +
+	"\n" +
+	"expires_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x1a=\n" +
+	"\x0fFormFieldsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"6\n" +
+	"\x1bCompleteDirectUploadRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\"J\n" +
+	"\x11DeleteFileRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x1c\n" +
+	"\tpermanent\x18\x02 \x01(\bR\tpermanent\"d\n" +
+	"\x12DeleteFileResponse\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x125\n" +
+	"\bpurge_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\apurgeAt\"-\n" +
+	"\x12RestoreFileRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\"F\n" +
+	"\x13RestoreFileResponse\x12/\n" +
+	"\x04file\x18\x01 \x01(\v2\x1b.upload_service.v1.FileInfoR\x04file\"\x12\n" +
+	"\x10ListTrashRequest\"\xb0\x01\n" +
+	"\vTrashedFile\x12/\n" +
+	"\x04file\x18\x01 \x01(\v2\x1b.upload_service.v1.FileInfoR\x04file\x129\n" +
+	"\n" +
+	"deleted_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tdeletedAt\x125\n" +
+	"\bpurge_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\apurgeAt\"I\n" +
+	"\x11ListTrashResponse\x124\n" +
+	"\x05files\x18\x01 \x03(\v2\x1e.upload_service.v1.TrashedFileR\x05files\"\x11\n" +
+	"\x0fGetStatsRequest\"\xa7\x01\n" +
+	"\x10GetStatsResponse\x12\x14\n" +
+	"\x05files\x18\x01 \x01(\x04R\x05files\x12#\n" +
+	"\rlogical_bytes\x18\x02 \x01(\x04R\flogicalBytes\x12!\n" +
+	"\fstored_bytes\x18\x03 \x01(\x04R\vstoredBytes\x12\x14\n" +
+	"\x05blobs\x18\x04 \x01(\x04R\x05blobs\x12\x1f\n" +
+	"\vdedup_ratio\x18\x05 \x01(\x01R\n" +
+	"dedupRatio\".\n" +
+	"\x13ListVersionsRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\"\xaf\x01\n" +
+	"\vFileVersion\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\x04R\aversion\x12/\n" +
+	"\x04file\x18\x02 \x01(\v2\x1b.upload_service.v1.FileInfoR\x04file\x12\x18\n" +
+	"\acurrent\x18\x03 \x01(\bR\acurrent\x12;\n" +
+	"\varchived_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"archivedAt\"R\n" +
+	"\x14ListVersionsResponse\x12:\n" +
+	"\bversions\x18\x01 \x03(\v2\x1e.upload_service.v1.FileVersionR\bversions\"\x99\x01\n" +
+	"\x1aVersionDownloadLinkRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x04R\aversion\x12%\n" +
+	"\x0eexpiry_seconds\x18\x03 \x01(\rR\rexpirySeconds\x12!\n" +
+	"\fcontent_type\x18\x04 \x01(\tR\vcontentType\"H\n" +
+	"\x13RollbackFileRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x04R\aversion\"G\n" +
+	"\x14RollbackFileResponse\x12/\n" +
+	"\x04file\x18\x01 \x01(\v2\x1b.upload_service.v1.FileInfoR\x04file\"\x99\x01\n" +
+	"\x16CreateShareLinkRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\x12#\n" +
+	"\rmax_downloads\x18\x03 \x01(\x04R\fmaxDownloads\x12%\n" +
+	"\x0eexpiry_seconds\x18\x04 \x01(\x03R\rexpirySeconds\"\xa8\x02\n" +
+	"\tShareLink\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x17\n" +
+	"\afile_id\x18\x02 \x01(\tR\x06fileId\x12\x10\n" +
+	"\x03url\x18\x03 \x01(\tR\x03url\x12!\n" +
+	"\fhas_password\x18\x04 \x01(\bR\vhasPassword\x12#\n" +
+	"\rmax_downloads\x18\x05 \x01(\x04R\fmaxDownloads\x12\x1c\n" +
+	"\tdownloads\x18\x06 \x01(\x04R\tdownloads\x129\n" +
+	"\n" +
+	"created_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"expires_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\"0\n" +
+	"\x15ListShareLinksRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\"L\n" +
+	"\x16ListShareLinksResponse\x122\n" +
+	"\x05links\x18\x01 \x03(\v2\x1c.upload_service.v1.ShareLinkR\x05links\".\n" +
+	"\x16RevokeShareLinkRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\"\x19\n" +
+	"\x17RevokeShareLinkResponse\"G\n" +
+	"\x12GrantAccessRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x18\n" +
+	"\asubject\x18\x02 \x01(\tR\asubject\"F\n" +
+	"\x13GrantAccessResponse\x12/\n" +
+	"\x04file\x18\x01 \x01(\v2\x1b.upload_service.v1.FileInfoR\x04file\"H\n" +
+	"\x13RevokeAccessRequest\x12\x17\n" +
+	"\afile_id\x18\x01 \x01(\tR\x06fileId\x12\x18\n" +
+	"\asubject\x18\x02 \x01(\tR\asubject\"G\n" +
+	"\x14RevokeAccessResponse\x12/\n" +
+	"\x04file\x18\x01 \x01(\v2\x1b.upload_service.v1.FileInfoR\x04file*\xa8\x01\n" +
+	"\rListSortField\x12\x1f\n" +
+	"\x1bLIST_SORT_FIELD_UNSPECIFIED\x10\x00\x12\x1c\n" +
+	"\x18LIST_SORT_FIELD_FILENAME\x10\x01\x12\x1e\n" +
+	"\x1aLIST_SORT_FIELD_CREATED_AT\x10\x02\x12\x1e\n" +
+	"\x1aLIST_SORT_FIELD_UPDATED_AT\x10\x03\x12\x18\n" +
+	"\x14LIST_SORT_FIELD_SIZE\x10\x04*\x96\x01\n" +
+	"\rArchiveFormat\x12\x1e\n" +
+	"\x1aARCHIVE_FORMAT_UNSPECIFIED\x10\x00\x12\x16\n" +
+	"\x12ARCHIVE_FORMAT_ZIP\x10\x01\x12\x16\n" +
+	"\x12ARCHIVE_FORMAT_TAR\x10\x02\x12\x19\n" +
+	"\x15ARCHIVE_FORMAT_TAR_GZ\x10\x03\x12\x1a\n" +
+	"\x16ARCHIVE_FORMAT_TAR_ZST\x10\x042\xe8\x12\n" +
+	"\vFileService\x12O\n" +
+	"\x06Upload\x12 .upload_service.v1.UploadRequest\x1a!.upload_service.v1.UploadResponse(\x01\x12[\n" +
+	"\n" +
+	"UpdateFile\x12$.upload_service.v1.UpdateFileRequest\x1a%.upload_service.v1.UpdateFileResponse(\x01\x12L\n" +
+	"\tListFiles\x12\x1e.upload_service.v1.ListRequest\x1a\x1f.upload_service.v1.ListResponse\x12b\n" +
+	"\x0fGetDownloadLink\x12&.upload_service.v1.DownloadLinkRequest\x1a'.upload_service.v1.DownloadLinkResponse\x12a\n" +
+	"\fDownloadFile\x12&.upload_service.v1.DownloadFileRequest\x1a'.upload_service.v1.DownloadFileResponse0\x01\x12^\n" +
+	"\vDownloadZip\x12%.upload_service.v1.DownloadZipRequest\x1a&.upload_service.v1.DownloadZipResponse0\x01\x12t\n" +
+	"\x13CreateUploadSession\x12-.upload_service.v1.CreateUploadSessionRequest\x1a..upload_service.v1.CreateUploadSessionResponse\x12v\n" +
+	"\x13AppendUploadSession\x12-.upload_service.v1.AppendUploadSessionRequest\x1a..upload_service.v1.UploadSessionStatusResponse(\x01\x12w\n" +
+	"\x16GetUploadSessionStatus\x12-.upload_service.v1.UploadSessionStatusRequest\x1a..upload_service.v1.UploadSessionStatusResponse\x12k\n" +
+	"\x15FinalizeUploadSession\x12/.upload_service.v1.FinalizeUploadSessionRequest\x1a!.upload_service.v1.UploadResponse\x12q\n" +
+	"\x12CreateDirectUpload\x12,.upload_service.v1.CreateDirectUploadRequest\x1a-.upload_service.v1.CreateDirectUploadResponse\x12i\n" +
+	"\x14CompleteDirectUpload\x12..upload_service.v1.CompleteDirectUploadRequest\x1a!.upload_service.v1.UploadResponse\x12Y\n" +
+	"\n" +
+	"DeleteFile\x12$.upload_service.v1.DeleteFileRequest\x1a%.upload_service.v1.DeleteFileResponse\x12\\\n" +
+	"\vRestoreFile\x12%.upload_service.v1.RestoreFileRequest\x1a&.upload_service.v1.RestoreFileResponse\x12V\n" +
+	"\tListTrash\x12#.upload_service.v1.ListTrashRequest\x1a$.upload_service.v1.ListTrashResponse\x12_\n" +
+	"\fListVersions\x12&.upload_service.v1.ListVersionsRequest\x1a'.upload_service.v1.ListVersionsResponse\x12p\n" +
+	"\x16GetVersionDownloadLink\x12-.upload_service.v1.VersionDownloadLinkRequest\x1a'.upload_service.v1.DownloadLinkResponse\x12_\n" +
+	"\fRollbackFile\x12&.upload_service.v1.RollbackFileRequest\x1a'.upload_service.v1.RollbackFileResponse\x12S\n" +
+	"\bGetStats\x12\".upload_service.v1.GetStatsRequest\x1a#.upload_service.v1.GetStatsResponse\x12Z\n" +
+	"\x0fCreateShareLink\x12).upload_service.v1.CreateShareLinkRequest\x1a\x1c.upload_service.v1.ShareLink\x12e\n" +
+	"\x0eListShareLinks\x12(.upload_service.v1.ListShareLinksRequest\x1a).upload_service.v1.ListShareLinksResponse\x12h\n" +
+	"\x0fRevokeShareLink\x12).upload_service.v1.RevokeShareLinkRequest\x1a*.upload_service.v1.RevokeShareLinkResponse\x12\\\n" +
+	"\vGrantAccess\x12%.upload_service.v1.GrantAccessRequest\x1a&.upload_service.v1.GrantAccessResponse\x12_\n" +
+	"\fRevokeAccess\x12&.upload_service.v1.RevokeAccessRequest\x1a'.upload_service.v1.RevokeAccessResponseB\x03Z\x01.b\x06proto3"
+
+var (
+	file_proto_upload_service_v1_upload_service_proto_rawDescOnce sync.Once
+	file_proto_upload_service_v1_upload_service_proto_rawDescData []byte
+)
+
+func file_proto_upload_service_v1_upload_service_proto_rawDescGZIP() []byte {
+	file_proto_upload_service_v1_upload_service_proto_rawDescOnce.Do(func() {
+		file_proto_upload_service_v1_upload_service_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_upload_service_v1_upload_service_proto_rawDesc), len(file_proto_upload_service_v1_upload_service_proto_rawDesc)))
+	})
+	return file_proto_upload_service_v1_upload_service_proto_rawDescData
+}
+
+var file_proto_upload_service_v1_upload_service_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_proto_upload_service_v1_upload_service_proto_msgTypes = make([]protoimpl.MessageInfo, 52)
+var file_proto_upload_service_v1_upload_service_proto_goTypes = []any{
+	(ListSortField)(0),                   // 0: upload_service.v1.ListSortField
+	(ArchiveFormat)(0),                   // 1: upload_service.v1.ArchiveFormat
+	(*FileInfo)(nil),                     // 2: upload_service.v1.FileInfo
+	(*Checksums)(nil),                    // 3: upload_service.v1.Checksums
+	(*UploadRequest)(nil),                // 4: upload_service.v1.UploadRequest
+	(*UploadResponse)(nil),               // 5: upload_service.v1.UploadResponse
+	(*UpdateFileRequest)(nil),            // 6: upload_service.v1.UpdateFileRequest
+	(*UpdateFileResponse)(nil),           // 7: upload_service.v1.UpdateFileResponse
+	(*ListRequest)(nil),                  // 8: upload_service.v1.ListRequest
+	(*ListFilter)(nil),                   // 9: upload_service.v1.ListFilter
+	(*ListResponse)(nil),                 // 10: upload_service.v1.ListResponse
+	(*DownloadLinkRequest)(nil),          // 11: upload_service.v1.DownloadLinkRequest
+	(*DownloadLinkResponse)(nil),         // 12: upload_service.v1.DownloadLinkResponse
+	(*DownloadFileRequest)(nil),          // 13: upload_service.v1.DownloadFileRequest
+	(*DownloadFileHeader)(nil),           // 14: upload_service.v1.DownloadFileHeader
+	(*DownloadFileResponse)(nil),         // 15: upload_service.v1.DownloadFileResponse
+	(*DownloadZipRequest)(nil),           // 16: upload_service.v1.DownloadZipRequest
+	(*DownloadZipResponse)(nil),          // 17: upload_service.v1.DownloadZipResponse
+	(*CreateUploadSessionRequest)(nil),   // 18: upload_service.v1.CreateUploadSessionRequest
+	(*CreateUploadSessionResponse)(nil),  // 19: upload_service.v1.CreateUploadSessionResponse
+	(*UploadSessionOffset)(nil),          // 20: upload_service.v1.UploadSessionOffset
+	(*AppendUploadSessionRequest)(nil),   // 21: upload_service.v1.AppendUploadSessionRequest
+	(*UploadSessionStatusRequest)(nil),   // 22: upload_service.v1.UploadSessionStatusRequest
+	(*UploadSessionStatusResponse)(nil),  // 23: upload_service.v1.UploadSessionStatusResponse
+	(*FinalizeUploadSessionRequest)(nil), // 24: upload_service.v1.FinalizeUploadSessionRequest
+	(*CreateDirectUploadRequest)(nil),    // 25: upload_service.v1.CreateDirectUploadRequest
+	(*CreateDirectUploadResponse)(nil),   // 26: upload_service.v1.CreateDirectUploadResponse
+	(*CompleteDirectUploadRequest)(nil),  // 27: upload_service.v1.CompleteDirectUploadRequest
+	(*DeleteFileRequest)(nil),            // 28: upload_service.v1.DeleteFileRequest
+	(*DeleteFileResponse)(nil),           // 29: upload_service.v1.DeleteFileResponse
+	(*RestoreFileRequest)(nil),           // 30: upload_service.v1.RestoreFileRequest
+	(*RestoreFileResponse)(nil),          // 31: upload_service.v1.RestoreFileResponse
+	(*ListTrashRequest)(nil),             // 32: upload_service.v1.ListTrashRequest
+	(*TrashedFile)(nil),                  // 33: upload_service.v1.TrashedFile
+	(*ListTrashResponse)(nil),            // 34: upload_service.v1.ListTrashResponse
+	(*GetStatsRequest)(nil),              // 35: upload_service.v1.GetStatsRequest
+	(*GetStatsResponse)(nil),             // 36: upload_service.v1.GetStatsResponse
+	(*ListVersionsRequest)(nil),          // 37: upload_service.v1.ListVersionsRequest
+	(*FileVersion)(nil),                  // 38: upload_service.v1.FileVersion
+	(*ListVersionsResponse)(nil),         // 39: upload_service.v1.ListVersionsResponse
+	(*VersionDownloadLinkRequest)(nil),   // 40: upload_service.v1.VersionDownloadLinkRequest
+	(*RollbackFileRequest)(nil),          // 41: upload_service.v1.RollbackFileRequest
+	(*RollbackFileResponse)(nil),         // 42: upload_service.v1.RollbackFileResponse
+	(*CreateShareLinkRequest)(nil),       // 43: upload_service.v1.CreateShareLinkRequest
+	(*ShareLink)(nil),                    // 44: upload_service.v1.ShareLink
+	(*ListShareLinksRequest)(nil),        // 45: upload_service.v1.ListShareLinksRequest
+	(*ListShareLinksResponse)(nil),       // 46: upload_service.v1.ListShareLinksResponse
+	(*RevokeShareLinkRequest)(nil),       // 47: upload_service.v1.RevokeShareLinkRequest
+	(*RevokeShareLinkResponse)(nil),      // 48: upload_service.v1.RevokeShareLinkResponse
+	(*GrantAccessRequest)(nil),           // 49: upload_service.v1.GrantAccessRequest
+	(*GrantAccessResponse)(nil),          // 50: upload_service.v1.GrantAccessResponse
+	(*RevokeAccessRequest)(nil),          // 51: upload_service.v1.RevokeAccessRequest
+	(*RevokeAccessResponse)(nil),         // 52: upload_service.v1.RevokeAccessResponse
+	nil,                                  // 53: upload_service.v1.CreateDirectUploadResponse.FormFieldsEntry
+	(*timestamppb.Timestamp)(nil),        // 54: google.protobuf.Timestamp
+}
+var file_proto_upload_service_v1_upload_service_proto_depIdxs = []int32{
+	54, // 0: upload_service.v1.FileInfo.created_at:type_name -> google.protobuf.Timestamp
+	54, // 1: upload_service.v1.FileInfo.updated_at:type_name -> google.protobuf.Timestamp
+	3,  // 2: upload_service.v1.FileInfo.checksums:type_name -> upload_service.v1.Checksums
+	3,  // 3: upload_service.v1.UploadRequest.checksums:type_name -> upload_service.v1.Checksums
+	3,  // 4: upload_service.v1.UploadResponse.checksums:type_name -> upload_service.v1.Checksums
+	3,  // 5: upload_service.v1.UpdateFileRequest.checksums:type_name -> upload_service.v1.Checksums
+	3,  // 6: upload_service.v1.UpdateFileResponse.checksums:type_name -> upload_service.v1.Checksums
+	9,  // 7: upload_service.v1.ListRequest.filter:type_name -> upload_service.v1.ListFilter
+	0,  // 8: upload_service.v1.ListRequest.sort_by:type_name -> upload_service.v1.ListSortField
+	54, // 9: upload_service.v1.ListFilter.created_after:type_name -> google.protobuf.Timestamp
+	54, // 10: upload_service.v1.ListFilter.created_before:type_name -> google.protobuf.Timestamp
+	54, // 11: upload_service.v1.ListFilter.updated_after:type_name -> google.protobuf.Timestamp
+	54, // 12: upload_service.v1.ListFilter.updated_before:type_name -> google.protobuf.Timestamp
+	2,  // 13: upload_service.v1.ListResponse.files:type_name -> upload_service.v1.FileInfo
+	54, // 14: upload_service.v1.DownloadLinkResponse.expires_at:type_name -> google.protobuf.Timestamp
+	2,  // 15: upload_service.v1.DownloadFileHeader.file:type_name -> upload_service.v1.FileInfo
+	14, // 16: upload_service.v1.DownloadFileResponse.header:type_name -> upload_service.v1.DownloadFileHeader
+	1,  // 17: upload_service.v1.DownloadZipRequest.format:type_name -> upload_service.v1.ArchiveFormat
+	54, // 18: upload_service.v1.CreateUploadSessionResponse.expires_at:type_name -> google.protobuf.Timestamp
+	20, // 19: upload_service.v1.AppendUploadSessionRequest.header:type_name -> upload_service.v1.UploadSessionOffset
+	54, // 20: upload_service.v1.UploadSessionStatusResponse.expires_at:type_name -> google.protobuf.Timestamp
+	53, // 21: upload_service.v1.CreateDirectUploadResponse.form_fields:type_name -> upload_service.v1.CreateDirectUploadResponse.FormFieldsEntry
+	54, // 22: upload_service.v1.CreateDirectUploadResponse.expires_at:type_name -> google.protobuf.Timestamp
+	54, // 23: upload_service.v1.DeleteFileResponse.purge_at:type_name -> google.protobuf.Timestamp
+	2,  // 24: upload_service.v1.RestoreFileResponse.file:type_name -> upload_service.v1.FileInfo
+	2,  // 25: upload_service.v1.TrashedFile.file:type_name -> upload_service.v1.FileInfo
+	54, // 26: upload_service.v1.TrashedFile.deleted_at:type_name -> google.protobuf.Timestamp
+	54, // 27: upload_service.v1.TrashedFile.purge_at:type_name -> google.protobuf.Timestamp
+	33, // 28: upload_service.v1.ListTrashResponse.files:type_name -> upload_service.v1.TrashedFile
+	2,  // 29: upload_service.v1.FileVersion.file:type_name -> upload_service.v1.FileInfo
+	54, // 30: upload_service.v1.FileVersion.archived_at:type_name -> google.protobuf.Timestamp
+	38, // 31: upload_service.v1.ListVersionsResponse.versions:type_name -> upload_service.v1.FileVersion
+	2,  // 32: upload_service.v1.RollbackFileResponse.file:type_name -> upload_service.v1.FileInfo
+	54, // 33: upload_service.v1.ShareLink.created_at:type_name -> google.protobuf.Timestamp
+	54, // 34: upload_service.v1.ShareLink.expires_at:type_name -> google.protobuf.Timestamp
+	44, // 35: upload_service.v1.ListShareLinksResponse.links:type_name -> upload_service.v1.ShareLink
+	2,  // 36: upload_service.v1.GrantAccessResponse.file:type_name -> upload_service.v1.FileInfo
+	2,  // 37: upload_service.v1.RevokeAccessResponse.file:type_name -> upload_service.v1.FileInfo
+	4,  // 38: upload_service.v1.FileService.Upload:input_type -> upload_service.v1.UploadRequest
+	6,  // 39: upload_service.v1.FileService.UpdateFile:input_type -> upload_service.v1.UpdateFileRequest
+	8,  // 40: upload_service.v1.FileService.ListFiles:input_type -> upload_service.v1.ListRequest
+	11, // 41: upload_service.v1.FileService.GetDownloadLink:input_type -> upload_service.v1.DownloadLinkRequest
+	13, // 42: upload_service.v1.FileService.DownloadFile:input_type -> upload_service.v1.DownloadFileRequest
+	16, // 43: upload_service.v1.FileService.DownloadZip:input_type -> upload_service.v1.DownloadZipRequest
+	18, // 44: upload_service.v1.FileService.CreateUploadSession:input_type -> upload_service.v1.CreateUploadSessionRequest
+	21, // 45: upload_service.v1.FileService.AppendUploadSession:input_type -> upload_service.v1.AppendUploadSessionRequest
+	22, // 46: upload_service.v1.FileService.GetUploadSessionStatus:input_type -> upload_service.v1.UploadSessionStatusRequest
+	24, // 47: upload_service.v1.FileService.FinalizeUploadSession:input_type -> upload_service.v1.FinalizeUploadSessionRequest
+	25, // 48: upload_service.v1.FileService.CreateDirectUpload:input_type -> upload_service.v1.CreateDirectUploadRequest
+	27, // 49: upload_service.v1.FileService.CompleteDirectUpload:input_type -> upload_service.v1.CompleteDirectUploadRequest
+	28, // 50: upload_service.v1.FileService.DeleteFile:input_type -> upload_service.v1.DeleteFileRequest
+	30, // 51: upload_service.v1.FileService.RestoreFile:input_type -> upload_service.v1.RestoreFileRequest
+	32, // 52: upload_service.v1.FileService.ListTrash:input_type -> upload_service.v1.ListTrashRequest
+	37, // 53: upload_service.v1.FileService.ListVersions:input_type -> upload_service.v1.ListVersionsRequest
+	40, // 54: upload_service.v1.FileService.GetVersionDownloadLink:input_type -> upload_service.v1.VersionDownloadLinkRequest
+	41, // 55: upload_service.v1.FileService.RollbackFile:input_type -> upload_service.v1.RollbackFileRequest
+	35, // 56: upload_service.v1.FileService.GetStats:input_type -> upload_service.v1.GetStatsRequest
+	43, // 57: upload_service.v1.FileService.CreateShareLink:input_type -> upload_service.v1.CreateShareLinkRequest
+	45, // 58: upload_service.v1.FileService.ListShareLinks:input_type -> upload_service.v1.ListShareLinksRequest
+	47, // 59: upload_service.v1.FileService.RevokeShareLink:input_type -> upload_service.v1.RevokeShareLinkRequest
+	49, // 60: upload_service.v1.FileService.GrantAccess:input_type -> upload_service.v1.GrantAccessRequest
+	51, // 61: upload_service.v1.FileService.RevokeAccess:input_type -> upload_service.v1.RevokeAccessRequest
+	5,  // 62: upload_service.v1.FileService.Upload:output_type -> upload_service.v1.UploadResponse
+	7,  // 63: upload_service.v1.FileService.UpdateFile:output_type -> upload_service.v1.UpdateFileResponse
+	10, // 64: upload_service.v1.FileService.ListFiles:output_type -> upload_service.v1.ListResponse
+	12, // 65: upload_service.v1.FileService.GetDownloadLink:output_type -> upload_service.v1.DownloadLinkResponse
+	15, // 66: upload_service.v1.FileService.DownloadFile:output_type -> upload_service.v1.DownloadFileResponse
+	17, // 67: upload_service.v1.FileService.DownloadZip:output_type -> upload_service.v1.DownloadZipResponse
+	19, // 68: upload_service.v1.FileService.CreateUploadSession:output_type -> upload_service.v1.CreateUploadSessionResponse
+	23, // 69: upload_service.v1.FileService.AppendUploadSession:output_type -> upload_service.v1.UploadSessionStatusResponse
+	23, // 70: upload_service.v1.FileService.GetUploadSessionStatus:output_type -> upload_service.v1.UploadSessionStatusResponse
+	5,  // 71: upload_service.v1.FileService.FinalizeUploadSession:output_type -> upload_service.v1.UploadResponse
+	26, // 72: upload_service.v1.FileService.CreateDirectUpload:output_type -> upload_service.v1.CreateDirectUploadResponse
+	5,  // 73: upload_service.v1.FileService.CompleteDirectUpload:output_type -> upload_service.v1.UploadResponse
+	29, // 74: upload_service.v1.FileService.DeleteFile:output_type -> upload_service.v1.DeleteFileResponse
+	31, // 75: upload_service.v1.FileService.RestoreFile:output_type -> upload_service.v1.RestoreFileResponse
+	34, // 76: upload_service.v1.FileService.ListTrash:output_type -> upload_service.v1.ListTrashResponse
+	39, // 77: upload_service.v1.FileService.ListVersions:output_type -> upload_service.v1.ListVersionsResponse
+	12, // 78: upload_service.v1.FileService.GetVersionDownloadLink:output_type -> upload_service.v1.DownloadLinkResponse
+	42, // 79: upload_service.v1.FileService.RollbackFile:output_type -> upload_service.v1.RollbackFileResponse
+	36, // 80: upload_service.v1.FileService.GetStats:output_type -> upload_service.v1.GetStatsResponse
+	44, // 81: upload_service.v1.FileService.CreateShareLink:output_type -> upload_service.v1.ShareLink
+	46, // 82: upload_service.v1.FileService.ListShareLinks:output_type -> upload_service.v1.ListShareLinksResponse
+	48, // 83: upload_service.v1.FileService.RevokeShareLink:output_type -> upload_service.v1.RevokeShareLinkResponse
+	50, // 84: upload_service.v1.FileService.GrantAccess:output_type -> upload_service.v1.GrantAccessResponse
+	52, // 85: upload_service.v1.FileService.RevokeAccess:output_type -> upload_service.v1.RevokeAccessResponse
+	62, // [62:86] is the sub-list for method output_type
+	38, // [38:62] is the sub-list for method input_type
+	38, // [38:38] is the sub-list for extension type_name
+	38, // [38:38] is the sub-list for extension extendee
+	0,  // [0:38] is the sub-list for field type_name
+}
+
+func init() { file_proto_upload_service_v1_upload_service_proto_init() }
+func file_proto_upload_service_v1_upload_service_proto_init() {
+	if File_proto_upload_service_v1_upload_service_proto != nil {
+		return
+	}
+	file_proto_upload_service_v1_upload_service_proto_msgTypes[2].OneofWrappers = []any{
+		(*UploadRequest_Filename)(nil),
+		(*UploadRequest_Chunk)(nil),
+	}
+	file_proto_upload_service_v1_upload_service_proto_msgTypes[4].OneofWrappers = []any{
+		(*UpdateFileRequest_FileId)(nil),
+		(*UpdateFileRequest_Chunk)(nil),
+	}
+	file_proto_upload_service_v1_upload_service_proto_msgTypes[13].OneofWrappers = []any{
+		(*DownloadFileResponse_Header)(nil),
+		(*DownloadFileResponse_Chunk)(nil),
+	}
+	file_proto_upload_service_v1_upload_service_proto_msgTypes[19].OneofWrappers = []any{
+		(*AppendUploadSessionRequest_Header)(nil),
+		(*AppendUploadSessionRequest_Chunk)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_upload_service_v1_upload_service_proto_rawDesc), len(file_proto_upload_service_v1_upload_service_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   52,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_proto_upload_service_v1_upload_service_proto_goTypes,
+		DependencyIndexes: file_proto_upload_service_v1_upload_service_proto_depIdxs,
+		EnumInfos:         file_proto_upload_service_v1_upload_service_proto_enumTypes,
+		MessageInfos:      file_proto_upload_service_v1_upload_service_proto_msgTypes,
+	}.Build()
+	File_proto_upload_service_v1_upload_service_proto = out.File
+	file_proto_upload_service_v1_upload_service_proto_goTypes = nil
+	file_proto_upload_service_v1_upload_service_proto_depIdxs = nil
+}
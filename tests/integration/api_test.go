@@ -5,12 +5,17 @@ import (
 	"bytes"
 	"context"
 	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	pb "github.com/1abobik1/proto-upload-service/gen/go/upload_service/v1"
 	"github.com/1abobik1/upload_file_service/internal/handler"
+	"github.com/1abobik1/upload_file_service/internal/models"
 	"github.com/1abobik1/upload_file_service/internal/service"
 	"github.com/1abobik1/upload_file_service/tests/integration/testutils"
 	"github.com/stretchr/testify/require"
@@ -23,8 +28,7 @@ import (
 
 // TestGRPCHandler проверяет метод Upload через gRPC стрим
 func TestGRPCHandler(t *testing.T) {
-	store := testutils.SetupMinIO(t)
-	defer testutils.CleanupMinIO(t, store)
+	store := testutils.SetupStorage(t)
 
 	svc := service.NewFileService(store, store.Bucket)
 	h := handler.NewFileHandler(svc)
@@ -46,8 +50,7 @@ func TestGRPCHandler(t *testing.T) {
 
 // TestDownloadLink проверяет получение URL для скачивания файла
 func TestDownloadLink(t *testing.T) {
-	store := testutils.SetupMinIO(t)
-	defer testutils.CleanupMinIO(t, store)
+	store := testutils.SetupStorage(t)
 
 	svc := service.NewFileService(store, store.Bucket)
 	h := handler.NewFileHandler(svc)
@@ -56,29 +59,40 @@ func TestDownloadLink(t *testing.T) {
 	filename := "download_test.txt"
 	content := []byte("download test content")
 
-	fileID, size, err := svc.Upload(ctx, filename, content)
+	rec, err := svc.Upload(ctx, filename, bytes.NewReader(content), models.Checksums{})
 	require.NoError(t, err)
+	fileID := rec.FileID
 	require.NotEmpty(t, fileID)
-	require.Equal(t, uint64(len(content)), size)
+	require.Equal(t, int64(len(content)), rec.Size)
 
 	req := &pb.DownloadLinkRequest{FileId: fileID}
 	resp, err := h.GetDownloadLink(ctx, req)
 	require.NoError(t, err)
 	require.Contains(t, resp.GetUrl(), fileID, "URL для скачивания должен содержать fileID")
+
+	// срок ограничен максимумом сервера, имя файла и тип ответа передаются в ссылке
+	req = &pb.DownloadLinkRequest{FileId: fileID, ExpirySeconds: 30 * 24 * 3600, ContentType: "application/octet-stream"}
+	resp, err = h.GetDownloadLink(ctx, req)
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now().Add(7*24*time.Hour), resp.GetExpiresAt().AsTime(), time.Minute)
+
+	link, err := url.Parse(resp.GetUrl())
+	require.NoError(t, err)
+	require.Equal(t, `attachment; filename=`+filename, link.Query().Get("response-content-disposition"))
+	require.Equal(t, "application/octet-stream", link.Query().Get("response-content-type"))
 }
 
 // TestListFiles проверяет получение списка загруженных файлов
 func TestListFiles(t *testing.T) {
-	store := testutils.SetupMinIO(t)
-	defer testutils.CleanupMinIO(t, store)
+	store := testutils.SetupStorage(t)
 
 	svc := service.NewFileService(store, store.Bucket)
 	h := handler.NewFileHandler(svc)
 
 	ctx := context.Background()
-	_, _, err := svc.Upload(ctx, "file1.txt", []byte("content1"))
+	_, err := svc.Upload(ctx, "file1.txt", strings.NewReader("content1"), models.Checksums{})
 	require.NoError(t, err)
-	_, _, err = svc.Upload(ctx, "file2.txt", []byte("content2"))
+	_, err = svc.Upload(ctx, "file2.txt", strings.NewReader("content2"), models.Checksums{})
 	require.NoError(t, err)
 
 	listReq := &pb.ListRequest{}
@@ -89,8 +103,7 @@ func TestListFiles(t *testing.T) {
 
 // TestDownloadZip проверяет формирование zip-архива из загруженных файлов
 func TestDownloadZip(t *testing.T) {
-	store := testutils.SetupMinIO(t)
-	defer testutils.CleanupMinIO(t, store)
+	store := testutils.SetupStorage(t)
 
 	svc := service.NewFileService(store, store.Bucket)
 	h := handler.NewFileHandler(svc)
@@ -99,12 +112,12 @@ func TestDownloadZip(t *testing.T) {
 	expectedSubstr1 := "file1.txt"
 	expectedSubstr2 := "file2.txt"
 
-	fileID1, _, err := svc.Upload(ctx, expectedSubstr1, []byte("content1"))
+	file1, err := svc.Upload(ctx, expectedSubstr1, strings.NewReader("content1"), models.Checksums{})
 	require.NoError(t, err)
-	fileID2, _, err := svc.Upload(ctx, expectedSubstr2, []byte("content2"))
+	file2, err := svc.Upload(ctx, expectedSubstr2, strings.NewReader("content2"), models.Checksums{})
 	require.NoError(t, err)
 
-	zipReq := &pb.DownloadZipRequest{FileIds: []string{fileID1, fileID2}}
+	zipReq := &pb.DownloadZipRequest{FileIds: []string{file1.FileID, file2.FileID}}
 	stream := &mockDownloadZipStream{ctx: ctx}
 
 	err = h.DownloadZip(zipReq, stream)
@@ -153,8 +166,7 @@ func TestDownloadZip(t *testing.T) {
 
 // TestUploadPhoto проверяет загрузку реальной фотографии
 func TestUploadPhoto(t *testing.T) {
-	store := testutils.SetupMinIO(t)
-	defer testutils.CleanupMinIO(t, store)
+	store := testutils.SetupStorage(t)
 
 	svc := service.NewFileService(store, store.Bucket)
 	ctx := context.Background()
@@ -164,29 +176,30 @@ func TestUploadPhoto(t *testing.T) {
 	require.NoError(t, err)
 	require.NotEmpty(t, photoData)
 
-	fileID, size, err := svc.Upload(ctx, "test_photo.jpg", photoData)
+	rec, err := svc.Upload(ctx, "test_photo.jpg", bytes.NewReader(photoData), models.Checksums{})
 	require.NoError(t, err)
-	require.Greater(t, size, uint64(0))
+	fileID := rec.FileID
+	require.Greater(t, rec.Size, int64(0))
 	require.NotEmpty(t, fileID)
 
-	downloadLink, err := svc.DownloadLink(ctx, fileID)
+	downloadLink, _, err := svc.DownloadLink(ctx, fileID, service.LinkOptions{})
 	require.NoError(t, err)
 	require.Contains(t, downloadLink, fileID, "Ссылка для скачивания должна содержать fileID")
 }
 
 func TestUpdateFile(t *testing.T) {
-    store := testutils.SetupMinIO(t)
-    defer testutils.CleanupMinIO(t, store)
+    store := testutils.SetupStorage(t)
 
     svc := service.NewFileService(store, store.Bucket)
     h := handler.NewFileHandler(svc)
     ctx := context.Background()
 
     originalContent := []byte("original content")
-    fileID, _, err := svc.Upload(ctx, "test_update.txt", originalContent)
+    rec, err := svc.Upload(ctx, "test_update.txt", bytes.NewReader(originalContent), models.Checksums{})
     require.NoError(t, err)
+    fileID := rec.FileID
 
-    files, err := svc.ListFiles(ctx)
+    files, _, err := svc.ListFiles(ctx, service.ListOptions{})
     require.NoError(t, err)
     for _, f := range files {
         if f.FileId == fileID {
@@ -237,8 +250,157 @@ func TestUpdateFile(t *testing.T) {
     })
 }
 
+// TestDownloadFileRange проверяет потоковое скачивание файла целиком и диапазоном
+func TestDownloadFileRange(t *testing.T) {
+	store := testutils.SetupStorage(t)
+
+	svc := service.NewFileService(store, store.Bucket)
+	h := handler.NewFileHandler(svc)
+	ctx := context.Background()
+
+	content := "0123456789abcdef"
+	file, err := svc.Upload(ctx, "digits.txt", strings.NewReader(content), models.Checksums{})
+	require.NoError(t, err)
+
+	t.Run("Whole file", func(t *testing.T) {
+		stream := &mockDownloadFileStream{ctx: ctx}
+		require.NoError(t, h.DownloadFile(&pb.DownloadFileRequest{FileId: file.FileID}, stream))
+
+		require.NotNil(t, stream.header)
+		require.Equal(t, "digits.txt", stream.header.GetFile().GetFilename())
+		require.EqualValues(t, len(content), stream.header.GetFile().GetSize())
+		require.Equal(t, file.Checksums.SHA256, stream.header.GetFile().GetChecksums().GetSha256())
+		require.Equal(t, content, stream.buf.String())
+	})
+
+	t.Run("Range", func(t *testing.T) {
+		stream := &mockDownloadFileStream{ctx: ctx}
+		require.NoError(t, h.DownloadFile(&pb.DownloadFileRequest{FileId: file.FileID, Offset: 10, Length: 100}, stream))
+
+		require.EqualValues(t, 10, stream.header.GetOffset())
+		require.EqualValues(t, 6, stream.header.GetLength())
+		require.Equal(t, "abcdef", stream.buf.String())
+	})
+
+	t.Run("Offset beyond end", func(t *testing.T) {
+		stream := &mockDownloadFileStream{ctx: ctx}
+		err := h.DownloadFile(&pb.DownloadFileRequest{FileId: file.FileID, Offset: 17}, stream)
+		require.Equal(t, codes.OutOfRange, status.Code(err))
+	})
+}
+
+// TestShareLink проверяет публичную ссылку с паролем и лимитом скачиваний
+func TestShareLink(t *testing.T) {
+	store := testutils.SetupStorage(t)
+
+	srv := httptest.NewServer(nil)
+	defer srv.Close()
+	svc := service.NewFileService(store, store.Bucket, service.WithShareLinks(srv.URL), service.WithSharePasswordLimit(3, time.Hour))
+	srv.Config.Handler = handler.NewShareHTTPHandler(svc)
+	h := handler.NewFileHandler(svc)
+	ctx := context.Background()
+
+	file, err := svc.Upload(ctx, "notes.txt", strings.NewReader("shared notes"), models.Checksums{})
+	require.NoError(t, err)
+
+	// без сервера ссылок ссылки не создаются
+	_, err = handler.NewFileHandler(service.NewFileService(store, store.Bucket)).CreateShareLink(ctx, &pb.CreateShareLinkRequest{FileId: file.FileID})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	link, err := h.CreateShareLink(ctx, &pb.CreateShareLinkRequest{FileId: file.FileID, Password: "secret", MaxDownloads: 1})
+	require.NoError(t, err)
+	require.True(t, link.GetHasPassword())
+
+	get := func(password string) (*http.Response, string) {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+service.SharePath+link.GetToken(), nil)
+		require.NoError(t, err)
+		if password != "" {
+			req.SetBasicAuth("", password)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, string(body)
+	}
+
+	resp, _ := get("")
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	require.NotEmpty(t, resp.Header.Get("WWW-Authenticate"))
+	resp, _ = get("wrong")
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp, body := get("secret")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "shared notes", body)
+	require.Equal(t, "attachment; filename=notes.txt", resp.Header.Get("Content-Disposition"))
+
+	// лимит исчерпан; неудачные попытки с неверным паролем не засчитываются
+	resp, _ = get("secret")
+	require.Equal(t, http.StatusGone, resp.StatusCode)
+
+	links, err := h.ListShareLinks(ctx, &pb.ListShareLinksRequest{FileId: file.FileID})
+	require.NoError(t, err)
+	require.Len(t, links.GetLinks(), 1)
+	require.EqualValues(t, 1, links.GetLinks()[0].GetDownloads())
+
+	_, err = h.RevokeShareLink(ctx, &pb.RevokeShareLinkRequest{Token: link.GetToken()})
+	require.NoError(t, err)
+	resp, _ = get("secret")
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	_, err = h.RevokeShareLink(ctx, &pb.RevokeShareLinkRequest{Token: link.GetToken()})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	// ссылки не попадают в список файлов и удаляются вместе с файлом
+	_, err = h.CreateShareLink(ctx, &pb.CreateShareLinkRequest{FileId: file.FileID})
+	require.NoError(t, err)
+	files, _, err := svc.ListFiles(ctx, service.ListOptions{PageSize: 10})
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	_, err = svc.DeleteFile(ctx, file.FileID, true)
+	require.NoError(t, err)
+	links, err = h.ListShareLinks(ctx, &pb.ListShareLinksRequest{})
+	require.NoError(t, err)
+	require.Empty(t, links.GetLinks())
+
+	// после нескольких неверных паролей подряд ссылка не принимает и верный
+	file, err = svc.Upload(ctx, "secret.txt", strings.NewReader("secret"), models.Checksums{})
+	require.NoError(t, err)
+	link, err = h.CreateShareLink(ctx, &pb.CreateShareLinkRequest{FileId: file.FileID, Password: "secret"})
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(link.GetUrl(), srv.URL+service.SharePath))
+	for range 3 {
+		resp, _ = get("wrong")
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	}
+	resp, _ = get("secret")
+	require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+}
+
 // -------------------- Моки --------------------
 
+// mockDownloadFileStream эмулирует gRPC стрим для DownloadFile
+type mockDownloadFileStream struct {
+	ctx    context.Context
+	header *pb.DownloadFileHeader
+	buf    bytes.Buffer
+	pb.FileService_DownloadFileServer
+}
+
+func (m *mockDownloadFileStream) Send(resp *pb.DownloadFileResponse) error {
+	if header := resp.GetHeader(); header != nil {
+		m.header = header
+	}
+	m.buf.Write(resp.GetChunk())
+	return nil
+}
+
+func (m *mockDownloadFileStream) Context() context.Context {
+	return m.ctx
+}
+
 // mockDownloadZipStream эмулирует gRPC стрим для DownloadZip
 type mockDownloadZipStream struct {
 	ctx context.Context
@@ -0,0 +1,28 @@
+package testutils
+
+import (
+	"os"
+	"testing"
+
+	"github.com/1abobik1/upload_file_service/internal/service"
+	"github.com/1abobik1/upload_file_service/internal/storage"
+)
+
+// Storage - хранилище для тестов API вместе с бакетом, в котором оно работает.
+type Storage struct {
+	service.MinIOStorageI
+	Bucket string
+}
+
+// SetupStorage выбирает бэкенд по TEST_STORAGE_BACKEND: "minio" - контейнер из
+// docker-compose.test.yml, иначе хранилище в памяти, и тесты запускаются обычным go test.
+func SetupStorage(t *testing.T) *Storage {
+	t.Helper()
+
+	if os.Getenv("TEST_STORAGE_BACKEND") == "minio" {
+		store := SetupMinIO(t)
+		return &Storage{MinIOStorageI: store, Bucket: store.Bucket}
+	}
+
+	return &Storage{MinIOStorageI: storage.NewMemoryStorage(), Bucket: "test-bucket"}
+}